@@ -0,0 +1,136 @@
+// Package apierror writes the error envelope every API error response uses:
+//
+//	{"error": "Insufficient balance", "code": "INSUFFICIENT_BALANCE", "request_id": "…"}
+//
+// error is a message for people and may change; code is for clients to
+// branch on and does not. details carries the field problems of a request
+// that failed validation, or the limit details of a responsible gaming
+// refusal. Some responses add further fields, such as retry_after on a
+// throttled login.
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Codes of the error envelope. The responsible gaming and reality check
+// codes are defined by the services that raise them.
+const (
+	CodeInvalidRequest    = "INVALID_REQUEST"
+	CodeValidationFailed  = "VALIDATION_FAILED"
+	CodeRouteNotFound     = "ROUTE_NOT_FOUND"
+	CodeInternal          = "INTERNAL_ERROR"
+	CodeInvalidHistory    = "INVALID_HISTORY_QUERY"
+	CodeInvalidRoundRef   = "INVALID_ROUND_REF"
+	CodeInvalidFreeSpins  = "INVALID_FREE_SPIN_GRANT"
+	CodeInvalidTournament = "INVALID_TOURNAMENT"
+	CodeInvalidPromo      = "INVALID_PROMO_CODE"
+	CodeInvalidAutoplay   = "INVALID_AUTOPLAY"
+	CodeInvalidOperator   = "INVALID_OPERATOR"
+
+	// Authentication and access
+	CodeAuthRequired           = "AUTH_REQUIRED"
+	CodeInvalidToken           = "INVALID_TOKEN"
+	CodeSessionExpired         = "SESSION_EXPIRED"
+	CodeInvalidCredentials     = "INVALID_CREDENTIALS"
+	CodeInvalidChallenge       = "INVALID_CHALLENGE"
+	CodeAccountLocked          = "ACCOUNT_LOCKED"
+	CodeTooManyAttempts        = "TOO_MANY_ATTEMPTS"
+	CodeUsernameTaken          = "USERNAME_TAKEN"
+	CodeAdminRequired          = "ADMIN_REQUIRED"
+	CodeTwoFactorRequired      = "TWO_FACTOR_REQUIRED"
+	CodeTwoFactorSetupRequired = "TWO_FACTOR_SETUP_REQUIRED"
+	CodeInvalidTwoFactorCode   = "INVALID_TWO_FACTOR_CODE"
+	CodeTwoFactorEnabled       = "TWO_FACTOR_ALREADY_ENABLED"
+	CodeTwoFactorNotEnabled    = "TWO_FACTOR_NOT_ENABLED"
+	CodeTwoFactorNotEnrolled   = "TWO_FACTOR_NOT_ENROLLED"
+
+	// Operators
+	CodeOperatorNotFound      = "OPERATOR_NOT_FOUND"
+	CodeOperatorInactive      = "OPERATOR_INACTIVE"
+	CodeInvalidAPIKey         = "INVALID_API_KEY"
+	CodeAPIKeyNotFound        = "API_KEY_NOT_FOUND"
+	CodePlatformAdminRequired = "PLATFORM_ADMIN_REQUIRED"
+
+	// Resources that do not exist or are not the player's
+	CodeUserNotFound        = "USER_NOT_FOUND"
+	CodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
+	CodeRoundNotFound       = "ROUND_NOT_FOUND"
+	CodeSessionNotFound     = "SESSION_NOT_FOUND"
+	CodePlaySessionNotFound = "PLAY_SESSION_NOT_FOUND"
+	CodeTournamentNotFound  = "TOURNAMENT_NOT_FOUND"
+	CodeMissionNotFound     = "MISSION_NOT_FOUND"
+	CodePromoNotFound       = "PROMO_CODE_NOT_FOUND"
+	CodeAutoplayNotFound    = "AUTOPLAY_NOT_FOUND"
+
+	// Play and money
+	CodeInsufficientBalance   = "INSUFFICIENT_BALANCE"
+	CodeBetOutOfRange         = "BET_OUT_OF_RANGE"
+	CodeFeatureBuyDisabled    = "FEATURE_BUY_DISABLED"
+	CodeGameUnavailable       = "GAME_UNAVAILABLE"
+	CodeAnteWithFeatureBuy    = "ANTE_WITH_FEATURE_BUY"
+	CodeNoFreeSpins           = "NO_FREE_SPINS"
+	CodeFreeSpinsExpired      = "FREE_SPINS_EXPIRED"
+	CodeTxAlreadyProcessed    = "TX_ALREADY_PROCESSED"
+	CodeWithdrawLimitExceeded = "WITHDRAW_LIMIT_EXCEEDED"
+	CodeSeamlessWallet        = "SEAMLESS_WALLET"
+	CodeWalletUnavailable     = "WALLET_UNAVAILABLE"
+	CodeWalletRejected        = "WALLET_REJECTED"
+	CodeNoRealityCheckPending = "NO_REALITY_CHECK_PENDING"
+
+	// Tournaments, missions, promo codes and autoplay
+	CodeTournamentClosed     = "TOURNAMENT_CLOSED"
+	CodeMissionNotCompleted  = "MISSION_NOT_COMPLETED"
+	CodeRewardAlreadyClaimed = "REWARD_ALREADY_CLAIMED"
+	CodePromoExpired         = "PROMO_EXPIRED"
+	CodePromoExhausted       = "PROMO_EXHAUSTED"
+	CodePromoAlreadyRedeemed = "PROMO_ALREADY_REDEEMED"
+	CodePromoNotEligible     = "PROMO_NOT_ELIGIBLE"
+	CodeAutoplayActive       = "AUTOPLAY_ACTIVE"
+	CodeAutoplayNotRunning   = "AUTOPLAY_NOT_RUNNING"
+)
+
+// RequestIDKey is the context key the request ID is stored under
+const RequestIDKey = "requestID"
+
+// Body builds the envelope for the request. Extra fields are copied in
+// beside error and code.
+func Body(c *gin.Context, code, message string, extra ...gin.H) gin.H {
+	body := gin.H{"error": message, "code": code}
+	for _, fields := range extra {
+		for key, value := range fields {
+			body[key] = value
+		}
+	}
+	if id := c.GetString(RequestIDKey); id != "" {
+		body["request_id"] = id
+	}
+	return body
+}
+
+// Respond answers the request with an error and stops the handler chain
+func Respond(c *gin.Context, status int, code, message string, extra ...gin.H) {
+	c.AbortWithStatusJSON(status, Body(c, code, message, extra...))
+}
+
+// RespondBody answers with an error body a service built, such as
+// ResponsibleGamingError.Body(), adding the request ID
+func RespondBody(c *gin.Context, status int, body map[string]interface{}) {
+	code, _ := body["code"].(string)
+	message, _ := body["error"].(string)
+	Respond(c, status, code, message, body)
+}
+
+// Internal answers with a 500. The message says what failed without
+// passing on the underlying error.
+func Internal(c *gin.Context, message string) {
+	Respond(c, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// Unauthorized answers a request that reached a handler without a signed-in
+// user
+func Unauthorized(c *gin.Context) {
+	Respond(c, http.StatusUnauthorized, CodeAuthRequired, "Unauthorized")
+}
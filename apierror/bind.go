@@ -0,0 +1,158 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is one problem with a request, listed in the details of a
+// VALIDATION_FAILED or BET_OUT_OF_RANGE error. It has the shape of
+// openapi.Problem so both validators report alike.
+type FieldError struct {
+	In      string `json:"in"` // path, query or body
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Name fields by their JSON property in validation errors
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// RespondBind answers a request whose JSON body could not be bound. The
+// binder's own message is not passed on; each invalid field is listed
+// instead.
+func RespondBind(c *gin.Context, err error) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	var fieldErrs validator.ValidationErrors
+	switch {
+	case errors.Is(err, io.EOF):
+		Respond(c, http.StatusBadRequest, CodeValidationFailed, "Request body is required",
+			gin.H{"details": []FieldError{{In: "body", Message: "is required"}}})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		Respond(c, http.StatusBadRequest, CodeInvalidRequest, "Request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		RespondInvalid(c, "Request validation failed",
+			[]FieldError{{In: "body", Field: typeErr.Field, Message: "must be " + jsonType(typeErr.Type)}})
+	case errors.As(err, &timeErr):
+		RespondInvalid(c, "Request validation failed",
+			[]FieldError{{In: "body", Message: "dates must be RFC 3339 date-times"}})
+	case errors.As(err, &fieldErrs):
+		details := make([]FieldError, len(fieldErrs))
+		for i, fe := range fieldErrs {
+			details[i] = FieldError{In: "body", Field: fieldPath(fe), Message: fieldMessage(fe)}
+		}
+		RespondInvalid(c, "Request validation failed", details)
+	default:
+		Respond(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request")
+	}
+}
+
+// RespondInvalid answers a request with invalid fields. A bet that is
+// present but outside its allowed range has a code of its own.
+func RespondInvalid(c *gin.Context, message string, details []FieldError) {
+	code := CodeValidationFailed
+	if betOutOfRange(details) {
+		code, message = CodeBetOutOfRange, "Bet amount is out of range"
+	}
+	Respond(c, http.StatusBadRequest, code, message, gin.H{"details": details})
+}
+
+func betOutOfRange(details []FieldError) bool {
+	for _, detail := range details {
+		if detail.In != "body" || detail.Field != "bet" {
+			return false
+		}
+		if !strings.HasPrefix(detail.Message, "must be at") && !strings.HasPrefix(detail.Message, "must be greater") {
+			return false
+		}
+	}
+	return len(details) > 0
+}
+
+// fieldPath is the field's JSON path without the name of the request struct
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "len":
+		return "must have length " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// InvalidID answers a request whose ID parameter is not a number
+func InvalidID(c *gin.Context, in, name, message string) {
+	Respond(c, http.StatusBadRequest, CodeValidationFailed, message,
+		gin.H{"details": []FieldError{{In: in, Field: name, Message: "must be a positive integer"}}})
+}
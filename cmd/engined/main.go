@@ -0,0 +1,23 @@
+// Command engined serves the gRPC game engine service on its own, without
+// the HTTP API or a database.
+//
+// Usage:
+//
+//	engined [-addr :9090]
+package main
+
+import (
+	"flag"
+	"log"
+	"slot-sim/enginerpc"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	flag.Parse()
+
+	log.Printf("engine service listening on %s", *addr)
+	if err := enginerpc.ListenAndServe(*addr); err != nil {
+		log.Fatalf("engine service: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	osuser "os/user"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
+	"text/tabwriter"
+	"time"
+)
+
+func createUser(args []string) error {
+	fs := flag.NewFlagSet("create-user", flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	password := fs.String("password", "", "password (required)")
+	role := fs.String("role", "user", "role: user or admin")
+	balance := fs.Int("balance", 1000, "initial balance")
+	fs.Parse(args)
+
+	if *username == "" || *password == "" {
+		return errors.New("-username and -password are required")
+	}
+	if *role != "user" && *role != "admin" {
+		return fmt.Errorf("invalid role %q", *role)
+	}
+
+	operator, err := findOperator(*operatorCode)
+	if err != nil {
+		return err
+	}
+
+	user := models.User{
+		OperatorID: operator.ID,
+		Username:   *username,
+		Password:   *password,
+		Balance:    *balance,
+		Role:       *role,
+	}
+	if err := config.DB.Create(&user).Error; err != nil {
+		return err
+	}
+
+	fmt.Printf("Created user %q of operator %s (id %d, role %s, balance %d)\n",
+		user.Username, operator.Code, user.ID, user.Role, user.Balance)
+	return nil
+}
+
+func promote(args []string) error {
+	return setRole("promote", args, "admin")
+}
+
+func demote(args []string) error {
+	return setRole("demote", args, "user")
+}
+
+func setRole(name string, args []string, role string) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	fs.Parse(args)
+
+	user, err := findUser(*operatorCode, *username)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Model(user).Update("role", role).Error; err != nil {
+		return err
+	}
+
+	fmt.Printf("User %q now has role %s\n", user.Username, role)
+	if role == "admin" && !user.TOTPEnabled {
+		fmt.Println("Note: admin access requires two-factor authentication; enrol via /user/2fa/setup")
+	}
+	return nil
+}
+
+func resetPassword(args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	password := fs.String("password", "", "new password (required)")
+	fs.Parse(args)
+
+	if *password == "" {
+		return errors.New("-password is required")
+	}
+	user, err := findUser(*operatorCode, *username)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Model(user).Update("password", *password).Error; err != nil {
+		return err
+	}
+
+	fmt.Printf("Password reset for %q\n", user.Username)
+	return nil
+}
+
+func lockUser(args []string) error {
+	fs := flag.NewFlagSet("lock", flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	duration := fs.Duration("duration", 0, "how long to lock the account (0 locks until unlocked)")
+	fs.Parse(args)
+
+	user, err := findUser(*operatorCode, *username)
+	if err != nil {
+		return err
+	}
+
+	until := time.Now().AddDate(100, 0, 0)
+	if *duration > 0 {
+		until = time.Now().Add(*duration)
+	}
+	if err := services.NewLoginGuard(config.DB).Lock(user, until); err != nil {
+		return err
+	}
+	// A locked account must not keep using tokens it already has
+	if _, err := services.NewSessionService(config.DB).RevokeAll(user.ID); err != nil {
+		return err
+	}
+
+	if *duration > 0 {
+		fmt.Printf("User %q locked until %s\n", user.Username, until.Format(time.RFC3339))
+	} else {
+		fmt.Printf("User %q locked until unlocked\n", user.Username)
+	}
+	return nil
+}
+
+func unlockUser(args []string) error {
+	fs := flag.NewFlagSet("unlock", flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	fs.Parse(args)
+
+	user, err := findUser(*operatorCode, *username)
+	if err != nil {
+		return err
+	}
+
+	if err := services.NewLoginGuard(config.DB).Unlock(user); err != nil {
+		return err
+	}
+
+	fmt.Printf("User %q unlocked\n", user.Username)
+	return nil
+}
+
+func adjustBalance(args []string) error {
+	fs := flag.NewFlagSet("adjust-balance", flag.ExitOnError)
+	username := fs.String("username", "", "username (required)")
+	operatorCode := operatorFlag(fs)
+	amount := fs.Int("amount", 0, "amount to add; negative values debit (required)")
+	reason := fs.String("reason", "", "reason recorded with the adjustment (required)")
+	fs.Parse(args)
+
+	if *amount == 0 {
+		return errors.New("-amount must not be zero")
+	}
+	if *reason == "" {
+		return errors.New("-reason is required")
+	}
+	user, err := findUser(*operatorCode, *username)
+	if err != nil {
+		return err
+	}
+
+	adjustment, err := services.AdjustBalance(config.DB, user.ID, *amount, *reason, actor())
+	if err != nil {
+		return err
+	}
+
+	if adjustment.WalletCredit != "" {
+		fmt.Printf("Credit of %d to %q queued for the operator's wallet as %s (adjustment #%d)\n",
+			adjustment.Amount, user.Username, adjustment.WalletCredit, adjustment.ID)
+		return nil
+	}
+	fmt.Printf("Balance of %q changed %d -> %d (adjustment #%d)\n",
+		user.Username, adjustment.BalanceBefore, adjustment.BalanceAfter, adjustment.ID)
+	return nil
+}
+
+func listUsers(args []string) error {
+	fs := flag.NewFlagSet("list-users", flag.ExitOnError)
+	role := fs.String("role", "", "only list users with this role")
+	operatorCode := fs.String("operator", "", "only list users of the operator with this code")
+	fs.Parse(args)
+
+	query := config.DB.Order("id")
+	if *role != "" {
+		query = query.Where("role = ?", *role)
+	}
+	if *operatorCode != "" {
+		operator, err := findOperator(*operatorCode)
+		if err != nil {
+			return err
+		}
+		query = query.Where("operator_id = ?", operator.ID)
+	}
+
+	var operators []models.Operator
+	if err := config.DB.Find(&operators).Error; err != nil {
+		return err
+	}
+	codes := make(map[uint]string, len(operators))
+	for _, o := range operators {
+		codes[o.ID] = o.Code
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOPERATOR\tUSERNAME\tROLE\tBALANCE\t2FA\tLOCKED\tCREATED")
+	for _, u := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%t\t%t\t%s\n",
+			u.ID, codes[u.OperatorID], u.Username, u.Role, u.Balance, u.TOTPEnabled, u.IsLocked(now), u.CreatedAt.Format("2006-01-02"))
+	}
+	return w.Flush()
+}
+
+// operatorFlag adds the -operator flag; usernames are only unique per operator
+func operatorFlag(fs *flag.FlagSet) *string {
+	return fs.String("operator", models.DefaultOperatorCode, "code of the operator the user belongs to")
+}
+
+func findOperator(code string) (*models.Operator, error) {
+	var operator models.Operator
+	if err := config.DB.Where("code = ?", code).First(&operator).Error; err != nil {
+		return nil, fmt.Errorf("operator %q not found", code)
+	}
+	return &operator, nil
+}
+
+func findUser(operatorCode, username string) (*models.User, error) {
+	if username == "" {
+		return nil, errors.New("-username is required")
+	}
+	operator, err := findOperator(operatorCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := config.DB.Where("operator_id = ? AND username = ?", operator.ID, username).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user %q of operator %s not found", username, operatorCode)
+	}
+	return &user, nil
+}
+
+// actor identifies who ran the command in the adjustment audit trail
+func actor() string {
+	if u, err := osuser.Current(); err == nil {
+		return "slotctl:" + u.Username
+	}
+	return "slotctl"
+}
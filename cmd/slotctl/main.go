@@ -0,0 +1,82 @@
+// Command slotctl manages users of the slot-sim database from the command line.
+//
+// Usage:
+//
+//	slotctl [-db path] <command> [flags]
+//
+// Run "slotctl help" for the list of commands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slot-sim/config"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"create-user", "create a new user", createUser},
+	{"promote", "give a user the admin role", promote},
+	{"demote", "give a user the regular user role", demote},
+	{"reset-password", "set a new password for a user", resetPassword},
+	{"lock", "lock a user account", lockUser},
+	{"unlock", "unlock a user account and clear failed logins", unlockUser},
+	{"adjust-balance", "credit or debit a user's balance with a reason", adjustBalance},
+	{"list-users", "list users", listUsers},
+}
+
+func main() {
+	dbPath := flag.String("db", config.DatabasePath(), "path to the SQLite database")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 || flag.Arg(0) == "help" {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+			break
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "slotctl: unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	db, err := config.OpenDB(*dbPath)
+	if err != nil {
+		fatalf("failed to open database: %v", err)
+	}
+	config.DB = db
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		fatalf("%s: %v", cmd.name, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: slotctl [-db path] <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Run "slotctl <command> -h" for the flags of a command.`)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "slotctl: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,27 @@
+// Command walletstub serves a stand-in seamless wallet for trying operators
+// in seamless wallet mode locally. Point an operator's wallet_url at it and
+// give it the same wallet_secret.
+//
+// Usage:
+//
+//	walletstub [-addr :9100] [-secret s] [-balance 1000]
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"slot-sim/walletstub"
+)
+
+func main() {
+	addr := flag.String("addr", ":9100", "address to listen on")
+	secret := flag.String("secret", "walletstub", "secret the calls are signed with")
+	balance := flag.Float64("balance", 1000, "balance of players the stub has not seen yet")
+	flag.Parse()
+
+	log.Printf("stub wallet listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, walletstub.New(*secret, *balance)); err != nil {
+		log.Fatalf("stub wallet: %v", err)
+	}
+}
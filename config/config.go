@@ -1,7 +1,9 @@
 package config
 
 import (
+	"os"
 	"slot-sim/models"
+	"strconv"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -9,12 +11,77 @@ import (
 
 var DB *gorm.DB
 
+// DatabasePath returns the SQLite file to use (DB_PATH, default test.db)
+func DatabasePath() string {
+	if path := os.Getenv("DB_PATH"); path != "" {
+		return path
+	}
+	return "test.db"
+}
+
+// ValidateRequests reports whether requests that do not conform to the
+// OpenAPI specification are rejected (OPENAPI_VALIDATE_REQUESTS, default off)
+func ValidateRequests() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("OPENAPI_VALIDATE_REQUESTS"))
+	return enabled
+}
+
+// GRPCAddr is the address the gRPC engine service listens on alongside the
+// HTTP API (GRPC_ADDR, e.g. ":9090"). It is not started when unset.
+func GRPCAddr() string {
+	return os.Getenv("GRPC_ADDR")
+}
+
+// OpenDB opens the database at path and migrates all models
+func OpenDB(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Gamelog{},
+		&models.FortuneRound{},
+		&models.MythicSession{},
+		&models.Transaction{},
+		&models.LoginAttempt{},
+		&models.LoginThrottle{},
+		&models.BalanceAdjustment{},
+		&models.UserSession{},
+		&models.GamingLimit{},
+		&models.PlayerRestriction{},
+		&models.PlaySession{},
+		&models.AutoplayRun{},
+		&models.Jackpot{},
+		&models.JackpotWin{},
+		&models.Tournament{},
+		&models.TournamentEntry{},
+		&models.Mission{},
+		&models.MissionProgress{},
+		&models.FreeSpinPack{},
+		&models.LoyaltyAccount{},
+		&models.LoyaltyPointEntry{},
+		&models.CashbackPayout{},
+		&models.PromoCode{},
+		&models.PromoRedemption{},
+		&models.Operator{},
+		&models.OperatorAPIKey{},
+		&models.WalletTransaction{},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.MigrateOperators(db, LoadFeatureBuyConfig().Enabled); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
 func ConnectDB() {
 
 	var err error
-	DB, err = gorm.Open(sqlite.Open("test.db"), &gorm.Config{})
+	DB, err = OpenDB(DatabasePath())
 	if err != nil {
 		panic("failed to connect database: " + err.Error())
 	}
-	DB.AutoMigrate(&models.User{}, &models.Gamelog{}, &models.MythicSession{}, &models.Transaction{})
 }
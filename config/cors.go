@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSConfig struct {
+	// AllowedOrigins holds exact origins ("https://play.example.com") or
+	// wildcard subdomain patterns ("https://*.example.com")
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Per-environment defaults, overridable through CORS_* environment variables
+var corsDefaults = map[string]CORSConfig{
+	"development": {
+		AllowedOrigins:   []string{"http://localhost:3000", "http://127.0.0.1:3000", "http://localhost:5173"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "Origin", "Cache-Control", "X-Requested-With", "X-Request-ID", "X-Operator", "X-API-Key"},
+		ExposedHeaders:   []string{"X-Request-ID", "Deprecation", "Link"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	},
+	"production": {
+		AllowedOrigins:   []string{},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "X-Request-ID", "X-Operator", "X-API-Key"},
+		ExposedHeaders:   []string{"X-Request-ID", "Deprecation", "Link"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	},
+}
+
+// Environment returns the APP_ENV value, defaulting to development
+func Environment() string {
+	if env := os.Getenv("APP_ENV"); env != "" {
+		return env
+	}
+	return "development"
+}
+
+// LoadCORSConfig builds the CORS policy for the current environment
+func LoadCORSConfig() CORSConfig {
+	cfg, ok := corsDefaults[Environment()]
+	if !ok {
+		cfg = corsDefaults["production"]
+	}
+
+	if v, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS"); ok {
+		cfg.AllowedOrigins = splitList(v)
+	}
+	if v, ok := os.LookupEnv("CORS_ALLOWED_METHODS"); ok {
+		cfg.AllowedMethods = splitList(strings.ToUpper(v))
+	}
+	if v, ok := os.LookupEnv("CORS_ALLOWED_HEADERS"); ok {
+		cfg.AllowedHeaders = splitList(v)
+	}
+	if v, ok := os.LookupEnv("CORS_EXPOSED_HEADERS"); ok {
+		cfg.ExposedHeaders = splitList(v)
+	}
+	if v, err := strconv.ParseBool(os.Getenv("CORS_ALLOW_CREDENTIALS")); err == nil {
+		cfg.AllowCredentials = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("CORS_MAX_AGE")); err == nil {
+		cfg.MaxAge = time.Duration(v) * time.Second
+	}
+
+	return cfg
+}
+
+func splitList(v string) []string {
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
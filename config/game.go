@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RealityCheckInterval is how often players are shown a reality check during
+// play (REALITY_CHECK_MINUTES, default 60)
+func RealityCheckInterval() time.Duration {
+	return envMinutes("REALITY_CHECK_MINUTES", 60)
+}
+
+// PlaySessionIdleTimeout is how long a player can be inactive before the next
+// spin starts a new play session (PLAY_SESSION_IDLE_MINUTES, default 30)
+func PlaySessionIdleTimeout() time.Duration {
+	return envMinutes("PLAY_SESSION_IDLE_MINUTES", 30)
+}
+
+// FeatureBuyConfig controls the Mythic Lightning bonus buy. Several
+// jurisdictions prohibit bonus buys, so each operator offers it only when
+// enabled on its record; these are the deployment's defaults.
+type FeatureBuyConfig struct {
+	Enabled        bool    // whether the default operator offers it when first created, off in production
+	CostMultiplier float64 // price of the feature as a multiple of the bet, where the operator sets none
+}
+
+// LoadFeatureBuyConfig reads FEATURE_BUY_ENABLED and FEATURE_BUY_COST
+// (default 100x the bet)
+func LoadFeatureBuyConfig() FeatureBuyConfig {
+	cfg := FeatureBuyConfig{
+		Enabled:        Environment() != "production",
+		CostMultiplier: 100,
+	}
+
+	if v, err := strconv.ParseBool(os.Getenv("FEATURE_BUY_ENABLED")); err == nil {
+		cfg.Enabled = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("FEATURE_BUY_COST"), 64); err == nil && v > 0 {
+		cfg.CostMultiplier = v
+	}
+
+	return cfg
+}
+
+// JackpotContributionRate is the share of every bet that funds the
+// progressive jackpots (JACKPOT_CONTRIBUTION_PERCENT, default 1%)
+func JackpotContributionRate() float64 {
+	percent, err := strconv.ParseFloat(os.Getenv("JACKPOT_CONTRIBUTION_PERCENT"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		percent = 1
+	}
+	return percent / 100
+}
+
+// LoyaltyPointRate is the loyalty points earned per unit wagered on a game
+// (LOYALTY_POINTS_<GAME>, e.g. LOYALTY_POINTS_FORTUNE_GEMS, default 0.1)
+func LoyaltyPointRate(game string) float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("LOYALTY_POINTS_"+strings.ToUpper(game)), 64)
+	if err != nil || rate < 0 {
+		return 0.1
+	}
+	return rate
+}
+
+// BigWinMultiplier is the win, as a multiple of the bet, from which a win
+// is announced to every connected player (BIG_WIN_MULTIPLIER, default 50)
+func BigWinMultiplier() float64 {
+	multiplier, err := strconv.ParseFloat(os.Getenv("BIG_WIN_MULTIPLIER"), 64)
+	if err != nil || multiplier <= 0 {
+		return 50
+	}
+	return multiplier
+}
+
+func envMinutes(key string, fallback int) time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || minutes <= 0 {
+		minutes = fallback
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/handlers"
 	"slot-sim/models"
+	"slot-sim/services"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -17,19 +23,52 @@ func NewAdminController(db *gorm.DB) *AdminController {
 	return &AdminController{db: db}
 }
 
+// operatorScope membatasi query tabel ber-operator_id ke operator admin.
+// Admin operator default menjalankan platform dan melihat semua operator.
+func operatorScope(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		operator := c.MustGet("operator").(*models.Operator)
+		if operator.Code == models.DefaultOperatorCode {
+			return db
+		}
+		return db.Where("operator_id = ?", operator.ID)
+	}
+}
+
+// playerScope sama dengan operatorScope untuk tabel yang hanya punya user_id
+func playerScope(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		operator := c.MustGet("operator").(*models.Operator)
+		if operator.Code == models.DefaultOperatorCode {
+			return db
+		}
+		return db.Where("user_id IN (SELECT id FROM users WHERE operator_id = ?)", operator.ID)
+	}
+}
+
+// findUser mencari user yang boleh dilihat admin, false kalau tidak ada
+func (ac *AdminController) findUser(c *gin.Context, userID uint64) (*models.User, bool) {
+	var user models.User
+	if err := ac.db.Scopes(operatorScope(c)).First(&user, userID).Error; err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
+		return nil, false
+	}
+	return &user, true
+}
+
 // GetAllTransactions - Admin melihat semua transaksi
 func (ac *AdminController) GetAllTransactions(c *gin.Context) {
 	status := c.Query("status")
 	
 	var transactions []models.Transaction
-	query := ac.db.Order("created_at desc")
+	query := ac.db.Scopes(operatorScope(c)).Order("created_at desc")
 	
 	if status != "" {
 		query = query.Where("status = ?", status)
 	}
 	
 	if err := query.Find(&transactions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		apierror.Internal(c, "Failed to fetch transactions")
 		return
 	}
 
@@ -44,24 +83,24 @@ type ApproveTransactionRequest struct {
 func (ac *AdminController) ProcessTransaction(c *gin.Context) {
 	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+		apierror.InvalidID(c, "path", "id", "Invalid transaction ID")
 		return
 	}
 
 	var req ApproveTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBind(c, err)
 		return
 	}
 
 	var transaction models.Transaction
-	if err := ac.db.First(&transaction, transactionID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+	if err := ac.db.Scopes(operatorScope(c)).First(&transaction, transactionID).Error; err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeTransactionNotFound, "Transaction not found")
 		return
 	}
 
 	if transaction.Status != models.StatusPending {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Transaction already processed"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTxAlreadyProcessed, "Transaction already processed")
 		return
 	}
 
@@ -75,14 +114,14 @@ func (ac *AdminController) ProcessTransaction(c *gin.Context) {
 			var user models.User
 			if err := tx.First(&user, transaction.UserID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
 				return
 			}
 			
 			user.Balance += int(transaction.Amount)
 			if err := tx.Save(&user).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+				apierror.Internal(c, "Failed to update balance")
 				return
 			}
 		}
@@ -96,14 +135,14 @@ func (ac *AdminController) ProcessTransaction(c *gin.Context) {
 			var user models.User
 			if err := tx.First(&user, transaction.UserID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
 				return
 			}
 			
 			user.Balance += int(transaction.Amount)
 			if err := tx.Save(&user).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund balance"})
+				apierror.Internal(c, "Failed to refund balance")
 				return
 			}
 		}
@@ -111,12 +150,23 @@ func (ac *AdminController) ProcessTransaction(c *gin.Context) {
 
 	if err := tx.Save(&transaction).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction"})
+		apierror.Internal(c, "Failed to update transaction")
 		return
 	}
 
 	tx.Commit()
 
+	// Beri tahu user lewat live event channel
+	services.Events.Publish(services.Event{
+		Type:   services.EventTransactionProcessed,
+		UserID: transaction.UserID,
+		Data:   transaction,
+	})
+	if transaction.Type == models.TypeDeposit && transaction.Status == models.StatusApproved ||
+		transaction.Type == models.TypeWithdraw && transaction.Status == models.StatusRejected {
+		services.PublishBalance(ac.db, transaction.UserID, string(transaction.Type)+"_"+string(transaction.Status))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":     "Transaction processed successfully",
 		"transaction": transaction,
@@ -133,12 +183,500 @@ func (ac *AdminController) GetDashboardStats(c *gin.Context) {
 		TotalWithdraws   float64 `json:"total_withdraws"`
 	}
 
-	ac.db.Model(&models.User{}).Count(&stats.TotalUsers)
-	ac.db.Model(&models.Transaction{}).Where("type = ? AND status = ?", models.TypeDeposit, models.StatusPending).Count(&stats.PendingDeposits)
-	ac.db.Model(&models.Transaction{}).Where("type = ? AND status = ?", models.TypeWithdraw, models.StatusPending).Count(&stats.PendingWithdraws)
+	scope := operatorScope(c)
+	ac.db.Model(&models.User{}).Scopes(scope).Count(&stats.TotalUsers)
+	ac.db.Model(&models.Transaction{}).Scopes(scope).Where("type = ? AND status = ?", models.TypeDeposit, models.StatusPending).Count(&stats.PendingDeposits)
+	ac.db.Model(&models.Transaction{}).Scopes(scope).Where("type = ? AND status = ?", models.TypeWithdraw, models.StatusPending).Count(&stats.PendingWithdraws)
 	
-	ac.db.Model(&models.Transaction{}).Where("type = ? AND status = ?", models.TypeDeposit, models.StatusApproved).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalDeposits)
-	ac.db.Model(&models.Transaction{}).Where("type = ? AND status = ?", models.TypeWithdraw, models.StatusApproved).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalWithdraws)
+	ac.db.Model(&models.Transaction{}).Scopes(scope).Where("type = ? AND status = ?", models.TypeDeposit, models.StatusApproved).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalDeposits)
+	ac.db.Model(&models.Transaction{}).Scopes(scope).Where("type = ? AND status = ?", models.TypeWithdraw, models.StatusApproved).Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalWithdraws)
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetLoginAttempts - Admin melihat log percobaan login
+func (ac *AdminController) GetLoginAttempts(c *gin.Context) {
+	query := ac.db.Scopes(playerScope(c)).Order("created_at desc").Limit(200)
+
+	if username := c.Query("username"); username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if success := c.Query("success"); success != "" {
+		query = query.Where("success = ?", success == "true")
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Find(&attempts).Error; err != nil {
+		apierror.Internal(c, "Failed to fetch login attempts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attempts": attempts})
+}
+
+// UnlockUser - Admin membuka kunci akun yang terkunci karena gagal login
+func (ac *AdminController) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid user ID")
+		return
+	}
+
+	user, ok := ac.findUser(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := services.NewLoginGuard(ac.db).Unlock(user); err != nil {
+		apierror.Internal(c, "Failed to unlock user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// TerminateUserSessions - Admin mengeluarkan user dari semua device (akun dibobol)
+func (ac *AdminController) TerminateUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid user ID")
+		return
+	}
+
+	user, ok := ac.findUser(c, userID)
+	if !ok {
+		return
+	}
+
+	count, err := services.NewSessionService(ac.db).RevokeAll(user.ID)
+	if err != nil {
+		apierror.Internal(c, "Failed to terminate sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions terminated", "terminated": count})
+}
+
+// GetPlaySessions - Admin melihat ringkasan sesi bermain (filter user_id opsional)
+func (ac *AdminController) GetPlaySessions(c *gin.Context) {
+	var userID uint64
+	if v := c.Query("user_id"); v != "" {
+		var err error
+		if userID, err = strconv.ParseUint(v, 10, 32); err != nil {
+			apierror.InvalidID(c, "query", "user_id", "Invalid user ID")
+			return
+		}
+	}
+
+	sessions, err := services.NewPlaySessionService(ac.db).List(uint(userID), 200, playerScope(c))
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch play sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// GetMythicRTP - Admin melihat RTP Mythic Lightning, spin biasa dan feature buy dipisah
+func (ac *AdminController) GetMythicRTP(c *gin.Context) {
+	report, err := services.NewGameService(ac.db).MythicRTPReport(operatorScope(c))
+	if err != nil {
+		apierror.Internal(c, "Failed to calculate RTP")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rtp": report})
+}
+
+type CreateTournamentInput struct {
+	Name        string    `json:"name" binding:"required"`
+	Game        string    `json:"game" binding:"omitempty,oneof=fortune_gems mythic_lightning"` // kosong = kedua game
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	MinBet      float64   `json:"min_bet" binding:"gte=0"`
+	ScoringRule string    `json:"scoring_rule" binding:"required,oneof=highest_multiplier total_wagered"`
+	AutoEnroll  bool      `json:"auto_enroll"`
+	Prizes      []int     `json:"prizes"` // hadiah per peringkat, peringkat 1 dulu
+}
+
+// CreateTournament - Admin membuat turnamen baru
+func (ac *AdminController) CreateTournament(c *gin.Context) {
+	var input CreateTournamentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	tournament, err := services.NewTournamentService(ac.db).Create(services.TournamentInput{
+		Name:        input.Name,
+		Game:        input.Game,
+		StartsAt:    input.StartsAt,
+		EndsAt:      input.EndsAt,
+		MinBet:      input.MinBet,
+		ScoringRule: input.ScoringRule,
+		AutoEnroll:  input.AutoEnroll,
+		Prizes:      input.Prizes,
+	})
+	if err != nil {
+		handlers.RespondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Tournament created", "tournament": tournament})
+}
+
+// GetTournaments - Admin melihat semua turnamen
+func (ac *AdminController) GetTournaments(c *gin.Context) {
+	tournaments, err := services.NewTournamentService(ac.db).List(true)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch tournaments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tournaments": tournaments})
+}
+
+// CloseTournament - Admin menutup turnamen sekarang dan membagikan hadiah
+func (ac *AdminController) CloseTournament(c *gin.Context) {
+	tournamentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid tournament ID")
+		return
+	}
+
+	tournament, err := services.NewTournamentService(ac.db).Close(uint(tournamentID))
+	if err != nil {
+		handlers.RespondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tournament closed and prizes paid", "tournament": tournament})
+}
+
+type CreatePromoCodeInput struct {
+	Code              string     `json:"code" binding:"required"`
+	Description       string     `json:"description"`
+	RewardType        string     `json:"reward_type" binding:"required,oneof=balance free_spins"`
+	BonusAmount       int        `json:"bonus_amount"`
+	FreeSpins         int        `json:"free_spins"`
+	FreeSpinBet       float64    `json:"free_spin_bet"`
+	FreeSpinDays      int        `json:"free_spin_days"`  // 0 = spin tidak kedaluwarsa
+	MaxRedemptions    int        `json:"max_redemptions"` // 0 = tanpa batas
+	ExpiresAt         *time.Time `json:"expires_at"`
+	MinTier           string     `json:"min_tier"`             // tier VIP minimum
+	MaxAccountAgeDays int        `json:"max_account_age_days"` // khusus pemain baru
+	RequiresDeposit   bool       `json:"requires_deposit"`
+}
+
+// CreatePromoCode - Admin membuat kode promo baru
+func (ac *AdminController) CreatePromoCode(c *gin.Context) {
+	var input CreatePromoCodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	adminID := c.MustGet("userID").(uint)
+
+	promo, err := services.NewPromoService(ac.db).Create(services.PromoCodeInput{
+		Code:              input.Code,
+		Description:       input.Description,
+		RewardType:        input.RewardType,
+		BonusAmount:       input.BonusAmount,
+		FreeSpins:         input.FreeSpins,
+		FreeSpinBet:       input.FreeSpinBet,
+		FreeSpinDays:      input.FreeSpinDays,
+		MaxRedemptions:    input.MaxRedemptions,
+		ExpiresAt:         input.ExpiresAt,
+		MinTier:           input.MinTier,
+		MaxAccountAgeDays: input.MaxAccountAgeDays,
+		RequiresDeposit:   input.RequiresDeposit,
+	}, fmt.Sprintf("admin:%d", adminID))
+	if err != nil {
+		handlers.RespondPromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Promo code created", "promo_code": promo})
+}
+
+// GetPromoCodes - Admin melihat semua kode promo beserta jumlah pemakaiannya
+func (ac *AdminController) GetPromoCodes(c *gin.Context) {
+	promos, err := services.NewPromoService(ac.db).List()
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch promo codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promo_codes": promos})
+}
+
+// DeactivatePromoCode - Admin menonaktifkan kode promo
+func (ac *AdminController) DeactivatePromoCode(c *gin.Context) {
+	promoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid promo code ID")
+		return
+	}
+
+	if err := services.NewPromoService(ac.db).SetActive(uint(promoID), false); err != nil {
+		handlers.RespondPromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deactivated"})
+}
+
+type GrantFreeSpinsInput struct {
+	Spins         int     `json:"spins" binding:"required,gt=0"`
+	Bet           float64 `json:"bet" binding:"required,gt=0"`
+	ExpiresInDays int     `json:"expires_in_days" binding:"gte=0"` // 0 = tidak kedaluwarsa
+	Reason        string  `json:"reason" binding:"required"`
+}
+
+// GrantFreeSpins - Admin memberikan paket free spin Mythic Lightning langsung ke user
+func (ac *AdminController) GrantFreeSpins(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid user ID")
+		return
+	}
+
+	var input GrantFreeSpinsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	user, ok := ac.findUser(c, userID)
+	if !ok {
+		return
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	adminID := c.MustGet("userID").(uint)
+	source := fmt.Sprintf("admin:%d:%s", adminID, input.Reason)
+	pack, err := services.GrantFreeSpins(ac.db, user.ID, input.Spins, input.Bet, source, expiresAt)
+	if errors.Is(err, services.ErrInvalidFreeSpins) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidFreeSpins, err.Error())
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, "Failed to grant free spins")
+		return
+	}
+	services.PublishFreeSpins(pack)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Free spins granted", "free_spin_pack": pack})
+}
+
+// GetFreeSpinPacks - Admin melihat paket free spin, kemenangannya dicatat terpisah di total_win
+func (ac *AdminController) GetFreeSpinPacks(c *gin.Context) {
+	query := ac.db.Model(&models.FreeSpinPack{}).Scopes(playerScope(c))
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var packs []models.FreeSpinPack
+	if err := query.Order("created_at DESC").Limit(200).Find(&packs).Error; err != nil {
+		apierror.Internal(c, "Failed to fetch free spin packs")
+		return
+	}
+
+	var totalWin float64
+	for _, pack := range packs {
+		totalWin += pack.TotalWin
+	}
+
+	c.JSON(http.StatusOK, gin.H{"free_spin_packs": packs, "total_win": totalWin})
+}
+
+type OperatorInput struct {
+	Code           string            `json:"code"` // hanya saat membuat, tidak bisa diubah
+	Name           string            `json:"name" binding:"required"`
+	Host           string            `json:"host"`
+	Currency       string            `json:"currency"` // kosong = USD
+	Games          []string          `json:"games"`    // kosong = semua game
+	FortuneMinBet  int               `json:"fortune_min_bet" binding:"gte=0"`
+	FortuneMaxBet  int               `json:"fortune_max_bet" binding:"gte=0"`
+	MythicMinBet   float64           `json:"mythic_min_bet" binding:"gte=0"`
+	MythicMaxBet   float64           `json:"mythic_max_bet" binding:"gte=0"`
+	Branding       map[string]string `json:"branding"`
+	WalletURL      string            `json:"wallet_url"`                       // kosong = saldo disimpan di sini
+	WalletSecret   string            `json:"wallet_secret"`                    // kosong = secret lama dipakai
+	FeatureBuy     bool              `json:"feature_buy"`                      // bonus buy Mythic Lightning
+	FeatureBuyCost float64           `json:"feature_buy_cost" binding:"gte=0"` // 0 = FEATURE_BUY_COST
+	Active         *bool             `json:"active"`                           // kosong = aktif
+}
+
+func (input OperatorInput) service() services.OperatorInput {
+	active := input.Active == nil || *input.Active
+	return services.OperatorInput{
+		Code:           input.Code,
+		Name:           input.Name,
+		Host:           input.Host,
+		Currency:       input.Currency,
+		Games:          input.Games,
+		FortuneMinBet:  input.FortuneMinBet,
+		FortuneMaxBet:  input.FortuneMaxBet,
+		MythicMinBet:   input.MythicMinBet,
+		MythicMaxBet:   input.MythicMaxBet,
+		Branding:       input.Branding,
+		WalletURL:      input.WalletURL,
+		WalletSecret:   input.WalletSecret,
+		FeatureBuy:     input.FeatureBuy,
+		FeatureBuyCost: input.FeatureBuyCost,
+		Active:         active,
+	}
+}
+
+// GetOperators - Admin platform melihat semua operator
+func (ac *AdminController) GetOperators(c *gin.Context) {
+	operators, err := services.NewOperatorService(ac.db).List()
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch operators")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"operators": operators})
+}
+
+// CreateOperator - Admin platform menambahkan operator (brand) baru
+func (ac *AdminController) CreateOperator(c *gin.Context) {
+	var input OperatorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	operator, err := services.NewOperatorService(ac.db).Create(input.service())
+	if err != nil {
+		handlers.RespondOperatorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Operator created", "operator": operator})
+}
+
+// UpdateOperator - Admin platform mengubah konfigurasi operator
+func (ac *AdminController) UpdateOperator(c *gin.Context) {
+	operatorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid operator ID")
+		return
+	}
+
+	var input OperatorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	operator, err := services.NewOperatorService(ac.db).Update(uint(operatorID), input.service())
+	if err != nil {
+		handlers.RespondOperatorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Operator updated", "operator": operator})
+}
+
+// GetOperatorAPIKeys - Admin platform melihat API key operator (tanpa key-nya)
+func (ac *AdminController) GetOperatorAPIKeys(c *gin.Context) {
+	operatorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid operator ID")
+		return
+	}
+
+	keys, err := services.NewOperatorService(ac.db).APIKeys(uint(operatorID))
+	if err != nil {
+		handlers.RespondOperatorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+type CreateAPIKeyInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateOperatorAPIKey - Admin platform membuat API key, key hanya ditampilkan sekali
+func (ac *AdminController) CreateOperatorAPIKey(c *gin.Context) {
+	operatorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid operator ID")
+		return
+	}
+
+	var input CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	key, secret, err := services.NewOperatorService(ac.db).CreateAPIKey(uint(operatorID), input.Name)
+	if err != nil {
+		handlers.RespondOperatorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "API key created, store it now as it is not shown again", "api_key": key, "key": secret})
+}
+
+// RevokeOperatorAPIKey - Admin platform mencabut API key operator
+func (ac *AdminController) RevokeOperatorAPIKey(c *gin.Context) {
+	operatorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid operator ID")
+		return
+	}
+	keyID, err := strconv.ParseUint(c.Param("keyId"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "keyId", "Invalid API key ID")
+		return
+	}
+
+	key, err := services.NewOperatorService(ac.db).RevokeAPIKey(uint(operatorID), uint(keyID))
+	if err != nil {
+		handlers.RespondOperatorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked", "api_key": key})
+}
+
+// GetWalletTransactions - Admin melihat panggilan ke seamless wallet operator,
+// filter type, status, round_id dan user_id opsional (status failed dikirim ulang
+// pada next_attempt_at, status rejected perlu rekonsiliasi manual)
+func (ac *AdminController) GetWalletTransactions(c *gin.Context) {
+	query := ac.db.Model(&models.WalletTransaction{}).Scopes(operatorScope(c))
+	if kind := c.Query("type"); kind != "" {
+		query = query.Where("type = ?", kind)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if roundID := c.Query("round_id"); roundID != "" {
+		query = query.Where("round_id = ?", roundID)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var transactions []models.WalletTransaction
+	if err := query.Order("id DESC").Limit(200).Find(&transactions).Error; err != nil {
+		apierror.Internal(c, "Failed to fetch wallet transactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wallet_transactions": transactions})
+}
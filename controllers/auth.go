@@ -2,9 +2,13 @@ package controllers
 
 import (
 	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/config"
+	"slot-sim/handlers"
 	"slot-sim/models"
+	"slot-sim/services"
 	"slot-sim/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,26 +19,34 @@ type RegisterInput struct {
 }
 
 type LoginInput struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username    string `json:"username" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+	DeviceLabel string `json:"device_label"` // optional, derived from the user agent when empty
+}
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP code or recovery code
+	DeviceLabel    string `json:"device_label"`
 }
 
 func Register(c *gin.Context) {
 	var input RegisterInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBind(c, err)
 		return
 	}
 
 	user := models.User{
-		Username: input.Username,
-		Password: input.Password, // In a real app, hash this!
-		Balance:  1000,           // Initial balance
-		Role:     "user",
+		OperatorID: c.GetUint("operatorID"),
+		Username:   input.Username,
+		Password:   input.Password, // In a real app, hash this!
+		Balance:    1000,           // Initial balance
+		Role:       "user",
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username already exists or invalid data"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeUsernameTaken, "Username already exists or invalid data")
 		return
 	}
 
@@ -44,26 +56,143 @@ func Register(c *gin.Context) {
 func Login(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	guard := services.NewLoginGuard(config.DB)
+	now := time.Now()
+	attempt := models.LoginAttempt{
+		Username:  input.Username,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	subject := services.LoginSubject{OperatorID: c.GetUint("operatorID"), Username: input.Username, IP: attempt.IP}
+	var user models.User
+	result := config.DB.Where("operator_id = ? AND username = ?", subject.OperatorID, input.Username).Limit(1).Find(&user)
+	if result.Error != nil {
+		apierror.Internal(c, "Failed to check credentials")
+		return
+	}
+	if result.RowsAffected == 1 {
+		subject.User = &user
+		attempt.UserID = &user.ID
+	}
+
+	// Locked accounts and clients retrying too fast are rejected before the
+	// password is checked. Unknown usernames get the same answers as
+	// accounts, so they cannot be told apart.
+	if wait, reason := guard.RetryAfter(subject, now); reason != "" {
+		attempt.Reason = reason
+		guard.Record(&attempt)
+		handlers.RespondLoginBlocked(c, wait, reason)
+		return
+	}
+
+	if subject.User == nil || user.Password != input.Password {
+		guard.RegisterFailure(subject, now)
+		attempt.Reason = models.LoginReasonInvalidPassword
+		if subject.User == nil {
+			attempt.Reason = models.LoginReasonUnknownUser
+		}
+		guard.Record(&attempt)
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidCredentials, "Invalid credentials")
+		return
+	}
+
+	// With 2FA enabled the password only earns a challenge for the second step
+	if user.TOTPEnabled {
+		challenge, err := utils.GenerateTwoFactorChallenge(user.ID)
+		if err != nil {
+			apierror.Internal(c, "Could not generate token")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
+	}
+
+	token, session, err := services.NewSessionService(config.DB).Start(user.ID, false, services.DeviceInfo{
+		Label:     input.DeviceLabel,
+		IP:        attempt.IP,
+		UserAgent: attempt.UserAgent,
+	})
+	if err != nil {
+		apierror.Internal(c, "Could not generate token")
+		return
+	}
+
+	guard.RegisterSuccess(subject)
+	attempt.Success = true
+	attempt.Reason = models.LoginReasonSuccess
+	guard.Record(&attempt)
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "session_id": session.ID})
+}
+
+// LoginTwoFactor completes a login for users with 2FA enabled
+func LoginTwoFactor(c *gin.Context) {
+	var input TwoFactorLoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	claims, err := utils.ParseToken(input.ChallengeToken, utils.PurposeTwoFactor)
+	if err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidChallenge, "Invalid or expired challenge")
 		return
 	}
 
 	var user models.User
-	if err := config.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	err = config.DB.Where("operator_id = ?", c.GetUint("operatorID")).First(&user, claims.UserID).Error
+	if err != nil {
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidChallenge, "Invalid or expired challenge")
+		return
+	}
+
+	guard := services.NewLoginGuard(config.DB)
+	now := time.Now()
+	attempt := models.LoginAttempt{
+		UserID:    &user.ID,
+		Username:  user.Username,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	subject := services.LoginSubject{OperatorID: user.OperatorID, Username: user.Username, IP: attempt.IP, User: &user}
+
+	if wait, reason := guard.RetryAfter(subject, now); reason != "" {
+		attempt.Reason = reason
+		guard.Record(&attempt)
+		handlers.RespondLoginBlocked(c, wait, reason)
 		return
 	}
 
-	if user.Password != input.Password {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	if err := services.NewTwoFactorService(config.DB).Verify(&user, input.Code); err != nil {
+		guard.RegisterFailure(subject, now)
+		attempt.Reason = models.LoginReasonInvalidTOTP
+		guard.Record(&attempt)
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidTwoFactorCode, "Invalid two-factor code")
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID)
+	token, session, err := services.NewSessionService(config.DB).Start(user.ID, true, services.DeviceInfo{
+		Label:     input.DeviceLabel,
+		IP:        attempt.IP,
+		UserAgent: attempt.UserAgent,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate token"})
+		apierror.Internal(c, "Could not generate token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	guard.RegisterSuccess(subject)
+	attempt.Success = true
+	attempt.Reason = models.LoginReasonSuccess
+	guard.Record(&attempt)
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "session_id": session.ID})
 }
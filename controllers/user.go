@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/config"
+	"slot-sim/handlers"
 	"slot-sim/models"
+	"slot-sim/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,7 +15,21 @@ func GetProfile(c *gin.Context) {
 	userId := c.MustGet("userID").(uint)
 	var user models.User
 	if err := config.DB.First(&user, userId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
+		return
+	}
+
+	operator := c.MustGet("operator").(*models.Operator)
+
+	badges, err := services.NewMissionService(config.DB).Badges(user.ID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch badges")
+		return
+	}
+
+	loyalty, err := services.NewLoyaltyService(config.DB).Status(user.ID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch loyalty status")
 		return
 	}
 
@@ -20,12 +37,41 @@ func GetProfile(c *gin.Context) {
 		"username": user.Username,
 		"balance":  user.Balance,
 		"role":     user.Role,
+		"operator": operator.Code,
+		"currency": operator.Currency,
+		"badges":   badges,
+		"loyalty":  loyalty,
 	})
 }
 
+// GetHistory returns the user's Fortune Gems rounds a page at a time,
+// filtered on game (the log action) and result besides the shared history
+// parameters
 func GetHistory(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
-	var logs []models.Gamelog
-	config.DB.Where("user_id = ?", userID).Find(&logs)
-	c.JSON(http.StatusOK, logs)
+
+	q, err := handlers.ParseHistoryQuery(c, "bet", "win", "balance_change")
+	if err == nil {
+		err = handlers.FilterResult(&q, c, "win")
+	}
+	if err != nil {
+		handlers.RespondHistory[models.Gamelog](c, "history", nil, err)
+		return
+	}
+	handlers.FilterHistory(&q, c, "game", "action")
+
+	page, err := services.PageHistory[models.Gamelog](config.DB.Where("user_id = ?", userID), q)
+	handlers.RespondHistory(c, "history", page, err)
+}
+
+// GetLoginHistory returns the user's recent login attempts so they can spot
+// sign-ins they don't recognise
+func GetLoginHistory(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	attempts, err := services.NewLoginGuard(config.DB).RecentLogins(userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch login history")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"logins": attempts})
 }
@@ -0,0 +1,1346 @@
+// The game engine service: the math of Mythic Lightning and Fortune Gems for
+// other backend services. Nothing is stored and no balance changes; wallets,
+// limits and round history stay with the HTTP API.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: engine.proto
+
+package enginepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Game int32
+
+const (
+	Game_GAME_UNSPECIFIED      Game = 0
+	Game_GAME_MYTHIC_LIGHTNING Game = 1
+	Game_GAME_FORTUNE_GEMS     Game = 2
+)
+
+// Enum value maps for Game.
+var (
+	Game_name = map[int32]string{
+		0: "GAME_UNSPECIFIED",
+		1: "GAME_MYTHIC_LIGHTNING",
+		2: "GAME_FORTUNE_GEMS",
+	}
+	Game_value = map[string]int32{
+		"GAME_UNSPECIFIED":      0,
+		"GAME_MYTHIC_LIGHTNING": 1,
+		"GAME_FORTUNE_GEMS":     2,
+	}
+)
+
+func (x Game) Enum() *Game {
+	p := new(Game)
+	*p = x
+	return p
+}
+
+func (x Game) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Game) Descriptor() protoreflect.EnumDescriptor {
+	return file_engine_proto_enumTypes[0].Descriptor()
+}
+
+func (Game) Type() protoreflect.EnumType {
+	return &file_engine_proto_enumTypes[0]
+}
+
+func (x Game) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Game.Descriptor instead.
+func (Game) EnumDescriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{0}
+}
+
+// Row is one row of a grid, symbols from left to right
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_engine_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Row) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Col           int32                  `protobuf:"varint,2,opt,name=col,proto3" json:"col,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_engine_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Position) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *Position) GetCol() int32 {
+	if x != nil {
+		return x.Col
+	}
+	return 0
+}
+
+type Cluster struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Positions     []*Position            `protobuf:"bytes,2,rep,name=positions,proto3" json:"positions,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Win           float64                `protobuf:"fixed64,4,opt,name=win,proto3" json:"win,omitempty"` // set by EvaluateGrid
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cluster) Reset() {
+	*x = Cluster{}
+	mi := &file_engine_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cluster) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cluster) ProtoMessage() {}
+
+func (x *Cluster) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cluster.ProtoReflect.Descriptor instead.
+func (*Cluster) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Cluster) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Cluster) GetPositions() []*Position {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *Cluster) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Cluster) GetWin() float64 {
+	if x != nil {
+		return x.Win
+	}
+	return 0
+}
+
+type SpinRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Game  Game                   `protobuf:"varint,1,opt,name=game,proto3,enum=slotsim.engine.v1.Game" json:"game,omitempty"`
+	// Mythic Lightning takes any positive bet, Fortune Gems a whole amount
+	// from 10 to 1000
+	Bet        float64 `protobuf:"fixed64,2,opt,name=bet,proto3" json:"bet,omitempty"`
+	AnteBet    bool    `protobuf:"varint,3,opt,name=ante_bet,json=anteBet,proto3" json:"ante_bet,omitempty"`          // Mythic Lightning only
+	FeatureBuy bool    `protobuf:"varint,4,opt,name=feature_buy,json=featureBuy,proto3" json:"feature_buy,omitempty"` // Mythic Lightning only, plays the bought feature
+	// The same seed and request always play the same spin. A secure random
+	// seed is drawn when it is unset.
+	Seed          *int64 `protobuf:"varint,5,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpinRequest) Reset() {
+	*x = SpinRequest{}
+	mi := &file_engine_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpinRequest) ProtoMessage() {}
+
+func (x *SpinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpinRequest.ProtoReflect.Descriptor instead.
+func (*SpinRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SpinRequest) GetGame() Game {
+	if x != nil {
+		return x.Game
+	}
+	return Game_GAME_UNSPECIFIED
+}
+
+func (x *SpinRequest) GetBet() float64 {
+	if x != nil {
+		return x.Bet
+	}
+	return 0
+}
+
+func (x *SpinRequest) GetAnteBet() bool {
+	if x != nil {
+		return x.AnteBet
+	}
+	return false
+}
+
+func (x *SpinRequest) GetFeatureBuy() bool {
+	if x != nil {
+		return x.FeatureBuy
+	}
+	return false
+}
+
+func (x *SpinRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+type SpinResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seed  int64                  `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*SpinResponse_Mythic
+	//	*SpinResponse_Fortune
+	Outcome       isSpinResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpinResponse) Reset() {
+	*x = SpinResponse{}
+	mi := &file_engine_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpinResponse) ProtoMessage() {}
+
+func (x *SpinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpinResponse.ProtoReflect.Descriptor instead.
+func (*SpinResponse) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SpinResponse) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *SpinResponse) GetOutcome() isSpinResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *SpinResponse) GetMythic() *MythicOutcome {
+	if x != nil {
+		if x, ok := x.Outcome.(*SpinResponse_Mythic); ok {
+			return x.Mythic
+		}
+	}
+	return nil
+}
+
+func (x *SpinResponse) GetFortune() *FortuneOutcome {
+	if x != nil {
+		if x, ok := x.Outcome.(*SpinResponse_Fortune); ok {
+			return x.Fortune
+		}
+	}
+	return nil
+}
+
+type isSpinResponse_Outcome interface {
+	isSpinResponse_Outcome()
+}
+
+type SpinResponse_Mythic struct {
+	Mythic *MythicOutcome `protobuf:"bytes,2,opt,name=mythic,proto3,oneof"`
+}
+
+type SpinResponse_Fortune struct {
+	Fortune *FortuneOutcome `protobuf:"bytes,3,opt,name=fortune,proto3,oneof"`
+}
+
+func (*SpinResponse_Mythic) isSpinResponse_Outcome() {}
+
+func (*SpinResponse_Fortune) isSpinResponse_Outcome() {}
+
+type Tumble struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Grid          []*Row                 `protobuf:"bytes,1,rep,name=grid,proto3" json:"grid,omitempty"` // grid after the tumble
+	Clusters      []*Cluster             `protobuf:"bytes,2,rep,name=clusters,proto3" json:"clusters,omitempty"`
+	Win           float64                `protobuf:"fixed64,3,opt,name=win,proto3" json:"win,omitempty"`
+	Multiplier    float64                `protobuf:"fixed64,4,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	Strikes       []float64              `protobuf:"fixed64,5,rep,packed,name=strikes,proto3" json:"strikes,omitempty"`
+	Refilled      []*Position            `protobuf:"bytes,6,rep,name=refilled,proto3" json:"refilled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tumble) Reset() {
+	*x = Tumble{}
+	mi := &file_engine_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tumble) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tumble) ProtoMessage() {}
+
+func (x *Tumble) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tumble.ProtoReflect.Descriptor instead.
+func (*Tumble) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Tumble) GetGrid() []*Row {
+	if x != nil {
+		return x.Grid
+	}
+	return nil
+}
+
+func (x *Tumble) GetClusters() []*Cluster {
+	if x != nil {
+		return x.Clusters
+	}
+	return nil
+}
+
+func (x *Tumble) GetWin() float64 {
+	if x != nil {
+		return x.Win
+	}
+	return 0
+}
+
+func (x *Tumble) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *Tumble) GetStrikes() []float64 {
+	if x != nil {
+		return x.Strikes
+	}
+	return nil
+}
+
+func (x *Tumble) GetRefilled() []*Position {
+	if x != nil {
+		return x.Refilled
+	}
+	return nil
+}
+
+type MythicOutcome struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	InitialGrid      []*Row                 `protobuf:"bytes,1,rep,name=initial_grid,json=initialGrid,proto3" json:"initial_grid,omitempty"`
+	Grid             []*Row                 `protobuf:"bytes,2,rep,name=grid,proto3" json:"grid,omitempty"`
+	Tumbles          []*Tumble              `protobuf:"bytes,3,rep,name=tumbles,proto3" json:"tumbles,omitempty"`
+	BaseWin          float64                `protobuf:"fixed64,4,opt,name=base_win,json=baseWin,proto3" json:"base_win,omitempty"`
+	TotalMultiplier  float64                `protobuf:"fixed64,5,opt,name=total_multiplier,json=totalMultiplier,proto3" json:"total_multiplier,omitempty"`
+	ScatterCount     int32                  `protobuf:"varint,6,opt,name=scatter_count,json=scatterCount,proto3" json:"scatter_count,omitempty"`
+	ScatterWin       float64                `protobuf:"fixed64,7,opt,name=scatter_win,json=scatterWin,proto3" json:"scatter_win,omitempty"`
+	FreeSpinsAwarded int32                  `protobuf:"varint,8,opt,name=free_spins_awarded,json=freeSpinsAwarded,proto3" json:"free_spins_awarded,omitempty"`
+	AnteBet          bool                   `protobuf:"varint,9,opt,name=ante_bet,json=anteBet,proto3" json:"ante_bet,omitempty"`
+	WeightProfile    string                 `protobuf:"bytes,10,opt,name=weight_profile,json=weightProfile,proto3" json:"weight_profile,omitempty"`
+	FeatureBuy       bool                   `protobuf:"varint,11,opt,name=feature_buy,json=featureBuy,proto3" json:"feature_buy,omitempty"`
+	FreeSpins        []*MythicOutcome       `protobuf:"bytes,12,rep,name=free_spins,json=freeSpins,proto3" json:"free_spins,omitempty"` // played straight away on a feature buy
+	FreeSpinsWin     float64                `protobuf:"fixed64,13,opt,name=free_spins_win,json=freeSpinsWin,proto3" json:"free_spins_win,omitempty"`
+	TotalWin         float64                `protobuf:"fixed64,14,opt,name=total_win,json=totalWin,proto3" json:"total_win,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MythicOutcome) Reset() {
+	*x = MythicOutcome{}
+	mi := &file_engine_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MythicOutcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MythicOutcome) ProtoMessage() {}
+
+func (x *MythicOutcome) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MythicOutcome.ProtoReflect.Descriptor instead.
+func (*MythicOutcome) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MythicOutcome) GetInitialGrid() []*Row {
+	if x != nil {
+		return x.InitialGrid
+	}
+	return nil
+}
+
+func (x *MythicOutcome) GetGrid() []*Row {
+	if x != nil {
+		return x.Grid
+	}
+	return nil
+}
+
+func (x *MythicOutcome) GetTumbles() []*Tumble {
+	if x != nil {
+		return x.Tumbles
+	}
+	return nil
+}
+
+func (x *MythicOutcome) GetBaseWin() float64 {
+	if x != nil {
+		return x.BaseWin
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetTotalMultiplier() float64 {
+	if x != nil {
+		return x.TotalMultiplier
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetScatterCount() int32 {
+	if x != nil {
+		return x.ScatterCount
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetScatterWin() float64 {
+	if x != nil {
+		return x.ScatterWin
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetFreeSpinsAwarded() int32 {
+	if x != nil {
+		return x.FreeSpinsAwarded
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetAnteBet() bool {
+	if x != nil {
+		return x.AnteBet
+	}
+	return false
+}
+
+func (x *MythicOutcome) GetWeightProfile() string {
+	if x != nil {
+		return x.WeightProfile
+	}
+	return ""
+}
+
+func (x *MythicOutcome) GetFeatureBuy() bool {
+	if x != nil {
+		return x.FeatureBuy
+	}
+	return false
+}
+
+func (x *MythicOutcome) GetFreeSpins() []*MythicOutcome {
+	if x != nil {
+		return x.FreeSpins
+	}
+	return nil
+}
+
+func (x *MythicOutcome) GetFreeSpinsWin() float64 {
+	if x != nil {
+		return x.FreeSpinsWin
+	}
+	return 0
+}
+
+func (x *MythicOutcome) GetTotalWin() float64 {
+	if x != nil {
+		return x.TotalWin
+	}
+	return 0
+}
+
+type FortuneOutcome struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Grid          []*Row                 `protobuf:"bytes,1,rep,name=grid,proto3" json:"grid,omitempty"`
+	SpecialSymbol string                 `protobuf:"bytes,2,opt,name=special_symbol,json=specialSymbol,proto3" json:"special_symbol,omitempty"`
+	BaseWin       int64                  `protobuf:"varint,3,opt,name=base_win,json=baseWin,proto3" json:"base_win,omitempty"`
+	BonusWin      int64                  `protobuf:"varint,4,opt,name=bonus_win,json=bonusWin,proto3" json:"bonus_win,omitempty"`
+	FinalWin      int64                  `protobuf:"varint,5,opt,name=final_win,json=finalWin,proto3" json:"final_win,omitempty"`
+	Multiplier    int32                  `protobuf:"varint,6,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	IsFortuneSpin bool                   `protobuf:"varint,7,opt,name=is_fortune_spin,json=isFortuneSpin,proto3" json:"is_fortune_spin,omitempty"`
+	WheelPrize    int32                  `protobuf:"varint,8,opt,name=wheel_prize,json=wheelPrize,proto3" json:"wheel_prize,omitempty"`
+	WinningLines  []int32                `protobuf:"varint,9,rep,packed,name=winning_lines,json=winningLines,proto3" json:"winning_lines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FortuneOutcome) Reset() {
+	*x = FortuneOutcome{}
+	mi := &file_engine_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FortuneOutcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FortuneOutcome) ProtoMessage() {}
+
+func (x *FortuneOutcome) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FortuneOutcome.ProtoReflect.Descriptor instead.
+func (*FortuneOutcome) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FortuneOutcome) GetGrid() []*Row {
+	if x != nil {
+		return x.Grid
+	}
+	return nil
+}
+
+func (x *FortuneOutcome) GetSpecialSymbol() string {
+	if x != nil {
+		return x.SpecialSymbol
+	}
+	return ""
+}
+
+func (x *FortuneOutcome) GetBaseWin() int64 {
+	if x != nil {
+		return x.BaseWin
+	}
+	return 0
+}
+
+func (x *FortuneOutcome) GetBonusWin() int64 {
+	if x != nil {
+		return x.BonusWin
+	}
+	return 0
+}
+
+func (x *FortuneOutcome) GetFinalWin() int64 {
+	if x != nil {
+		return x.FinalWin
+	}
+	return 0
+}
+
+func (x *FortuneOutcome) GetMultiplier() int32 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *FortuneOutcome) GetIsFortuneSpin() bool {
+	if x != nil {
+		return x.IsFortuneSpin
+	}
+	return false
+}
+
+func (x *FortuneOutcome) GetWheelPrize() int32 {
+	if x != nil {
+		return x.WheelPrize
+	}
+	return 0
+}
+
+func (x *FortuneOutcome) GetWinningLines() []int32 {
+	if x != nil {
+		return x.WinningLines
+	}
+	return nil
+}
+
+type EvaluateGridRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Game  Game                   `protobuf:"varint,1,opt,name=game,proto3,enum=slotsim.engine.v1.Game" json:"game,omitempty"`
+	// 5 rows of 6 for Mythic Lightning, 3 rows of 3 for Fortune Gems
+	Grid          []*Row  `protobuf:"bytes,2,rep,name=grid,proto3" json:"grid,omitempty"`
+	Bet           float64 `protobuf:"fixed64,3,opt,name=bet,proto3" json:"bet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateGridRequest) Reset() {
+	*x = EvaluateGridRequest{}
+	mi := &file_engine_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateGridRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateGridRequest) ProtoMessage() {}
+
+func (x *EvaluateGridRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateGridRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateGridRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EvaluateGridRequest) GetGame() Game {
+	if x != nil {
+		return x.Game
+	}
+	return Game_GAME_UNSPECIFIED
+}
+
+func (x *EvaluateGridRequest) GetGrid() []*Row {
+	if x != nil {
+		return x.Grid
+	}
+	return nil
+}
+
+func (x *EvaluateGridRequest) GetBet() float64 {
+	if x != nil {
+		return x.Bet
+	}
+	return 0
+}
+
+type LineWin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"` // 0-2 rows top to bottom, 3 and 4 the diagonals
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Win           float64                `protobuf:"fixed64,3,opt,name=win,proto3" json:"win,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineWin) Reset() {
+	*x = LineWin{}
+	mi := &file_engine_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineWin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineWin) ProtoMessage() {}
+
+func (x *LineWin) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineWin.ProtoReflect.Descriptor instead.
+func (*LineWin) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LineWin) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LineWin) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *LineWin) GetWin() float64 {
+	if x != nil {
+		return x.Win
+	}
+	return 0
+}
+
+type EvaluateGridResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Clusters         []*Cluster             `protobuf:"bytes,1,rep,name=clusters,proto3" json:"clusters,omitempty"` // Mythic Lightning
+	Lines            []*LineWin             `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`       // Fortune Gems
+	ScatterCount     int32                  `protobuf:"varint,3,opt,name=scatter_count,json=scatterCount,proto3" json:"scatter_count,omitempty"`
+	FreeSpinsAwarded int32                  `protobuf:"varint,4,opt,name=free_spins_awarded,json=freeSpinsAwarded,proto3" json:"free_spins_awarded,omitempty"`
+	ScatterWin       float64                `protobuf:"fixed64,5,opt,name=scatter_win,json=scatterWin,proto3" json:"scatter_win,omitempty"`
+	// Cluster and scatter wins before tumbles and lightning multipliers, or
+	// line wins before the special reel
+	TotalWin      float64 `protobuf:"fixed64,6,opt,name=total_win,json=totalWin,proto3" json:"total_win,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateGridResponse) Reset() {
+	*x = EvaluateGridResponse{}
+	mi := &file_engine_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateGridResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateGridResponse) ProtoMessage() {}
+
+func (x *EvaluateGridResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateGridResponse.ProtoReflect.Descriptor instead.
+func (*EvaluateGridResponse) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EvaluateGridResponse) GetClusters() []*Cluster {
+	if x != nil {
+		return x.Clusters
+	}
+	return nil
+}
+
+func (x *EvaluateGridResponse) GetLines() []*LineWin {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *EvaluateGridResponse) GetScatterCount() int32 {
+	if x != nil {
+		return x.ScatterCount
+	}
+	return 0
+}
+
+func (x *EvaluateGridResponse) GetFreeSpinsAwarded() int32 {
+	if x != nil {
+		return x.FreeSpinsAwarded
+	}
+	return 0
+}
+
+func (x *EvaluateGridResponse) GetScatterWin() float64 {
+	if x != nil {
+		return x.ScatterWin
+	}
+	return 0
+}
+
+func (x *EvaluateGridResponse) GetTotalWin() float64 {
+	if x != nil {
+		return x.TotalWin
+	}
+	return 0
+}
+
+type SimulateBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Game          Game                   `protobuf:"varint,1,opt,name=game,proto3,enum=slotsim.engine.v1.Game" json:"game,omitempty"`
+	Bet           float64                `protobuf:"fixed64,2,opt,name=bet,proto3" json:"bet,omitempty"`
+	Spins         int32                  `protobuf:"varint,3,opt,name=spins,proto3" json:"spins,omitempty"` // at most 1,000,000
+	AnteBet       bool                   `protobuf:"varint,4,opt,name=ante_bet,json=anteBet,proto3" json:"ante_bet,omitempty"`
+	FeatureBuy    bool                   `protobuf:"varint,5,opt,name=feature_buy,json=featureBuy,proto3" json:"feature_buy,omitempty"` // staked at the configured feature buy cost
+	Seed          *int64                 `protobuf:"varint,6,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimulateBatchRequest) Reset() {
+	*x = SimulateBatchRequest{}
+	mi := &file_engine_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulateBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulateBatchRequest) ProtoMessage() {}
+
+func (x *SimulateBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulateBatchRequest.ProtoReflect.Descriptor instead.
+func (*SimulateBatchRequest) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SimulateBatchRequest) GetGame() Game {
+	if x != nil {
+		return x.Game
+	}
+	return Game_GAME_UNSPECIFIED
+}
+
+func (x *SimulateBatchRequest) GetBet() float64 {
+	if x != nil {
+		return x.Bet
+	}
+	return 0
+}
+
+func (x *SimulateBatchRequest) GetSpins() int32 {
+	if x != nil {
+		return x.Spins
+	}
+	return 0
+}
+
+func (x *SimulateBatchRequest) GetAnteBet() bool {
+	if x != nil {
+		return x.AnteBet
+	}
+	return false
+}
+
+func (x *SimulateBatchRequest) GetFeatureBuy() bool {
+	if x != nil {
+		return x.FeatureBuy
+	}
+	return false
+}
+
+func (x *SimulateBatchRequest) GetSeed() int64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
+	}
+	return 0
+}
+
+type SimulateBatchResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Spins           int32                  `protobuf:"varint,1,opt,name=spins,proto3" json:"spins,omitempty"`
+	TotalStake      float64                `protobuf:"fixed64,2,opt,name=total_stake,json=totalStake,proto3" json:"total_stake,omitempty"`
+	TotalWin        float64                `protobuf:"fixed64,3,opt,name=total_win,json=totalWin,proto3" json:"total_win,omitempty"`
+	Rtp             float64                `protobuf:"fixed64,4,opt,name=rtp,proto3" json:"rtp,omitempty"`
+	HitRate         float64                `protobuf:"fixed64,5,opt,name=hit_rate,json=hitRate,proto3" json:"hit_rate,omitempty"`
+	MaxWin          float64                `protobuf:"fixed64,6,opt,name=max_win,json=maxWin,proto3" json:"max_win,omitempty"`
+	FeatureTriggers int32                  `protobuf:"varint,7,opt,name=feature_triggers,json=featureTriggers,proto3" json:"feature_triggers,omitempty"`
+	Seed            int64                  `protobuf:"varint,8,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SimulateBatchResponse) Reset() {
+	*x = SimulateBatchResponse{}
+	mi := &file_engine_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulateBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulateBatchResponse) ProtoMessage() {}
+
+func (x *SimulateBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulateBatchResponse.ProtoReflect.Descriptor instead.
+func (*SimulateBatchResponse) Descriptor() ([]byte, []int) {
+	return file_engine_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SimulateBatchResponse) GetSpins() int32 {
+	if x != nil {
+		return x.Spins
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetTotalStake() float64 {
+	if x != nil {
+		return x.TotalStake
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetTotalWin() float64 {
+	if x != nil {
+		return x.TotalWin
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetRtp() float64 {
+	if x != nil {
+		return x.Rtp
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetHitRate() float64 {
+	if x != nil {
+		return x.HitRate
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetMaxWin() float64 {
+	if x != nil {
+		return x.MaxWin
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetFeatureTriggers() int32 {
+	if x != nil {
+		return x.FeatureTriggers
+	}
+	return 0
+}
+
+func (x *SimulateBatchResponse) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+var File_engine_proto protoreflect.FileDescriptor
+
+const file_engine_proto_rawDesc = "" +
+	"\n" +
+	"\fengine.proto\x12\x11slotsim.engine.v1\"\x1f\n" +
+	"\x03Row\x12\x18\n" +
+	"\asymbols\x18\x01 \x03(\tR\asymbols\".\n" +
+	"\bPosition\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x10\n" +
+	"\x03col\x18\x02 \x01(\x05R\x03col\"\x82\x01\n" +
+	"\aCluster\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x129\n" +
+	"\tpositions\x18\x02 \x03(\v2\x1b.slotsim.engine.v1.PositionR\tpositions\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12\x10\n" +
+	"\x03win\x18\x04 \x01(\x01R\x03win\"\xaa\x01\n" +
+	"\vSpinRequest\x12+\n" +
+	"\x04game\x18\x01 \x01(\x0e2\x17.slotsim.engine.v1.GameR\x04game\x12\x10\n" +
+	"\x03bet\x18\x02 \x01(\x01R\x03bet\x12\x19\n" +
+	"\bante_bet\x18\x03 \x01(\bR\aanteBet\x12\x1f\n" +
+	"\vfeature_buy\x18\x04 \x01(\bR\n" +
+	"featureBuy\x12\x17\n" +
+	"\x04seed\x18\x05 \x01(\x03H\x00R\x04seed\x88\x01\x01B\a\n" +
+	"\x05_seed\"\xa8\x01\n" +
+	"\fSpinResponse\x12\x12\n" +
+	"\x04seed\x18\x01 \x01(\x03R\x04seed\x12:\n" +
+	"\x06mythic\x18\x02 \x01(\v2 .slotsim.engine.v1.MythicOutcomeH\x00R\x06mythic\x12=\n" +
+	"\afortune\x18\x03 \x01(\v2!.slotsim.engine.v1.FortuneOutcomeH\x00R\afortuneB\t\n" +
+	"\aoutcome\"\xf1\x01\n" +
+	"\x06Tumble\x12*\n" +
+	"\x04grid\x18\x01 \x03(\v2\x16.slotsim.engine.v1.RowR\x04grid\x126\n" +
+	"\bclusters\x18\x02 \x03(\v2\x1a.slotsim.engine.v1.ClusterR\bclusters\x12\x10\n" +
+	"\x03win\x18\x03 \x01(\x01R\x03win\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x04 \x01(\x01R\n" +
+	"multiplier\x12\x18\n" +
+	"\astrikes\x18\x05 \x03(\x01R\astrikes\x127\n" +
+	"\brefilled\x18\x06 \x03(\v2\x1b.slotsim.engine.v1.PositionR\brefilled\"\xcc\x04\n" +
+	"\rMythicOutcome\x129\n" +
+	"\finitial_grid\x18\x01 \x03(\v2\x16.slotsim.engine.v1.RowR\vinitialGrid\x12*\n" +
+	"\x04grid\x18\x02 \x03(\v2\x16.slotsim.engine.v1.RowR\x04grid\x123\n" +
+	"\atumbles\x18\x03 \x03(\v2\x19.slotsim.engine.v1.TumbleR\atumbles\x12\x19\n" +
+	"\bbase_win\x18\x04 \x01(\x01R\abaseWin\x12)\n" +
+	"\x10total_multiplier\x18\x05 \x01(\x01R\x0ftotalMultiplier\x12#\n" +
+	"\rscatter_count\x18\x06 \x01(\x05R\fscatterCount\x12\x1f\n" +
+	"\vscatter_win\x18\a \x01(\x01R\n" +
+	"scatterWin\x12,\n" +
+	"\x12free_spins_awarded\x18\b \x01(\x05R\x10freeSpinsAwarded\x12\x19\n" +
+	"\bante_bet\x18\t \x01(\bR\aanteBet\x12%\n" +
+	"\x0eweight_profile\x18\n" +
+	" \x01(\tR\rweightProfile\x12\x1f\n" +
+	"\vfeature_buy\x18\v \x01(\bR\n" +
+	"featureBuy\x12?\n" +
+	"\n" +
+	"free_spins\x18\f \x03(\v2 .slotsim.engine.v1.MythicOutcomeR\tfreeSpins\x12$\n" +
+	"\x0efree_spins_win\x18\r \x01(\x01R\ffreeSpinsWin\x12\x1b\n" +
+	"\ttotal_win\x18\x0e \x01(\x01R\btotalWin\"\xc6\x02\n" +
+	"\x0eFortuneOutcome\x12*\n" +
+	"\x04grid\x18\x01 \x03(\v2\x16.slotsim.engine.v1.RowR\x04grid\x12%\n" +
+	"\x0especial_symbol\x18\x02 \x01(\tR\rspecialSymbol\x12\x19\n" +
+	"\bbase_win\x18\x03 \x01(\x03R\abaseWin\x12\x1b\n" +
+	"\tbonus_win\x18\x04 \x01(\x03R\bbonusWin\x12\x1b\n" +
+	"\tfinal_win\x18\x05 \x01(\x03R\bfinalWin\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x06 \x01(\x05R\n" +
+	"multiplier\x12&\n" +
+	"\x0fis_fortune_spin\x18\a \x01(\bR\risFortuneSpin\x12\x1f\n" +
+	"\vwheel_prize\x18\b \x01(\x05R\n" +
+	"wheelPrize\x12#\n" +
+	"\rwinning_lines\x18\t \x03(\x05R\fwinningLines\"\x80\x01\n" +
+	"\x13EvaluateGridRequest\x12+\n" +
+	"\x04game\x18\x01 \x01(\x0e2\x17.slotsim.engine.v1.GameR\x04game\x12*\n" +
+	"\x04grid\x18\x02 \x03(\v2\x16.slotsim.engine.v1.RowR\x04grid\x12\x10\n" +
+	"\x03bet\x18\x03 \x01(\x01R\x03bet\"G\n" +
+	"\aLineWin\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x10\n" +
+	"\x03win\x18\x03 \x01(\x01R\x03win\"\x91\x02\n" +
+	"\x14EvaluateGridResponse\x126\n" +
+	"\bclusters\x18\x01 \x03(\v2\x1a.slotsim.engine.v1.ClusterR\bclusters\x120\n" +
+	"\x05lines\x18\x02 \x03(\v2\x1a.slotsim.engine.v1.LineWinR\x05lines\x12#\n" +
+	"\rscatter_count\x18\x03 \x01(\x05R\fscatterCount\x12,\n" +
+	"\x12free_spins_awarded\x18\x04 \x01(\x05R\x10freeSpinsAwarded\x12\x1f\n" +
+	"\vscatter_win\x18\x05 \x01(\x01R\n" +
+	"scatterWin\x12\x1b\n" +
+	"\ttotal_win\x18\x06 \x01(\x01R\btotalWin\"\xc9\x01\n" +
+	"\x14SimulateBatchRequest\x12+\n" +
+	"\x04game\x18\x01 \x01(\x0e2\x17.slotsim.engine.v1.GameR\x04game\x12\x10\n" +
+	"\x03bet\x18\x02 \x01(\x01R\x03bet\x12\x14\n" +
+	"\x05spins\x18\x03 \x01(\x05R\x05spins\x12\x19\n" +
+	"\bante_bet\x18\x04 \x01(\bR\aanteBet\x12\x1f\n" +
+	"\vfeature_buy\x18\x05 \x01(\bR\n" +
+	"featureBuy\x12\x17\n" +
+	"\x04seed\x18\x06 \x01(\x03H\x00R\x04seed\x88\x01\x01B\a\n" +
+	"\x05_seed\"\xf0\x01\n" +
+	"\x15SimulateBatchResponse\x12\x14\n" +
+	"\x05spins\x18\x01 \x01(\x05R\x05spins\x12\x1f\n" +
+	"\vtotal_stake\x18\x02 \x01(\x01R\n" +
+	"totalStake\x12\x1b\n" +
+	"\ttotal_win\x18\x03 \x01(\x01R\btotalWin\x12\x10\n" +
+	"\x03rtp\x18\x04 \x01(\x01R\x03rtp\x12\x19\n" +
+	"\bhit_rate\x18\x05 \x01(\x01R\ahitRate\x12\x17\n" +
+	"\amax_win\x18\x06 \x01(\x01R\x06maxWin\x12)\n" +
+	"\x10feature_triggers\x18\a \x01(\x05R\x0ffeatureTriggers\x12\x12\n" +
+	"\x04seed\x18\b \x01(\x03R\x04seed*N\n" +
+	"\x04Game\x12\x14\n" +
+	"\x10GAME_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15GAME_MYTHIC_LIGHTNING\x10\x01\x12\x15\n" +
+	"\x11GAME_FORTUNE_GEMS\x10\x022\x9d\x02\n" +
+	"\rEngineService\x12G\n" +
+	"\x04Spin\x12\x1e.slotsim.engine.v1.SpinRequest\x1a\x1f.slotsim.engine.v1.SpinResponse\x12_\n" +
+	"\fEvaluateGrid\x12&.slotsim.engine.v1.EvaluateGridRequest\x1a'.slotsim.engine.v1.EvaluateGridResponse\x12b\n" +
+	"\rSimulateBatch\x12'.slotsim.engine.v1.SimulateBatchRequest\x1a(.slotsim.engine.v1.SimulateBatchResponseB\x13Z\x11slot-sim/enginepbb\x06proto3"
+
+var (
+	file_engine_proto_rawDescOnce sync.Once
+	file_engine_proto_rawDescData []byte
+)
+
+func file_engine_proto_rawDescGZIP() []byte {
+	file_engine_proto_rawDescOnce.Do(func() {
+		file_engine_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_engine_proto_rawDesc), len(file_engine_proto_rawDesc)))
+	})
+	return file_engine_proto_rawDescData
+}
+
+var file_engine_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_engine_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_engine_proto_goTypes = []any{
+	(Game)(0),                     // 0: slotsim.engine.v1.Game
+	(*Row)(nil),                   // 1: slotsim.engine.v1.Row
+	(*Position)(nil),              // 2: slotsim.engine.v1.Position
+	(*Cluster)(nil),               // 3: slotsim.engine.v1.Cluster
+	(*SpinRequest)(nil),           // 4: slotsim.engine.v1.SpinRequest
+	(*SpinResponse)(nil),          // 5: slotsim.engine.v1.SpinResponse
+	(*Tumble)(nil),                // 6: slotsim.engine.v1.Tumble
+	(*MythicOutcome)(nil),         // 7: slotsim.engine.v1.MythicOutcome
+	(*FortuneOutcome)(nil),        // 8: slotsim.engine.v1.FortuneOutcome
+	(*EvaluateGridRequest)(nil),   // 9: slotsim.engine.v1.EvaluateGridRequest
+	(*LineWin)(nil),               // 10: slotsim.engine.v1.LineWin
+	(*EvaluateGridResponse)(nil),  // 11: slotsim.engine.v1.EvaluateGridResponse
+	(*SimulateBatchRequest)(nil),  // 12: slotsim.engine.v1.SimulateBatchRequest
+	(*SimulateBatchResponse)(nil), // 13: slotsim.engine.v1.SimulateBatchResponse
+}
+var file_engine_proto_depIdxs = []int32{
+	2,  // 0: slotsim.engine.v1.Cluster.positions:type_name -> slotsim.engine.v1.Position
+	0,  // 1: slotsim.engine.v1.SpinRequest.game:type_name -> slotsim.engine.v1.Game
+	7,  // 2: slotsim.engine.v1.SpinResponse.mythic:type_name -> slotsim.engine.v1.MythicOutcome
+	8,  // 3: slotsim.engine.v1.SpinResponse.fortune:type_name -> slotsim.engine.v1.FortuneOutcome
+	1,  // 4: slotsim.engine.v1.Tumble.grid:type_name -> slotsim.engine.v1.Row
+	3,  // 5: slotsim.engine.v1.Tumble.clusters:type_name -> slotsim.engine.v1.Cluster
+	2,  // 6: slotsim.engine.v1.Tumble.refilled:type_name -> slotsim.engine.v1.Position
+	1,  // 7: slotsim.engine.v1.MythicOutcome.initial_grid:type_name -> slotsim.engine.v1.Row
+	1,  // 8: slotsim.engine.v1.MythicOutcome.grid:type_name -> slotsim.engine.v1.Row
+	6,  // 9: slotsim.engine.v1.MythicOutcome.tumbles:type_name -> slotsim.engine.v1.Tumble
+	7,  // 10: slotsim.engine.v1.MythicOutcome.free_spins:type_name -> slotsim.engine.v1.MythicOutcome
+	1,  // 11: slotsim.engine.v1.FortuneOutcome.grid:type_name -> slotsim.engine.v1.Row
+	0,  // 12: slotsim.engine.v1.EvaluateGridRequest.game:type_name -> slotsim.engine.v1.Game
+	1,  // 13: slotsim.engine.v1.EvaluateGridRequest.grid:type_name -> slotsim.engine.v1.Row
+	3,  // 14: slotsim.engine.v1.EvaluateGridResponse.clusters:type_name -> slotsim.engine.v1.Cluster
+	10, // 15: slotsim.engine.v1.EvaluateGridResponse.lines:type_name -> slotsim.engine.v1.LineWin
+	0,  // 16: slotsim.engine.v1.SimulateBatchRequest.game:type_name -> slotsim.engine.v1.Game
+	4,  // 17: slotsim.engine.v1.EngineService.Spin:input_type -> slotsim.engine.v1.SpinRequest
+	9,  // 18: slotsim.engine.v1.EngineService.EvaluateGrid:input_type -> slotsim.engine.v1.EvaluateGridRequest
+	12, // 19: slotsim.engine.v1.EngineService.SimulateBatch:input_type -> slotsim.engine.v1.SimulateBatchRequest
+	5,  // 20: slotsim.engine.v1.EngineService.Spin:output_type -> slotsim.engine.v1.SpinResponse
+	11, // 21: slotsim.engine.v1.EngineService.EvaluateGrid:output_type -> slotsim.engine.v1.EvaluateGridResponse
+	13, // 22: slotsim.engine.v1.EngineService.SimulateBatch:output_type -> slotsim.engine.v1.SimulateBatchResponse
+	20, // [20:23] is the sub-list for method output_type
+	17, // [17:20] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_engine_proto_init() }
+func file_engine_proto_init() {
+	if File_engine_proto != nil {
+		return
+	}
+	file_engine_proto_msgTypes[3].OneofWrappers = []any{}
+	file_engine_proto_msgTypes[4].OneofWrappers = []any{
+		(*SpinResponse_Mythic)(nil),
+		(*SpinResponse_Fortune)(nil),
+	}
+	file_engine_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_engine_proto_rawDesc), len(file_engine_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_engine_proto_goTypes,
+		DependencyIndexes: file_engine_proto_depIdxs,
+		EnumInfos:         file_engine_proto_enumTypes,
+		MessageInfos:      file_engine_proto_msgTypes,
+	}.Build()
+	File_engine_proto = out.File
+	file_engine_proto_goTypes = nil
+	file_engine_proto_depIdxs = nil
+}
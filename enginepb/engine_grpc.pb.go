@@ -0,0 +1,209 @@
+// The game engine service: the math of Mythic Lightning and Fortune Gems for
+// other backend services. Nothing is stored and no balance changes; wallets,
+// limits and round history stay with the HTTP API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: engine.proto
+
+package enginepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	EngineService_Spin_FullMethodName          = "/slotsim.engine.v1.EngineService/Spin"
+	EngineService_EvaluateGrid_FullMethodName  = "/slotsim.engine.v1.EngineService/EvaluateGrid"
+	EngineService_SimulateBatch_FullMethodName = "/slotsim.engine.v1.EngineService/SimulateBatch"
+)
+
+// EngineServiceClient is the client API for EngineService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EngineServiceClient interface {
+	// Spin plays one spin of a game
+	Spin(ctx context.Context, in *SpinRequest, opts ...grpc.CallOption) (*SpinResponse, error)
+	// EvaluateGrid pays a supplied grid: clusters and scatters for Mythic
+	// Lightning, paylines for Fortune Gems
+	EvaluateGrid(ctx context.Context, in *EvaluateGridRequest, opts ...grpc.CallOption) (*EvaluateGridResponse, error)
+	// SimulateBatch plays a batch of spins and sums them up
+	SimulateBatch(ctx context.Context, in *SimulateBatchRequest, opts ...grpc.CallOption) (*SimulateBatchResponse, error)
+}
+
+type engineServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEngineServiceClient(cc grpc.ClientConnInterface) EngineServiceClient {
+	return &engineServiceClient{cc}
+}
+
+func (c *engineServiceClient) Spin(ctx context.Context, in *SpinRequest, opts ...grpc.CallOption) (*SpinResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpinResponse)
+	err := c.cc.Invoke(ctx, EngineService_Spin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineServiceClient) EvaluateGrid(ctx context.Context, in *EvaluateGridRequest, opts ...grpc.CallOption) (*EvaluateGridResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvaluateGridResponse)
+	err := c.cc.Invoke(ctx, EngineService_EvaluateGrid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineServiceClient) SimulateBatch(ctx context.Context, in *SimulateBatchRequest, opts ...grpc.CallOption) (*SimulateBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimulateBatchResponse)
+	err := c.cc.Invoke(ctx, EngineService_SimulateBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EngineServiceServer is the server API for EngineService service.
+// All implementations must embed UnimplementedEngineServiceServer
+// for forward compatibility.
+type EngineServiceServer interface {
+	// Spin plays one spin of a game
+	Spin(context.Context, *SpinRequest) (*SpinResponse, error)
+	// EvaluateGrid pays a supplied grid: clusters and scatters for Mythic
+	// Lightning, paylines for Fortune Gems
+	EvaluateGrid(context.Context, *EvaluateGridRequest) (*EvaluateGridResponse, error)
+	// SimulateBatch plays a batch of spins and sums them up
+	SimulateBatch(context.Context, *SimulateBatchRequest) (*SimulateBatchResponse, error)
+	mustEmbedUnimplementedEngineServiceServer()
+}
+
+// UnimplementedEngineServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEngineServiceServer struct{}
+
+func (UnimplementedEngineServiceServer) Spin(context.Context, *SpinRequest) (*SpinResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Spin not implemented")
+}
+func (UnimplementedEngineServiceServer) EvaluateGrid(context.Context, *EvaluateGridRequest) (*EvaluateGridResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EvaluateGrid not implemented")
+}
+func (UnimplementedEngineServiceServer) SimulateBatch(context.Context, *SimulateBatchRequest) (*SimulateBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SimulateBatch not implemented")
+}
+func (UnimplementedEngineServiceServer) mustEmbedUnimplementedEngineServiceServer() {}
+func (UnimplementedEngineServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeEngineServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EngineServiceServer will
+// result in compilation errors.
+type UnsafeEngineServiceServer interface {
+	mustEmbedUnimplementedEngineServiceServer()
+}
+
+func RegisterEngineServiceServer(s grpc.ServiceRegistrar, srv EngineServiceServer) {
+	// If the following call panics, it indicates UnimplementedEngineServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EngineService_ServiceDesc, srv)
+}
+
+func _EngineService_Spin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServiceServer).Spin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EngineService_Spin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServiceServer).Spin(ctx, req.(*SpinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EngineService_EvaluateGrid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateGridRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServiceServer).EvaluateGrid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EngineService_EvaluateGrid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServiceServer).EvaluateGrid(ctx, req.(*EvaluateGridRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EngineService_SimulateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServiceServer).SimulateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EngineService_SimulateBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServiceServer).SimulateBatch(ctx, req.(*SimulateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EngineService_ServiceDesc is the grpc.ServiceDesc for EngineService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EngineService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "slotsim.engine.v1.EngineService",
+	HandlerType: (*EngineServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Spin",
+			Handler:    _EngineService_Spin_Handler,
+		},
+		{
+			MethodName: "EvaluateGrid",
+			Handler:    _EngineService_EvaluateGrid_Handler,
+		},
+		{
+			MethodName: "SimulateBatch",
+			Handler:    _EngineService_SimulateBatch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "engine.proto",
+}
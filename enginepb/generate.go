@@ -0,0 +1,7 @@
+// Package enginepb holds the protobuf messages and gRPC stubs of the game
+// engine service, generated from engine.proto. Regenerate them after
+// changing the proto with go generate, which needs protoc, protoc-gen-go and
+// protoc-gen-go-grpc on the PATH.
+package enginepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative engine.proto
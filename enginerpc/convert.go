@@ -0,0 +1,92 @@
+package enginerpc
+
+import (
+	"slot-sim/enginepb"
+	"slot-sim/services"
+	"slot-sim/utils"
+)
+
+func gridRows(rows []*enginepb.Row) [][]string {
+	grid := make([][]string, len(rows))
+	for i, row := range rows {
+		grid[i] = row.GetSymbols()
+	}
+	return grid
+}
+
+func rowMessages(grid [][]string) []*enginepb.Row {
+	rows := make([]*enginepb.Row, len(grid))
+	for i, symbols := range grid {
+		rows[i] = &enginepb.Row{Symbols: symbols}
+	}
+	return rows
+}
+
+func positionMessages(positions []utils.Position) []*enginepb.Position {
+	messages := make([]*enginepb.Position, len(positions))
+	for i, pos := range positions {
+		messages[i] = &enginepb.Position{Row: int32(pos.Row), Col: int32(pos.Col)}
+	}
+	return messages
+}
+
+func clusterMessage(cluster utils.Cluster) *enginepb.Cluster {
+	return &enginepb.Cluster{
+		Symbol:    cluster.Symbol,
+		Positions: positionMessages(cluster.Positions),
+		Size:      int32(cluster.Size),
+	}
+}
+
+func mythicOutcome(outcome services.MythicOutcome) *enginepb.MythicOutcome {
+	message := &enginepb.MythicOutcome{
+		InitialGrid:      rowMessages(outcome.InitialGrid),
+		Grid:             rowMessages(outcome.Grid),
+		BaseWin:          outcome.BaseWin,
+		TotalMultiplier:  outcome.TotalMultiplier,
+		ScatterCount:     int32(outcome.ScatterCount),
+		ScatterWin:       outcome.ScatterWin,
+		FreeSpinsAwarded: int32(outcome.FreeSpinsAwarded),
+		AnteBet:          outcome.AnteBet,
+		WeightProfile:    outcome.WeightProfile,
+		FeatureBuy:       outcome.FeatureBuy,
+		FreeSpinsWin:     outcome.FreeSpinsWin,
+		TotalWin:         outcome.TotalWin,
+	}
+	for _, tumble := range outcome.Tumbles {
+		t := &enginepb.Tumble{
+			Grid:       rowMessages(tumble.Grid),
+			Win:        tumble.Win,
+			Multiplier: tumble.Multiplier,
+			Strikes:    tumble.Strikes,
+			Refilled:   positionMessages(tumble.Refilled),
+		}
+		for _, cluster := range tumble.Clusters {
+			t.Clusters = append(t.Clusters, clusterMessage(cluster))
+		}
+		message.Tumbles = append(message.Tumbles, t)
+	}
+	for _, freeSpin := range outcome.FreeSpins {
+		message.FreeSpins = append(message.FreeSpins, mythicOutcome(freeSpin))
+	}
+	return message
+}
+
+func fortuneOutcome(outcome services.FortuneOutcome) *enginepb.FortuneOutcome {
+	message := &enginepb.FortuneOutcome{
+		SpecialSymbol: outcome.SpecialSymbol,
+		BaseWin:       int64(outcome.BaseWin),
+		BonusWin:      int64(outcome.BonusWin),
+		FinalWin:      int64(outcome.FinalWin),
+		Multiplier:    int32(outcome.Multiplier),
+		IsFortuneSpin: outcome.IsFortuneSpin,
+		WheelPrize:    int32(outcome.WheelPrize),
+	}
+	for _, row := range outcome.Grid {
+		message.Grid = append(message.Grid, &enginepb.Row{Symbols: row[:]})
+	}
+	for _, line := range outcome.WinningLines {
+		message.WinningLines = append(message.WinningLines, int32(line))
+	}
+	return message
+}
@@ -0,0 +1,197 @@
+// Package enginerpc serves the game engines over gRPC for other backend
+// services, as described by enginepb/engine.proto. It plays and evaluates
+// spins only: no player, balance or round is involved.
+package enginerpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slot-sim/config"
+	"slot-sim/enginepb"
+	"slot-sim/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements enginepb.EngineServiceServer
+type Server struct {
+	enginepb.UnimplementedEngineServiceServer
+	mythic     *services.MythicEngine
+	fortune    *services.FortuneEngine
+	featureBuy config.FeatureBuyConfig
+}
+
+func NewServer() *Server {
+	return &Server{
+		mythic:     services.NewMythicEngine(),
+		fortune:    services.NewFortuneEngine(),
+		featureBuy: config.LoadFeatureBuyConfig(),
+	}
+}
+
+// NewGRPCServer returns a gRPC server with the engine service registered
+func NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	enginepb.RegisterEngineServiceServer(s, NewServer())
+	return s
+}
+
+// ListenAndServe serves the engine service on addr until it fails
+func ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return NewGRPCServer().Serve(lis)
+}
+
+// Spin plays one spin. Without a seed a secure random one is drawn, so
+// every spin can be replayed from the seed in its response.
+func (s *Server) Spin(ctx context.Context, req *enginepb.SpinRequest) (*enginepb.SpinResponse, error) {
+	game, err := gameName(req.GetGame())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOptions(game, req.GetBet(), req.GetAnteBet(), req.GetFeatureBuy()); err != nil {
+		return nil, err
+	}
+
+	seed := services.NewRoundSeed()
+	if req.Seed != nil {
+		seed = req.GetSeed()
+	}
+
+	if game == services.GameFortuneGems {
+		outcome := s.fortune.PlaySeed(int(req.GetBet()), seed)
+		return &enginepb.SpinResponse{
+			Seed:    seed,
+			Outcome: &enginepb.SpinResponse_Fortune{Fortune: fortuneOutcome(outcome)},
+		}, nil
+	}
+
+	engine := services.NewSeededMythicEngine(seed)
+	var outcome services.MythicOutcome
+	if req.GetFeatureBuy() {
+		outcome = engine.BuyFeature(req.GetBet())
+	} else {
+		outcome = engine.Spin(req.GetBet(), req.GetAnteBet())
+	}
+	return &enginepb.SpinResponse{
+		Seed:    seed,
+		Outcome: &enginepb.SpinResponse_Mythic{Mythic: mythicOutcome(outcome)},
+	}, nil
+}
+
+// EvaluateGrid pays a supplied grid as it lands
+func (s *Server) EvaluateGrid(ctx context.Context, req *enginepb.EvaluateGridRequest) (*enginepb.EvaluateGridResponse, error) {
+	game, err := gameName(req.GetGame())
+	if err != nil {
+		return nil, err
+	}
+	if err := services.CheckBet(game, req.GetBet()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	rows := gridRows(req.GetGrid())
+
+	if game == services.GameFortuneGems {
+		grid, err := services.FortuneGrid(rows)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		resp := &enginepb.EvaluateGridResponse{}
+		for _, line := range s.fortune.EvaluateLines(grid, int(req.GetBet())) {
+			resp.Lines = append(resp.Lines, &enginepb.LineWin{
+				Line:   int32(line.Line),
+				Symbol: line.Symbol,
+				Win:    float64(line.Win),
+			})
+			resp.TotalWin += float64(line.Win)
+		}
+		return resp, nil
+	}
+
+	evaluation, err := s.mythic.Evaluate(rows, req.GetBet())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &enginepb.EvaluateGridResponse{
+		ScatterCount:     int32(evaluation.ScatterCount),
+		FreeSpinsAwarded: int32(evaluation.FreeSpinsAwarded),
+		ScatterWin:       evaluation.ScatterWin,
+		TotalWin:         evaluation.TotalWin,
+	}
+	for _, cluster := range evaluation.Clusters {
+		c := clusterMessage(cluster.Cluster)
+		c.Win = cluster.Win
+		resp.Clusters = append(resp.Clusters, c)
+	}
+	return resp, nil
+}
+
+// SimulateBatch plays a batch of spins. A cancelled or expired call stops
+// the batch.
+func (s *Server) SimulateBatch(ctx context.Context, req *enginepb.SimulateBatchRequest) (*enginepb.SimulateBatchResponse, error) {
+	game, err := gameName(req.GetGame())
+	if err != nil {
+		return nil, err
+	}
+
+	seed := services.NewRoundSeed()
+	if req.Seed != nil {
+		seed = req.GetSeed()
+	}
+
+	result, err := services.Simulate(ctx, services.SimulationOptions{
+		Game:           game,
+		Bet:            req.GetBet(),
+		Spins:          int(req.GetSpins()),
+		AnteBet:        req.GetAnteBet(),
+		FeatureBuy:     req.GetFeatureBuy(),
+		FeatureBuyCost: s.featureBuy.CostMultiplier,
+		Seed:           seed,
+	})
+	switch {
+	case errors.Is(err, services.ErrInvalidSimulation):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &enginepb.SimulateBatchResponse{
+		Spins:           int32(result.Spins),
+		TotalStake:      result.TotalStake,
+		TotalWin:        result.TotalWin,
+		Rtp:             result.RTP,
+		HitRate:         result.HitRate,
+		MaxWin:          result.MaxWin,
+		FeatureTriggers: int32(result.FeatureTriggers),
+		Seed:            result.Seed,
+	}, nil
+}
+
+func gameName(game enginepb.Game) (string, error) {
+	switch game {
+	case enginepb.Game_GAME_MYTHIC_LIGHTNING:
+		return services.GameMythic, nil
+	case enginepb.Game_GAME_FORTUNE_GEMS:
+		return services.GameFortuneGems, nil
+	}
+	return "", status.Errorf(codes.InvalidArgument, "unknown game %v", game)
+}
+
+// checkOptions checks the bet and the Mythic Lightning options of a spin
+func checkOptions(game string, bet float64, ante, featureBuy bool) error {
+	if err := services.CheckBet(game, bet); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if game == services.GameFortuneGems && (ante || featureBuy) {
+		return status.Error(codes.InvalidArgument, "ante bet and feature buy are Mythic Lightning options")
+	}
+	if ante && featureBuy {
+		return status.Error(codes.InvalidArgument, services.ErrAnteWithFeatureBuy.Error())
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package enginerpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"slot-sim/enginepb"
+	"slot-sim/services"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	mythic  = enginepb.Game_GAME_MYTHIC_LIGHTNING
+	fortune = enginepb.Game_GAME_FORTUNE_GEMS
+)
+
+// newTestClient serves the engine service on an in-process bufconn
+// listener and returns a real client of it
+func newTestClient(t *testing.T) enginepb.EngineServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	server := NewGRPCServer()
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return enginepb.NewEngineServiceClient(conn)
+}
+
+func testContext(t *testing.T, timeout time.Duration) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestSpin(t *testing.T) {
+	client := newTestClient(t)
+	spin := func(req *enginepb.SpinRequest) *enginepb.SpinResponse {
+		t.Helper()
+		resp, err := client.Spin(testContext(t, 5*time.Second), req)
+		if err != nil {
+			t.Fatalf("Spin %s: %v", req.GetGame(), err)
+		}
+		return resp
+	}
+
+	seed := int64(42)
+	first := spin(&enginepb.SpinRequest{Game: mythic, Bet: 2, Seed: &seed})
+	again := spin(&enginepb.SpinRequest{Game: mythic, Bet: 2, Seed: &seed})
+	if !proto.Equal(first, again) {
+		t.Error("a seeded Mythic Lightning spin does not replay")
+	}
+	if first.GetSeed() != seed {
+		t.Errorf("seed %d in the response, want %d", first.GetSeed(), seed)
+	}
+	outcome := first.GetMythic()
+	if want := outcome.GetBaseWin()*outcome.GetTotalMultiplier() + outcome.GetScatterWin(); !approx(outcome.GetTotalWin(), want) {
+		t.Errorf("total win %v, want base win times multiplier plus scatter win (%v)", outcome.GetTotalWin(), want)
+	}
+	if !gridShape(outcome.GetGrid(), services.MythicRows, services.MythicCols) {
+		t.Error("Mythic Lightning grid is not 5 rows of 6")
+	}
+
+	played := spin(&enginepb.SpinRequest{Game: fortune, Bet: 100, Seed: &seed})
+	want := services.NewFortuneEngine().PlaySeed(100, seed)
+	got := played.GetFortune()
+	if !gridShape(got.GetGrid(), services.FortuneSize, services.FortuneSize) {
+		t.Fatal("Fortune Gems grid is not 3 rows of 3")
+	}
+	if got.GetFinalWin() != int64(want.FinalWin) || got.GetSpecialSymbol() != want.SpecialSymbol ||
+		got.GetGrid()[1].GetSymbols()[1] != want.Grid[1][1] {
+		t.Error("a seeded Fortune Gems spin does not match FortuneEngine.PlaySeed")
+	}
+
+	bought := spin(&enginepb.SpinRequest{Game: mythic, Bet: 1, FeatureBuy: true}).GetMythic()
+	if !bought.GetFeatureBuy() || bought.GetFreeSpinsAwarded() < 10 || len(bought.GetFreeSpins()) != int(bought.GetFreeSpinsAwarded()) {
+		t.Errorf("a feature buy awarded %d free spins and played %d", bought.GetFreeSpinsAwarded(), len(bought.GetFreeSpins()))
+	}
+
+	refused := map[string]*enginepb.SpinRequest{
+		"no game":                     {Bet: 1},
+		"fractional Fortune Gems bet": {Game: fortune, Bet: 15.5},
+		"zero Mythic Lightning bet":   {Game: mythic},
+		"ante bet with feature buy":   {Game: mythic, Bet: 1, AnteBet: true, FeatureBuy: true},
+		"ante bet on Fortune Gems":    {Game: fortune, Bet: 10, AnteBet: true},
+	}
+	for name, req := range refused {
+		_, err := client.Spin(testContext(t, 5*time.Second), req)
+		expectInvalidArgument(t, name, err)
+	}
+}
+
+func TestEvaluateGrid(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t, 5*time.Second)
+
+	// A checkerboard of A and K has no clusters. Four ZEUS in the top left
+	// corner form one cluster and four scatters apart from each other
+	// trigger free spins.
+	grid := make([][]string, services.MythicRows)
+	for r := range grid {
+		grid[r] = make([]string, services.MythicCols)
+		for c := range grid[r] {
+			grid[r][c] = []string{services.ACE, services.KING}[(r+c)%2]
+		}
+	}
+	for _, pos := range [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}} {
+		grid[pos[0]][pos[1]] = services.ZEUS
+	}
+	for _, pos := range [][2]int{{2, 5}, {3, 2}, {4, 3}, {4, 5}} {
+		grid[pos[0]][pos[1]] = services.SCATTER
+	}
+
+	resp, err := client.EvaluateGrid(ctx, &enginepb.EvaluateGridRequest{Game: mythic, Grid: rows(grid), Bet: 2})
+	if err != nil {
+		t.Fatalf("EvaluateGrid Mythic Lightning: %v", err)
+	}
+	clusters := resp.GetClusters()
+	if len(clusters) != 1 || clusters[0].GetSymbol() != services.ZEUS || clusters[0].GetSize() != 4 ||
+		len(clusters[0].GetPositions()) != 4 || clusters[0].GetWin() != 200 {
+		t.Errorf("clusters %v, want one ZEUS cluster of 4 paying 200", clusters)
+	}
+	if resp.GetScatterCount() != 4 || resp.GetFreeSpinsAwarded() != 10 || resp.GetScatterWin() != 4 {
+		t.Errorf("%d scatters awarded %d free spins and %v, want 10 free spins and 2x the bet",
+			resp.GetScatterCount(), resp.GetFreeSpinsAwarded(), resp.GetScatterWin())
+	}
+	if resp.GetTotalWin() != 204 {
+		t.Errorf("total win %v, want 204", resp.GetTotalWin())
+	}
+
+	lines := [][]string{
+		{services.Sym7, services.Sym7, services.Sym7},
+		{services.SymA, services.SymK, services.SymQ},
+		{services.SymJ, services.SymA, services.SymK},
+	}
+	resp, err = client.EvaluateGrid(ctx, &enginepb.EvaluateGridRequest{Game: fortune, Grid: rows(lines), Bet: 100})
+	if err != nil {
+		t.Fatalf("EvaluateGrid Fortune Gems: %v", err)
+	}
+	paid := resp.GetLines()
+	if len(paid) != 1 || paid[0].GetLine() != 0 || paid[0].GetSymbol() != services.Sym7 ||
+		paid[0].GetWin() != 500 || resp.GetTotalWin() != 500 {
+		t.Errorf("lines %v, want the top line of 777 paying 500", paid)
+	}
+
+	bad := [][]string{lines[0], lines[1], {services.SymJ, "SEVEN", services.SymK}}
+	refused := map[string]*enginepb.EvaluateGridRequest{
+		"Mythic Lightning grid of 3 rows": {Game: mythic, Grid: rows(lines), Bet: 1},
+		"unknown symbol":                  {Game: fortune, Grid: rows(bad), Bet: 100},
+		"Fortune Gems bet out of range":   {Game: fortune, Grid: rows(lines), Bet: 5000},
+	}
+	for name, req := range refused {
+		_, err := client.EvaluateGrid(ctx, req)
+		expectInvalidArgument(t, name, err)
+	}
+}
+
+func TestSimulateBatch(t *testing.T) {
+	client := newTestClient(t)
+	ctx := testContext(t, time.Minute)
+
+	seed := int64(7)
+	for _, game := range []enginepb.Game{mythic, fortune} {
+		req := &enginepb.SimulateBatchRequest{Game: game, Bet: 10, Spins: 2000, Seed: &seed}
+		first, err := client.SimulateBatch(ctx, req)
+		if err != nil {
+			t.Fatalf("SimulateBatch %s: %v", game, err)
+		}
+		again, err := client.SimulateBatch(ctx, req)
+		if err != nil {
+			t.Fatalf("SimulateBatch %s: %v", game, err)
+		}
+		if !proto.Equal(first, again) {
+			t.Errorf("a seeded %s batch does not replay", game)
+		}
+		if first.GetSpins() != 2000 || first.GetTotalStake() != 20000 {
+			t.Errorf("%s: %d spins staking %v, want the bet on each of 2000", game, first.GetSpins(), first.GetTotalStake())
+		}
+		if !approx(first.GetRtp(), first.GetTotalWin()/first.GetTotalStake()) {
+			t.Errorf("%s: RTP %v is not total win over stake", game, first.GetRtp())
+		}
+		if first.GetHitRate() <= 0 || first.GetHitRate() > 1 || first.GetMaxWin() > first.GetTotalWin() {
+			t.Errorf("%s: implausible hit rate %v or max win %v", game, first.GetHitRate(), first.GetMaxWin())
+		}
+	}
+
+	bought, err := client.SimulateBatch(ctx, &enginepb.SimulateBatchRequest{Game: mythic, Bet: 1, Spins: 20, FeatureBuy: true})
+	if err != nil {
+		t.Fatalf("SimulateBatch feature buy: %v", err)
+	}
+	if bought.GetFeatureTriggers() != 20 {
+		t.Errorf("%d of 20 bought features triggered free spins", bought.GetFeatureTriggers())
+	}
+	if bought.GetTotalStake() <= 20 {
+		t.Errorf("bought features staked %v, want their cost", bought.GetTotalStake())
+	}
+
+	refused := map[string]*enginepb.SimulateBatchRequest{
+		"no spins":       {Game: mythic, Bet: 1},
+		"too many spins": {Game: mythic, Bet: 1, Spins: services.MaxSimulationSpins + 1},
+	}
+	for name, req := range refused {
+		_, err := client.SimulateBatch(ctx, req)
+		expectInvalidArgument(t, name, err)
+	}
+
+	_, err = client.SimulateBatch(testContext(t, 50*time.Millisecond),
+		&enginepb.SimulateBatchRequest{Game: mythic, Bet: 1, Spins: services.MaxSimulationSpins, FeatureBuy: true})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("an expired call got %v, want DeadlineExceeded", err)
+	}
+}
+
+func expectInvalidArgument(t *testing.T, name string, err error) {
+	t.Helper()
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("%s: got %v, want InvalidArgument", name, err)
+	}
+}
+
+func rows(grid [][]string) []*enginepb.Row {
+	messages := make([]*enginepb.Row, len(grid))
+	for i, symbols := range grid {
+		messages[i] = &enginepb.Row{Symbols: symbols}
+	}
+	return messages
+}
+
+func gridShape(grid []*enginepb.Row, rows, cols int) bool {
+	if len(grid) != rows {
+		return false
+	}
+	for _, row := range grid {
+		if len(row.GetSymbols()) != cols {
+			return false
+		}
+	}
+	return true
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
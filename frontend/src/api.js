@@ -1,7 +1,7 @@
 import axios from 'axios';
 
 const api = axios.create({
-    baseURL: 'http://localhost:8080',
+    baseURL: 'http://localhost:8080/api/v1',
 });
 
 api.interceptors.request.use((config) => {
@@ -15,8 +15,8 @@ api.interceptors.request.use((config) => {
 export default api;
 
 // Auth
-export const register = (data) => api.post('/register', data);
-export const login = (data) => api.post('/login', data);
+export const register = (data) => api.post('/auth/register', data);
+export const login = (data) => api.post('/auth/login', data);
 export const getProfile = () => api.get('/user/me');
 
 // Fortune Gems
@@ -24,15 +24,15 @@ export const playSlot = (data) => api.post('/user/play-slot', data);
 export const getHistory = () => api.get('/user/history');
 
 // Mythic Lightning
-export const mythicSpin = (data) => api.post('/api/mythic/spin', data);
-export const mythicHistory = () => api.get('/api/mythic/history');
+export const mythicSpin = (data) => api.post('/mythic/spin', data);
+export const mythicHistory = () => api.get('/mythic/history');
 
 // Wallet
-export const requestTopUp = (data) => api.post('/api/wallet/topup', data);
-export const requestWithdraw = (data) => api.post('/api/wallet/withdraw', data);
-export const getWalletHistory = () => api.get('/api/wallet/history');
+export const requestTopUp = (data) => api.post('/wallet/topup', data);
+export const requestWithdraw = (data) => api.post('/wallet/withdraw', data);
+export const getWalletHistory = () => api.get('/wallet/history');
 
 // Admin
-export const getAdminTransactions = (status) => api.get('/api/admin/transactions', { params: { status } });
-export const processTransaction = (id, action) => api.post(`/api/admin/transactions/${id}/process`, { action });
-export const getAdminDashboard = () => api.get('/api/admin/dashboard');
+export const getAdminTransactions = (status) => api.get('/admin/transactions', { params: { status } });
+export const processTransaction = (id, action) => api.post(`/admin/transactions/${id}/process`, { action });
+export const getAdminDashboard = () => api.get('/admin/dashboard');
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/models"
+	"slot-sim/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AutoplayHandler struct {
+	autoplay *services.AutoplayManager
+}
+
+func NewAutoplayHandler(db *gorm.DB) *AutoplayHandler {
+	return &AutoplayHandler{
+		autoplay: services.NewAutoplayManager(db, services.NewGameService(db)),
+	}
+}
+
+type StartAutoplayRequest struct {
+	Game             string  `json:"game" binding:"required,oneof=fortune_gems mythic_lightning"`
+	Bet              float64 `json:"bet" binding:"required,gt=0"`
+	Spins            int     `json:"spins" binding:"required,min=1,max=1000"`
+	StopOnFeature    bool    `json:"stop_on_feature"`
+	StopOnWinAbove   float64 `json:"stop_on_win_above" binding:"gte=0"`
+	StopBalanceBelow float64 `json:"stop_balance_below" binding:"gte=0"`
+	StopNetLossAbove float64 `json:"stop_net_loss_above" binding:"gte=0"`
+}
+
+// Start begins a server-side autoplay run
+func (h *AutoplayHandler) Start(c *gin.Context) {
+	var req StartAutoplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	run, err := h.autoplay.Start(userID, services.AutoplayOptions{
+		Game:             req.Game,
+		Bet:              req.Bet,
+		Spins:            req.Spins,
+		StopOnFeature:    req.StopOnFeature,
+		StopOnWinAbove:   req.StopOnWinAbove,
+		StopBalanceBelow: req.StopBalanceBelow,
+		StopNetLossAbove: req.StopNetLossAbove,
+	})
+	if err != nil {
+		respondAutoplayError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Autoplay started", "run": run})
+}
+
+// Get reports the progress and results of a run; clients poll it
+func (h *AutoplayHandler) Get(c *gin.Context) {
+	run, ok := h.findRun(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"run":     run,
+		"results": h.autoplay.Results(run),
+		"done":    run.Status != models.AutoplayRunning,
+	})
+}
+
+// List returns the player's recent runs
+func (h *AutoplayHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	runs, err := h.autoplay.List(userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch autoplay runs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// Cancel stops a run after the spin in progress
+func (h *AutoplayHandler) Cancel(c *gin.Context) {
+	run, ok := h.findRun(c)
+	if !ok {
+		return
+	}
+
+	if err := h.autoplay.Cancel(run.UserID, run.ID); err != nil {
+		respondAutoplayError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autoplay cancelled"})
+}
+
+func (h *AutoplayHandler) findRun(c *gin.Context) (*models.AutoplayRun, bool) {
+	userID := c.MustGet("userID").(uint)
+
+	runID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid autoplay ID")
+		return nil, false
+	}
+
+	run, err := h.autoplay.Get(userID, uint(runID))
+	if err != nil {
+		respondAutoplayError(c, err)
+		return nil, false
+	}
+	return run, true
+}
+
+func respondAutoplayError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAutoplay):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidAutoplay, err.Error())
+	case errors.Is(err, services.ErrAutoplayActive):
+		apierror.Respond(c, http.StatusConflict, apierror.CodeAutoplayActive, "An autoplay run is already active")
+	case errors.Is(err, services.ErrAutoplayNotRunning):
+		apierror.Respond(c, http.StatusConflict, apierror.CodeAutoplayNotRunning, "Autoplay run is not running")
+	case errors.Is(err, services.ErrAutoplayNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeAutoplayNotFound, "Autoplay run not found")
+	default:
+		apierror.Internal(c, "Failed to process autoplay")
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/config"
+	"slot-sim/middleware"
+	"slot-sim/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventsHeartbeatInterval = 25 * time.Second
+	eventsWriteTimeout      = 10 * time.Second
+)
+
+type EventsHandler struct {
+	hub  *services.EventHub
+	cors config.CORSConfig
+}
+
+func NewEventsHandler(hub *services.EventHub) *EventsHandler {
+	return &EventsHandler{hub: hub, cors: config.LoadCORSConfig()}
+}
+
+// Stream upgrades the request to a WebSocket and pushes the player's events
+// and the global ones until either side closes the connection
+func (h *EventsHandler) Stream(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serve(ws, userID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin refuses browser connections from origins the CORS policy does
+// not allow. Clients that send no Origin are not browsers and are let through.
+func (h *EventsHandler) checkOrigin(_ *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin != "" && !middleware.OriginAllowed(h.cors, origin) {
+		return errors.New("origin not allowed")
+	}
+	return nil
+}
+
+func (h *EventsHandler) serve(ws *websocket.Conn, userID uint) {
+	defer ws.Close()
+
+	sub := h.hub.Subscribe(userID)
+	defer sub.Unsubscribe()
+
+	// Nothing the client sends is used, reading only notices when it leaves
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var msg string
+		for websocket.Message.Receive(ws, &msg) == nil {
+		}
+	}()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				if sub.Lagged() {
+					sendEvent(ws, services.Event{Type: services.EventResync})
+				}
+				return
+			}
+			if err := sendEvent(ws, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := sendEvent(ws, services.Event{Type: services.EventHeartbeat}); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// sendEvent writes one event, giving up on a client that stops reading
+func sendEvent(ws *websocket.Conn, event services.Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	ws.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+	return websocket.JSON.Send(ws, event)
+}
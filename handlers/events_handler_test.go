@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slot-sim/config"
+	"slot-sim/services"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const testOrigin = "https://play.example.com"
+
+type receivedEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// newEventsServer serves Stream on its own hub. The player is taken from
+// the user query parameter instead of a token.
+func newEventsServer(t *testing.T) (*httptest.Server, *services.EventHub) {
+	gin.SetMode(gin.TestMode)
+	hub := services.NewEventHub()
+	h := &EventsHandler{hub: hub, cors: config.CORSConfig{AllowedOrigins: []string{testOrigin}}}
+
+	r := gin.New()
+	r.GET("/events", func(c *gin.Context) {
+		userID, _ := strconv.Atoi(c.Query("user"))
+		c.Set("userID", uint(userID))
+	}, h.Stream)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server, hub
+}
+
+func dialEvents(server *httptest.Server, userID uint, origin string) (*websocket.Conn, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/events?user=" + strconv.Itoa(int(userID))
+	return websocket.Dial(url, "", origin)
+}
+
+func receive(t *testing.T, ws *websocket.Conn) receivedEvent {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event receivedEvent
+	if err := websocket.JSON.Receive(ws, &event); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return event
+}
+
+func waitForSubscribers(t *testing.T, hub *services.EventHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("hub has %d subscribers, want %d", hub.Subscribers(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventsRouting(t *testing.T) {
+	server, hub := newEventsServer(t)
+
+	alice, err := dialEvents(server, 1, testOrigin)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer alice.Close()
+	bob, err := dialEvents(server, 2, testOrigin)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer bob.Close()
+	waitForSubscribers(t, hub, 2)
+
+	hub.Publish(services.Event{Type: services.EventBalanceChanged, UserID: 1, Data: map[string]int{"balance": 500}})
+	hub.Publish(services.Event{Type: services.EventBigWin, Data: map[string]float64{"multiplier": 80}})
+
+	// Alice gets her balance, then the global event; Bob only the global one
+	if event := receive(t, alice); event.Type != services.EventBalanceChanged || string(event.Data) != `{"balance":500}` {
+		t.Errorf("user 1 got %s %s, want their balance_changed", event.Type, event.Data)
+	}
+	if event := receive(t, alice); event.Type != services.EventBigWin {
+		t.Errorf("user 1 got %s, want big_win", event.Type)
+	}
+	if event := receive(t, bob); event.Type != services.EventBigWin {
+		t.Errorf("user 2 got %s, want big_win and not user 1's balance", event.Type)
+	}
+}
+
+func TestEventsOriginRejected(t *testing.T) {
+	server, hub := newEventsServer(t)
+
+	ws, err := dialEvents(server, 1, "https://evil.example.net")
+	if err == nil {
+		ws.Close()
+		t.Fatal("dial from a disallowed origin succeeded")
+	}
+	var dialErr *websocket.DialError
+	if !errors.As(err, &dialErr) || dialErr.Err != websocket.ErrBadStatus {
+		t.Errorf("dial error = %v, want a refused handshake", err)
+	}
+	if n := hub.Subscribers(); n != 0 {
+		t.Errorf("hub has %d subscribers after a refused handshake", n)
+	}
+
+	// The same client from an allowed origin gets through
+	ws, err = dialEvents(server, 1, testOrigin)
+	if err != nil {
+		t.Fatalf("dial from an allowed origin: %v", err)
+	}
+	ws.Close()
+}
+
+func TestEventsResyncWhenLagging(t *testing.T) {
+	server, hub := newEventsServer(t)
+
+	ws, err := dialEvents(server, 1, testOrigin)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+	waitForSubscribers(t, hub, 1)
+
+	// The client does not read, so once the socket buffers are full the
+	// subscriber falls more than EventBufferSize events behind and is dropped
+	payload := strings.Repeat("x", 16<<10)
+	published := 0
+	for hub.Subscribers() > 0 {
+		if published > 100000 {
+			t.Fatalf("subscriber still connected after %d events", published)
+		}
+		hub.Publish(services.Event{Type: services.EventJackpotUpdate, UserID: 1, Data: payload})
+		published++
+	}
+	if published <= services.EventBufferSize {
+		t.Fatalf("subscriber dropped after %d events, within the buffer of %d", published, services.EventBufferSize)
+	}
+
+	// What was sent or buffered still arrives, then the resync and the close
+	received := 0
+	for {
+		event := receive(t, ws)
+		if event.Type == services.EventResync {
+			break
+		}
+		if event.Type != services.EventJackpotUpdate {
+			t.Fatalf("got %s before the resync", event.Type)
+		}
+		received++
+	}
+	if received >= published {
+		t.Errorf("received all %d events, want some dropped", published)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg string
+	if err := websocket.Message.Receive(ws, &msg); err == nil {
+		t.Errorf("connection still open after the resync, got %q", msg)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"slot-sim/apierror"
+	"slot-sim/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ParseHistoryQuery reads the parameters shared by the history endpoints:
+//
+//	cursor  next_cursor of the previous page
+//	limit   rows per page, up to services.MaxHistoryLimit
+//	from    RFC 3339 time or YYYY-MM-DD, inclusive
+//	to      RFC 3339 time (exclusive) or YYYY-MM-DD (inclusive)
+//	sort    one of the sortable columns, prefixed with - for descending;
+//	        newest first by default
+func ParseHistoryQuery(c *gin.Context, sortable ...string) (services.HistoryQuery, error) {
+	q := services.HistoryQuery{Cursor: c.Query("cursor"), Sort: "created_at", Desc: true}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return q, fmt.Errorf("%w: limit must be a positive number", services.ErrInvalidHistoryQuery)
+		}
+		q.Limit = limit
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, _, err := parseHistoryTime(v)
+		if err != nil {
+			return q, err
+		}
+		q.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, dateOnly, err := parseHistoryTime(v)
+		if err != nil {
+			return q, err
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		q.To = &to
+	}
+
+	if v := c.Query("sort"); v != "" {
+		column := strings.TrimPrefix(v, "-")
+		if column != "created_at" && !slices.Contains(sortable, column) {
+			return q, fmt.Errorf("%w: cannot sort by %q", services.ErrInvalidHistoryQuery, column)
+		}
+		q.Sort = column
+		q.Desc = strings.HasPrefix(v, "-")
+	}
+	return q, nil
+}
+
+// FilterHistory adds an equality filter on column when the query parameter
+// is set
+func FilterHistory(q *services.HistoryQuery, c *gin.Context, param, column string) {
+	if v := c.Query(param); v != "" {
+		q.Scopes = append(q.Scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where(column+" = ?", v)
+		})
+	}
+}
+
+// FilterResult adds the result filter: "win" keeps rounds that paid
+// something, "loss" the ones that did not
+func FilterResult(q *services.HistoryQuery, c *gin.Context, winColumn string) error {
+	switch c.Query("result") {
+	case "":
+	case "win":
+		q.Scopes = append(q.Scopes, func(db *gorm.DB) *gorm.DB { return db.Where(winColumn + " > 0") })
+	case "loss":
+		q.Scopes = append(q.Scopes, func(db *gorm.DB) *gorm.DB { return db.Where(winColumn + " = 0") })
+	default:
+		return fmt.Errorf("%w: result must be win or loss", services.ErrInvalidHistoryQuery)
+	}
+	return nil
+}
+
+// RespondHistory writes a history page with its rows under key
+func RespondHistory[T any](c *gin.Context, key string, page *services.HistoryPage[T], err error) {
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidHistoryQuery) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidHistory, err.Error())
+			return
+		}
+		apierror.Internal(c, "Failed to fetch history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		key:           page.Items,
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
+	})
+}
+
+func parseHistoryTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%w: %q is not a date or RFC 3339 time", services.ErrInvalidHistoryQuery, v)
+}
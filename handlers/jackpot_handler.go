@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type JackpotHandler struct {
+	jackpots *services.JackpotService
+}
+
+func NewJackpotHandler(db *gorm.DB) *JackpotHandler {
+	return &JackpotHandler{jackpots: services.NewJackpotService(db)}
+}
+
+// List returns the live value of every jackpot pool
+func (h *JackpotHandler) List(c *gin.Context) {
+	pools, err := h.jackpots.Pools()
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch jackpots")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jackpots": pools})
+}
+
+// JackpotWinnerView is the public view of a win, without the player
+type JackpotWinnerView struct {
+	Tier   string    `json:"tier"`
+	Game   string    `json:"game"`
+	Amount float64   `json:"amount"`
+	WonAt  time.Time `json:"won_at"`
+}
+
+// Winners returns the latest jackpot winners
+func (h *JackpotHandler) Winners(c *gin.Context) {
+	wins, err := h.jackpots.Winners(50)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch jackpot winners")
+		return
+	}
+
+	winners := make([]JackpotWinnerView, 0, len(wins))
+	for _, win := range wins {
+		winners = append(winners, JackpotWinnerView{
+			Tier:   win.Tier,
+			Game:   win.Game,
+			Amount: win.Amount,
+			WonAt:  win.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"winners": winners})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/models"
+	"slot-sim/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MissionHandler struct {
+	missions *services.MissionService
+}
+
+func NewMissionHandler(db *gorm.DB) *MissionHandler {
+	return &MissionHandler{missions: services.NewMissionService(db)}
+}
+
+// List returns the daily and weekly missions and the achievements with the
+// player's progress
+func (h *MissionHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	statuses, err := h.missions.List(userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch missions")
+		return
+	}
+
+	missions := []services.MissionStatus{}
+	achievements := []services.MissionStatus{}
+	for _, status := range statuses {
+		if status.Period == models.MissionPermanent {
+			achievements = append(achievements, status)
+		} else {
+			missions = append(missions, status)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"missions": missions, "achievements": achievements})
+}
+
+// Claim pays out the reward of a completed mission
+func (h *MissionHandler) Claim(c *gin.Context) {
+	missionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid mission ID")
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	status, err := h.missions.Claim(userID, uint(missionID))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMissionNotFound):
+			apierror.Respond(c, http.StatusNotFound, apierror.CodeMissionNotFound, "Mission not found")
+		case errors.Is(err, services.ErrMissionNotCompleted):
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeMissionNotCompleted, "Mission is not completed yet")
+		case errors.Is(err, services.ErrMissionClaimed):
+			apierror.Respond(c, http.StatusConflict, apierror.CodeRewardAlreadyClaimed, "Reward already claimed")
+		default:
+			apierror.Internal(c, "Failed to claim reward")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reward claimed", "mission": status})
+}
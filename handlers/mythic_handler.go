@@ -1,198 +1,276 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/models"
 	"slot-sim/services"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type MythicHandler struct {
-	db     *gorm.DB
-	engine *services.MythicEngine
+	db    *gorm.DB
+	games *services.GameService
 }
 
 func NewMythicHandler(db *gorm.DB) *MythicHandler {
 	return &MythicHandler{
-		db:     db,
-		engine: services.NewMythicEngine(),
+		db:    db,
+		games: services.NewGameService(db),
 	}
 }
 
 type MythicSpinRequest struct {
-	Bet float64 `json:"bet" binding:"required,gt=0"`
+	Bet     float64 `json:"bet" binding:"required,gt=0"`
+	AnteBet bool    `json:"ante_bet"` // stake AnteBetMultiplier x bet for a higher scatter chance
 }
 
 type MythicSpinResponse struct {
-	Grid             [][]string              `json:"grid"`
-	Tumbles          []services.TumbleResult `json:"tumbles"`
-	TotalWin         float64                 `json:"total_win"`
-	BaseWin          float64                 `json:"base_win"`
-	TotalMultiplier  float64                 `json:"total_multiplier"`
-	CurrentBalance   float64                 `json:"current_balance"`
-	ScatterCount     int                     `json:"scatter_count"`
-	FreeSpinsAwarded int                     `json:"free_spins_awarded"`
-	Message          string                  `json:"message"`
+	RoundRef         string                   `json:"round_ref"` // for /api/v1/rounds/:id
+	InitialGrid      [][]string               `json:"initial_grid"`
+	Grid             [][]string               `json:"grid"`
+	Tumbles          []services.TumbleResult  `json:"tumbles"`
+	TotalWin         float64                  `json:"total_win"`
+	BaseWin          float64                  `json:"base_win"`
+	TotalMultiplier  float64                  `json:"total_multiplier"`
+	CurrentBalance   float64                  `json:"current_balance"`
+	ScatterCount     int                      `json:"scatter_count"`
+	FreeSpinsAwarded int                      `json:"free_spins_awarded"`
+	AnteBet          bool                     `json:"ante_bet"`
+	WeightProfile    string                   `json:"weight_profile"`
+	Stake            float64                  `json:"stake"`
+	FeatureBuy       bool                     `json:"feature_buy,omitempty"`
+	FreeSpins        []services.MythicOutcome `json:"free_spins,omitempty"`
+	FreeSpinsWin     float64                  `json:"free_spins_win,omitempty"`
+	Jackpot          *services.JackpotAward   `json:"jackpot,omitempty"`
+	Missions         []string                 `json:"missions_completed,omitempty"`
+	FreeSpinPack     *models.FreeSpinPack     `json:"free_spin_pack,omitempty"`
+	Message          string                   `json:"message"`
+	RealityCheck     *services.RealityCheck   `json:"reality_check,omitempty"`
 }
 
 // Spin handles a regular Mythic Lightning spin
 func (h *MythicHandler) Spin(c *gin.Context) {
 	var req MythicSpinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bet amount"})
+		apierror.RespondBind(c, err)
 		return
 	}
 
 	// Get user from context
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// Get user from database
-	var user models.User
-	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	round, err := h.games.SpinMythic(userID.(uint), req.Bet, req.AnteBet)
+	if err != nil {
+		RespondGameError(c, err)
 		return
 	}
 
-	// Check balance
-	if float64(user.Balance) < req.Bet {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+	c.JSON(http.StatusOK, mythicResponse(round))
+}
+
+// GetFeatureBuy tells the client whether the operator offers the bonus buy
+// and its price
+func (h *MythicHandler) GetFeatureBuy(c *gin.Context) {
+	cfg := h.games.FeatureBuy(c.MustGet("operator").(*models.Operator))
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":         cfg.Enabled,
+		"cost_multiplier": cfg.CostMultiplier,
+	})
+}
+
+// BuyFeature buys the free spins feature for the bet times the feature price
+func (h *MythicHandler) BuyFeature(c *gin.Context) {
+	var req MythicSpinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	if req.AnteBet {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeAnteWithFeatureBuy, "Ante bet cannot be combined with feature buy")
 		return
 	}
+	userID := c.MustGet("userID").(uint)
 
-	// Deduct bet
-	user.Balance -= int(req.Bet)
-	if err := h.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+	round, err := h.games.BuyMythicFeature(userID, req.Bet)
+	if err != nil {
+		RespondGameError(c, err)
 		return
 	}
 
-	// Generate initial grid
-	grid := h.engine.GenerateGrid()
+	c.JSON(http.StatusOK, mythicResponse(round))
+}
 
-	// Process tumbles until no more wins
-	var tumbles []services.TumbleResult
-	totalWin := 0.0
-	totalMultiplier := 1.0
+// GetFreeSpins lists the player's free spin packs with spins left
+func (h *MythicHandler) GetFreeSpins(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
 
-	maxTumbles := 20 // Safety limit
-	for i := 0; i < maxTumbles; i++ {
-		tumbleResult := h.engine.ProcessTumble(grid, req.Bet, false)
+	packs, err := services.FreeSpinPacks(h.db, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch free spins")
+		return
+	}
 
-		if !tumbleResult.HasWins {
-			break
-		}
+	c.JSON(http.StatusOK, gin.H{"packs": packs})
+}
 
-		tumbles = append(tumbles, tumbleResult)
-		totalWin += tumbleResult.Win
-		totalMultiplier += (tumbleResult.Multiplier - 1) // Accumulate bonus multipliers
-		grid = tumbleResult.Grid
-	}
-
-	// Apply total multiplier to total win
-	finalWin := totalWin * totalMultiplier
-
-	// Check for scatters (free spins trigger)
-	scatterCount := h.engine.CountScatters(grid)
-	freeSpinsAwarded := 0
-	scatterWin := 0.0
-
-	if scatterCount >= 4 {
-		switch scatterCount {
-		case 4:
-			freeSpinsAwarded = 10
-			scatterWin = req.Bet * 2
-		case 5:
-			freeSpinsAwarded = 15
-			scatterWin = req.Bet * 5
-		default: // 6+
-			freeSpinsAwarded = 20
-			scatterWin = req.Bet * 10
-		}
-		finalWin += scatterWin
+// PlayFreeSpin plays one spin from a free spin pack
+func (h *MythicHandler) PlayFreeSpin(c *gin.Context) {
+	packID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid free spin pack ID")
+		return
 	}
+	userID := c.MustGet("userID").(uint)
 
-	// Update balance with win
-	user.Balance += int(finalWin)
-	if err := h.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+	round, err := h.games.PlayFreeSpin(userID, uint(packID))
+	if err != nil {
+		RespondGameError(c, err)
 		return
 	}
 
-	// Save session to database
-	gridJSON, _ := json.Marshal(grid)
-	tumblesJSON, _ := json.Marshal(tumbles)
-
-	session := models.MythicSession{
-		UserID:           user.ID,
-		BetAmount:        req.Bet,
-		Grid:             string(gridJSON),
-		TumblesCount:     len(tumbles),
-		Multipliers:      string(tumblesJSON),
-		TotalWin:         finalWin,
-		BaseWin:          totalWin,
-		MultiplierWin:    finalWin - totalWin,
-		FreeSpinsActive:  freeSpinsAwarded > 0,
-		FreeSpinsRemain:  freeSpinsAwarded,
-		GlobalMultiplier: totalMultiplier,
-	}
-
-	if err := h.db.Create(&session).Error; err != nil {
-		// Log error but don't fail the request
-		println("Failed to save session:", err.Error())
-	}
-
-	// Create response message
-	message := ""
-	if finalWin > 0 {
-		if freeSpinsAwarded > 0 {
-			message = "FREE SPINS TRIGGERED!"
-		} else if finalWin >= req.Bet*100 {
-			message = "MEGA WIN!"
-		} else if finalWin >= req.Bet*50 {
-			message = "BIG WIN!"
-		} else {
-			message = "WIN!"
-		}
-	} else {
-		message = "Try again!"
-	}
-
-	c.JSON(http.StatusOK, MythicSpinResponse{
-		Grid:             grid,
-		Tumbles:          tumbles,
-		TotalWin:         finalWin,
-		BaseWin:          totalWin,
-		TotalMultiplier:  totalMultiplier,
-		CurrentBalance:   float64(user.Balance),
-		ScatterCount:     scatterCount,
-		FreeSpinsAwarded: freeSpinsAwarded,
-		Message:          message,
-	})
+	c.JSON(http.StatusOK, mythicResponse(round))
 }
 
-// GetHistory returns user's Mythic Lightning game history
+func mythicResponse(round *services.MythicRound) MythicSpinResponse {
+	resp := MythicSpinResponse{
+		RoundRef:         services.MythicRoundRef(round.SessionID),
+		InitialGrid:      round.InitialGrid,
+		Grid:             round.Grid,
+		Tumbles:          round.Tumbles,
+		TotalWin:         round.TotalWin,
+		BaseWin:          round.BaseWin,
+		TotalMultiplier:  round.TotalMultiplier,
+		CurrentBalance:   round.CurrentBalance,
+		ScatterCount:     round.ScatterCount,
+		FreeSpinsAwarded: round.FreeSpinsAwarded,
+		AnteBet:          round.AnteBet,
+		WeightProfile:    round.WeightProfile,
+		Stake:            round.Cost,
+		Jackpot:          round.Jackpot,
+		Missions:         round.Missions,
+		FreeSpinPack:     round.FreeSpinPack,
+		Message:          mythicMessage(round),
+		RealityCheck:     round.RealityCheck,
+	}
+	if round.FeatureBuy {
+		resp.FeatureBuy = true
+		resp.FreeSpins = round.FreeSpins
+		resp.FreeSpinsWin = round.FreeSpinsWin
+	}
+	return resp
+}
+
+// mythicMessage creates the response message shown by the client
+func mythicMessage(round *services.MythicRound) string {
+	if round.Jackpot != nil {
+		return fmt.Sprintf("%s JACKPOT!", strings.ToUpper(round.Jackpot.Tier))
+	}
+	if round.TotalWin <= 0 {
+		return "Try again!"
+	}
+	switch {
+	case round.FeatureBuy:
+		return fmt.Sprintf("FEATURE WIN! %d free spins paid %.2f", len(round.FreeSpins), round.FreeSpinsWin)
+	case round.FreeSpinsAwarded > 0:
+		return "FREE SPINS TRIGGERED!"
+	case round.TotalWin >= round.Bet*100:
+		return "MEGA WIN!"
+	case round.TotalWin >= round.Bet*50:
+		return "BIG WIN!"
+	default:
+		return "WIN!"
+	}
+}
+
+// RespondGameError answers a spin that could not be played or settled
+func RespondGameError(c *gin.Context, err error) {
+	var rgErr *services.ResponsibleGamingError
+	var rcErr *services.RealityCheckRequiredError
+	var betErr *services.BetLimitError
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
+	case errors.Is(err, services.ErrInsufficientBalance):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientBalance, "Insufficient balance")
+	case errors.Is(err, services.ErrNoFreeSpins):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeNoFreeSpins, "No free spins left in this pack")
+	case errors.Is(err, services.ErrFreeSpinPackExpired):
+		apierror.Respond(c, http.StatusGone, apierror.CodeFreeSpinsExpired, "Free spin pack has expired")
+	case errors.Is(err, services.ErrFeatureBuyDisabled):
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeFeatureBuyDisabled, "Feature buy is not available")
+	case errors.Is(err, services.ErrGameUnavailable):
+		apierror.Respond(c, http.StatusForbidden, apierror.CodeGameUnavailable, "This game is not available")
+	case errors.As(err, &betErr):
+		apierror.RespondInvalid(c, "Bet amount is out of range",
+			[]apierror.FieldError{{In: "body", Field: "bet", Message: betErr.Limit}})
+	case errors.Is(err, services.ErrWalletUnavailable):
+		apierror.Respond(c, http.StatusServiceUnavailable, apierror.CodeWalletUnavailable, "Wallet is unavailable, the spin has been voided")
+	case errors.Is(err, services.ErrWalletRejected):
+		apierror.Respond(c, http.StatusBadGateway, apierror.CodeWalletRejected, "Wallet refused the spin, it has been voided")
+	case errors.As(err, &rcErr):
+		apierror.RespondBody(c, http.StatusPreconditionRequired, rcErr.Body())
+	case errors.As(err, &rgErr):
+		apierror.RespondBody(c, http.StatusForbidden, rgErr.Body())
+	default:
+		apierror.Internal(c, "Failed to play spin")
+	}
+}
+
+// GetHistory returns user's Mythic Lightning game history, a page at a
+// time. Besides the shared history parameters it filters on mode (regular,
+// ante, feature_buy or free_spins) and result (win or loss).
 func (h *MythicHandler) GetHistory(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	var sessions []models.MythicSession
-	if err := h.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(20).
-		Find(&sessions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch history"})
+	q, err := ParseHistoryQuery(c, "bet_amount", "total_win")
+	if err == nil {
+		err = FilterResult(&q, c, "total_win")
+	}
+	if err == nil {
+		err = filterMythicMode(&q, c.Query("mode"))
+	}
+	if err != nil {
+		RespondHistory[models.MythicSession](c, "history", nil, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"history": sessions})
+	page, err := services.PageHistory[models.MythicSession](h.db.Where("user_id = ?", userID), q)
+	RespondHistory(c, "history", page, err)
+}
+
+func filterMythicMode(q *services.HistoryQuery, mode string) error {
+	var scope func(*gorm.DB) *gorm.DB
+	switch mode {
+	case "":
+		return nil
+	case "regular":
+		scope = func(db *gorm.DB) *gorm.DB {
+			return db.Where("feature_buy = ? AND ante_bet = ? AND free_spin_pack_id IS NULL", false, false)
+		}
+	case "ante":
+		scope = func(db *gorm.DB) *gorm.DB { return db.Where("ante_bet = ?", true) }
+	case "feature_buy":
+		scope = func(db *gorm.DB) *gorm.DB { return db.Where("feature_buy = ?", true) }
+	case "free_spins":
+		scope = func(db *gorm.DB) *gorm.DB { return db.Where("free_spin_pack_id IS NOT NULL") }
+	default:
+		return fmt.Errorf("%w: mode must be regular, ante, feature_buy or free_spins", services.ErrInvalidHistoryQuery)
+	}
+	q.Scopes = append(q.Scopes, scope)
+	return nil
 }
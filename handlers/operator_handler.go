@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/models"
+	"slot-sim/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentOperator returns the configuration of the operator the request
+// was resolved to: games, bet limits, currency and branding
+func CurrentOperator(c *gin.Context) {
+	operator := c.MustGet("operator").(*models.Operator)
+	c.JSON(http.StatusOK, gin.H{"operator": services.PublicConfig(operator)})
+}
+
+// RespondOperatorError maps operator errors to responses
+func RespondOperatorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrOperatorNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeOperatorNotFound, "Operator not found")
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeAPIKeyNotFound, "API key not found")
+	case errors.Is(err, services.ErrInvalidOperator):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidOperator, err.Error())
+	default:
+		apierror.Internal(c, "Failed to update operator")
+	}
+}
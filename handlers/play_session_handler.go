@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PlaySessionHandler struct {
+	playSessions *services.PlaySessionService
+}
+
+func NewPlaySessionHandler(db *gorm.DB) *PlaySessionHandler {
+	return &PlaySessionHandler{playSessions: services.NewPlaySessionService(db)}
+}
+
+type RealityCheckAckRequest struct {
+	Action string `json:"action" binding:"required,oneof=continue stop"`
+}
+
+// Current returns the active play session summary
+func (h *PlaySessionHandler) Current(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	session, err := h.playSessions.Current(userID)
+	if err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session": session})
+}
+
+// List returns the player's recent play session summaries
+func (h *PlaySessionHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessions, err := h.playSessions.List(userID, 50)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch play sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// AcknowledgeRealityCheck lets the player continue, or stop and end the session
+func (h *PlaySessionHandler) AcknowledgeRealityCheck(c *gin.Context) {
+	var req RealityCheckAckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	session, err := h.playSessions.Acknowledge(userID, req.Action == "stop")
+	if err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reality check acknowledged", "session": session})
+}
+
+func respondPlaySessionError(c *gin.Context, err error) {
+	var rcErr *services.RealityCheckRequiredError
+	switch {
+	case errors.As(err, &rcErr):
+		apierror.RespondBody(c, http.StatusPreconditionRequired, rcErr.Body())
+	case errors.Is(err, services.ErrNoActivePlaySession):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodePlaySessionNotFound, "No active play session")
+	case errors.Is(err, services.ErrNoRealityCheckPending):
+		apierror.Respond(c, http.StatusConflict, apierror.CodeNoRealityCheckPending, "No reality check is pending")
+	default:
+		apierror.Internal(c, "Failed to track play session")
+	}
+}
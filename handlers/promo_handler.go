@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PromoHandler struct {
+	promos *services.PromoService
+}
+
+func NewPromoHandler(db *gorm.DB) *PromoHandler {
+	return &PromoHandler{promos: services.NewPromoService(db)}
+}
+
+type RedeemPromoRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Redeem applies a promo code to the player's account
+func (h *PromoHandler) Redeem(c *gin.Context) {
+	var req RedeemPromoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	result, err := h.promos.Redeem(userID, req.Code)
+	if err != nil {
+		RespondPromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code redeemed", "reward": result})
+}
+
+// RespondPromoError maps promo code errors to responses
+func RespondPromoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPromoNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodePromoNotFound, "Promo code not found")
+	case errors.Is(err, services.ErrPromoExpired):
+		apierror.Respond(c, http.StatusGone, apierror.CodePromoExpired, "Promo code has expired")
+	case errors.Is(err, services.ErrPromoExhausted):
+		apierror.Respond(c, http.StatusGone, apierror.CodePromoExhausted, "Promo code is no longer available")
+	case errors.Is(err, services.ErrPromoAlreadyRedeemed):
+		apierror.Respond(c, http.StatusConflict, apierror.CodePromoAlreadyRedeemed, "You have already redeemed this promo code")
+	case errors.Is(err, services.ErrPromoNotEligible):
+		apierror.Respond(c, http.StatusForbidden, apierror.CodePromoNotEligible, err.Error())
+	case errors.Is(err, services.ErrInvalidPromo):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidPromo, err.Error())
+	case errors.Is(err, services.ErrInvalidFreeSpins):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidFreeSpins, err.Error())
+	default:
+		apierror.Internal(c, "Failed to redeem promo code")
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/models"
+	"slot-sim/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ResponsibleGamingHandler struct {
+	service *services.ResponsibleGamingService
+}
+
+func NewResponsibleGamingHandler(db *gorm.DB) *ResponsibleGamingHandler {
+	return &ResponsibleGamingHandler{service: services.NewResponsibleGamingService(db)}
+}
+
+type SetLimitRequest struct {
+	Type   models.LimitType   `json:"type" binding:"required,oneof=deposit loss wager"`
+	Period models.LimitPeriod `json:"period" binding:"required,oneof=daily weekly monthly"`
+	Amount float64            `json:"amount" binding:"gte=0"` // 0 removes the limit
+}
+
+type SetSessionLimitRequest struct {
+	Minutes int `json:"minutes" binding:"gte=0,lte=1440"` // 0 removes the limit
+}
+
+type CoolOffRequest struct {
+	Hours int `json:"hours" binding:"required,oneof=24 72 168 720 1008"` // 1 day up to 6 weeks
+}
+
+type SelfExclusionRequest struct {
+	Months    int  `json:"months" binding:"omitempty,oneof=6 12 24 60"`
+	Permanent bool `json:"permanent"`
+}
+
+// GetLimits returns the player's limits, pending increases and restrictions
+func (h *ResponsibleGamingHandler) GetLimits(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	limits, restriction, err := h.service.Limits(userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"limits":               limits,
+		"restrictions":         restriction,
+		"increase_delay_hours": services.LimitIncreaseDelay.Hours(),
+	})
+}
+
+// SetLimit sets a deposit, loss or wager limit
+func (h *ResponsibleGamingHandler) SetLimit(c *gin.Context) {
+	var req SetLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	limit, err := h.service.SetLimit(userID, req.Type, req.Period, req.Amount)
+	if err != nil {
+		respondResponsibleGamingError(c, err)
+		return
+	}
+
+	message := "Limit updated"
+	if limit.PendingAmount != nil {
+		message = "Limit increase will take effect after the cooling period"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "limit": limit})
+}
+
+// SetSessionLimit sets the maximum play session length
+func (h *ResponsibleGamingHandler) SetSessionLimit(c *gin.Context) {
+	var req SetSessionLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	restriction, err := h.service.SetSessionLimit(userID, req.Minutes)
+	if err != nil {
+		respondResponsibleGamingError(c, err)
+		return
+	}
+
+	message := "Session limit updated"
+	if restriction.PendingSessionLimit != nil {
+		message = "Session limit increase will take effect after the cooling period"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "restrictions": restriction})
+}
+
+// CoolOff starts a cool-off period
+func (h *ResponsibleGamingHandler) CoolOff(c *gin.Context) {
+	var req CoolOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	restriction, err := h.service.CoolOff(userID, time.Duration(req.Hours)*time.Hour)
+	if err != nil {
+		respondResponsibleGamingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cool-off period started", "restrictions": restriction})
+}
+
+// SelfExclude excludes the player for a number of months or permanently
+func (h *ResponsibleGamingHandler) SelfExclude(c *gin.Context) {
+	var req SelfExclusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Months == 0 && !req.Permanent) {
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeValidationFailed, "Choose 6, 12, 24 or 60 months, or permanent")
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	var duration time.Duration
+	if !req.Permanent {
+		duration = time.Until(time.Now().AddDate(0, req.Months, 0))
+	}
+
+	restriction, err := h.service.SelfExclude(userID, duration)
+	if err != nil {
+		respondResponsibleGamingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Self-exclusion active", "restrictions": restriction})
+}
+
+// respondResponsibleGamingError answers with the structured error when play
+// or deposits are blocked by a limit
+func respondResponsibleGamingError(c *gin.Context, err error) {
+	var rgErr *services.ResponsibleGamingError
+	if errors.As(err, &rgErr) {
+		status := http.StatusForbidden
+		if rgErr.Code == services.CodeInvalidLimitChange || rgErr.Code == services.CodeRestrictionActive {
+			status = http.StatusBadRequest
+		}
+		apierror.RespondBody(c, status, rgErr.Body())
+		return
+	}
+	apierror.Internal(c, "Failed to check responsible gaming limits")
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoundHandler struct {
+	replays *services.ReplayService
+}
+
+func NewRoundHandler(db *gorm.DB) *RoundHandler {
+	return &RoundHandler{replays: services.NewReplayService(db)}
+}
+
+// Get returns the replay data of one of the player's rounds of either game.
+// The ID is the round reference the spin responses return, e.g. ml-42.
+func (h *RoundHandler) Get(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	replay, err := h.replays.Round(userID, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRoundRef):
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRoundRef, err.Error())
+		case errors.Is(err, services.ErrRoundNotFound):
+			apierror.Respond(c, http.StatusNotFound, apierror.CodeRoundNotFound, "Round not found")
+		default:
+			apierror.Internal(c, "Failed to load round")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"round": replay})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	sessions *services.SessionService
+}
+
+func NewSessionHandler(db *gorm.DB) *SessionHandler {
+	return &SessionHandler{sessions: services.NewSessionService(db)}
+}
+
+type SessionView struct {
+	ID          uint      `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	Current     bool      `json:"current"`
+}
+
+// List returns the devices the user is currently signed in on
+func (h *SessionHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+	currentID := c.GetUint("sessionID")
+
+	sessions, err := h.sessions.List(userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch sessions")
+		return
+	}
+
+	views := make([]SessionView, len(sessions))
+	for i, s := range sessions {
+		views[i] = SessionView{
+			ID:          s.ID,
+			DeviceLabel: s.DeviceLabel,
+			IP:          s.IP,
+			UserAgent:   s.UserAgent,
+			CreatedAt:   s.CreatedAt,
+			LastSeenAt:  s.LastSeenAt,
+			Current:     s.ID == currentID,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": views})
+}
+
+// Revoke signs out one of the user's sessions, including the current one
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID := c.MustGet("userID").(uint)
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid session ID")
+		return
+	}
+
+	if err := h.sessions.Revoke(userID, uint(sessionID)); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			apierror.Respond(c, http.StatusNotFound, apierror.CodeSessionNotFound, "Session not found")
+			return
+		}
+		apierror.Internal(c, "Failed to sign out session")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session signed out"})
+}
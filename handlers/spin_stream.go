@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spin stream event names, in the order they are sent
+const (
+	streamEventSpin       = "spin"       // stake and initial grid
+	streamEventTumble     = "tumble"     // removed clusters, win, refilled positions and new grid
+	streamEventMultiplier = "multiplier" // lightning multipliers that struck during a tumble
+	streamEventSettlement = "settlement" // the committed round, same body as /api/v1/mythic/spin
+	streamEventError      = "error"      // settlement failed, the spin is void
+)
+
+// spinStream writes events as Server-Sent Events, or as one JSON object per
+// line when the client asks for application/x-ndjson
+type spinStream struct {
+	c      *gin.Context
+	ndjson bool
+}
+
+func newSpinStream(c *gin.Context) *spinStream {
+	stream := &spinStream{c: c, ndjson: strings.Contains(c.GetHeader("Accept"), "application/x-ndjson")}
+
+	header := c.Writer.Header()
+	if stream.ndjson {
+		header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		header.Set("Content-Type", "text/event-stream")
+	}
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	c.Status(http.StatusOK)
+	return stream
+}
+
+func (s *spinStream) send(event string, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if s.ndjson {
+		fmt.Fprintf(s.c.Writer, "{\"event\":%q,\"data\":%s}\n", event, body)
+	} else {
+		fmt.Fprintf(s.c.Writer, "event: %s\ndata: %s\n\n", event, body)
+	}
+	s.c.Writer.Flush()
+}
+
+// SpinStream plays a regular spin like Spin but streams it: the initial grid,
+// every tumble with its multiplier strikes, then the settlement. Tumbles are
+// sent while the settlement is being committed; the settlement event only
+// follows once it is, and an error event voids the spin if it fails.
+func (h *MythicHandler) SpinStream(c *gin.Context) {
+	var req MythicSpinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	// Refusals before the spin is drawn are still plain JSON errors
+	pending, err := h.games.StartMythicSpin(userID, req.Bet, req.AnteBet)
+	if err != nil {
+		RespondGameError(c, err)
+		return
+	}
+
+	stream := newSpinStream(c)
+	outcome := pending.Outcome
+	stream.send(streamEventSpin, gin.H{
+		"bet":            pending.Bet,
+		"stake":          pending.Cost,
+		"ante_bet":       outcome.AnteBet,
+		"weight_profile": outcome.WeightProfile,
+		"grid":           outcome.InitialGrid,
+	})
+
+	multiplier := 1.0
+	for i, tumble := range outcome.Tumbles {
+		stream.send(streamEventTumble, gin.H{
+			"index":    i,
+			"clusters": tumble.Clusters,
+			"win":      tumble.Win,
+			"refilled": tumble.Refilled,
+			"grid":     tumble.Grid,
+		})
+		if len(tumble.Strikes) > 0 {
+			multiplier += tumble.Multiplier - 1
+			stream.send(streamEventMultiplier, gin.H{
+				"index":            i,
+				"strikes":          tumble.Strikes,
+				"total_multiplier": multiplier,
+			})
+		}
+	}
+
+	round, err := pending.Wait()
+	if err != nil {
+		code, message := apierror.CodeInternal, "Failed to settle spin, it has been voided"
+		switch {
+		case errors.Is(err, services.ErrInsufficientBalance):
+			code, message = apierror.CodeInsufficientBalance, "Insufficient balance, the spin has been voided"
+		case errors.Is(err, services.ErrWalletUnavailable):
+			code, message = apierror.CodeWalletUnavailable, "Wallet is unavailable, the spin has been voided"
+		case errors.Is(err, services.ErrWalletRejected):
+			code, message = apierror.CodeWalletRejected, "Wallet refused the spin, it has been voided"
+		}
+		stream.send(streamEventError, apierror.Body(c, code, message))
+		return
+	}
+	stream.send(streamEventSettlement, mythicResponse(round))
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TournamentHandler struct {
+	tournaments *services.TournamentService
+}
+
+func NewTournamentHandler(db *gorm.DB) *TournamentHandler {
+	return &TournamentHandler{tournaments: services.NewTournamentService(db)}
+}
+
+// List returns running, upcoming and recently closed tournaments
+func (h *TournamentHandler) List(c *gin.Context) {
+	tournaments, err := h.tournaments.List(false)
+	if err != nil {
+		apierror.Internal(c, "Failed to fetch tournaments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tournaments": tournaments})
+}
+
+// Join opts the player into a tournament
+func (h *TournamentHandler) Join(c *gin.Context) {
+	tournamentID, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	entry, err := h.tournaments.Join(userID, tournamentID)
+	if err != nil {
+		RespondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Joined tournament", "entry": entry})
+}
+
+// Leaderboard returns the ranked players and the caller's own position
+func (h *TournamentHandler) Leaderboard(c *gin.Context) {
+	tournamentID, ok := tournamentIDParam(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("userID").(uint)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	board, err := h.tournaments.Leaderboard(tournamentID, userID, limit)
+	if err != nil {
+		RespondTournamentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+func tournamentIDParam(c *gin.Context) (uint, bool) {
+	tournamentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.InvalidID(c, "path", "id", "Invalid tournament ID")
+		return 0, false
+	}
+	return uint(tournamentID), true
+}
+
+// RespondTournamentError maps tournament errors to responses
+func RespondTournamentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTournamentNotFound):
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeTournamentNotFound, "Tournament not found")
+	case errors.Is(err, services.ErrTournamentClosed):
+		apierror.Respond(c, http.StatusConflict, apierror.CodeTournamentClosed, "Tournament is closed")
+	case errors.Is(err, services.ErrInvalidTournament):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidTournament, err.Error())
+	default:
+		apierror.Internal(c, "Failed to process tournament")
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/models"
+	"slot-sim/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TwoFactorHandler struct {
+	db      *gorm.DB
+	service *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(db *gorm.DB) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		db:      db,
+		service: services.NewTwoFactorService(db),
+	}
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Status reports whether 2FA is enabled for the current user
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TOTPEnabled,
+		"recovery_codes_remaining": services.RemainingRecoveryCodes(user),
+	})
+}
+
+// Setup starts enrolment and returns the secret and otpauth URI to show as a QR code
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := h.service.BeginEnrolment(user)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+		"message":     "Scan the code with your authenticator app and confirm with a generated code",
+	})
+}
+
+// Confirm enables 2FA and returns the one-time recovery codes
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.ConfirmEnrolment(user, req.Code)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// Disable turns 2FA off after a valid code or recovery code
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.RespondBind(c, err)
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Disable(user, req.Code); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *TwoFactorHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		apierror.Unauthorized(c)
+		return nil, false
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
+		return nil, false
+	}
+	return &user, true
+}
+
+func (h *TwoFactorHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidTwoFactorCode, "Invalid two-factor code")
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTwoFactorEnabled, "Two-factor authentication is already enabled")
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTwoFactorNotEnabled, "Two-factor authentication is not enabled")
+	case errors.Is(err, services.ErrTwoFactorNotEnrolled):
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeTwoFactorNotEnrolled, "Two-factor enrolment has not been started")
+	default:
+		apierror.Internal(c, "Failed to update two-factor settings")
+	}
+}
+
+// RespondLoginBlocked answers an attempt that is blocked by lockout or
+// throttling, a login or a step-up confirmation
+func RespondLoginBlocked(c *gin.Context, wait time.Duration, reason string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	if reason == models.LoginReasonLocked {
+		apierror.Respond(c, http.StatusLocked, apierror.CodeAccountLocked, "Account temporarily locked", gin.H{"retry_after": seconds})
+		return
+	}
+	apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeTooManyAttempts, "Too many login attempts", gin.H{"retry_after": seconds})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/models"
+	"slot-sim/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,6 +20,16 @@ func NewWalletHandler(db *gorm.DB) *WalletHandler {
 	return &WalletHandler{db: db}
 }
 
+// seamlessWallet refuses deposits and withdrawals for players whose
+// balance the operator holds; they move money with the operator
+func seamlessWallet(c *gin.Context) bool {
+	if !c.MustGet("operator").(*models.Operator).SeamlessWallet() {
+		return false
+	}
+	apierror.Respond(c, http.StatusConflict, apierror.CodeSeamlessWallet, "Deposits and withdrawals are handled by the operator")
+	return true
+}
+
 type TopUpRequest struct {
 	Amount      float64 `json:"amount" binding:"required,gt=0"`
 	BankName    string  `json:"bank_name" binding:"required"`
@@ -26,18 +40,28 @@ type TopUpRequest struct {
 func (h *WalletHandler) RequestTopUp(c *gin.Context) {
 	var req TopUpRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBind(c, err)
+		return
+	}
+	if seamlessWallet(c) {
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Unauthorized(c)
+		return
+	}
+
+	// Responsible gaming: exclusions and deposit limits
+	if err := services.NewResponsibleGamingService(h.db).CheckDeposit(userID.(uint), req.Amount); err != nil {
+		respondResponsibleGamingError(c, err)
 		return
 	}
 
 	transaction := models.Transaction{
 		UserID:      userID.(uint),
+		OperatorID:  c.GetUint("operatorID"),
 		Type:        models.TypeDeposit,
 		Amount:      req.Amount,
 		Status:      models.StatusPending,
@@ -47,7 +71,7 @@ func (h *WalletHandler) RequestTopUp(c *gin.Context) {
 	}
 
 	if err := h.db.Create(&transaction).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
+		apierror.Internal(c, "Failed to create transaction")
 		return
 	}
 
@@ -59,47 +83,105 @@ type WithdrawRequest struct {
 	BankName    string  `json:"bank_name" binding:"required"`
 	BankAccount string  `json:"bank_account" binding:"required"`
 	AccountName string  `json:"account_name" binding:"required"`
+	TOTPCode    string  `json:"totp_code"` // required when the user has 2FA enabled
 }
 
 func (h *WalletHandler) RequestWithdraw(c *gin.Context) {
 	var req WithdrawRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBind(c, err)
+		return
+	}
+	if seamlessWallet(c) {
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	// Check balance using Transaction to lock row? No, simple check for now.
-	// Strictly we should use a transaction but let's keep it simple.
+	// The balance is checked early here; the conditional deduction below is
+	// what keeps it from going negative
 	var user models.User
 	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeUserNotFound, "User not found")
 		return
 	}
 
 	if float64(user.Balance) < req.Amount {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientBalance, "Insufficient balance")
 		return
 	}
 
+	// Daily withdrawal limit of the player's VIP tier
+	if err := services.NewLoyaltyService(h.db).CheckWithdraw(user.ID, req.Amount); err != nil {
+		if errors.Is(err, services.ErrWithdrawLimitExceeded) {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeWithdrawLimitExceeded, "Daily withdrawal limit exceeded")
+			return
+		}
+		apierror.Internal(c, "Failed to check withdrawal limit")
+		return
+	}
+
+	// Step-up confirmation for users with 2FA enabled
+	if user.TOTPEnabled {
+		if req.TOTPCode == "" {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeTwoFactorRequired, "Two-factor code required", gin.H{"two_factor_required": true})
+			return
+		}
+		// Missed codes count as failed logins, so guessing them locks the account
+		guard := services.NewLoginGuard(h.db)
+		now := time.Now()
+		subject := services.LoginSubject{OperatorID: user.OperatorID, Username: user.Username, IP: c.ClientIP(), User: &user}
+		if wait, reason := guard.RetryAfter(subject, now); reason != "" {
+			RespondLoginBlocked(c, wait, reason)
+			return
+		}
+		if err := services.NewTwoFactorService(h.db).Verify(&user, req.TOTPCode); err != nil {
+			guard.RegisterFailure(subject, now)
+			guard.Record(&models.LoginAttempt{
+				UserID:    &user.ID,
+				Username:  user.Username,
+				IP:        subject.IP,
+				UserAgent: c.Request.UserAgent(),
+				Reason:    models.LoginReasonStepUpTOTP,
+			})
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidTwoFactorCode, "Invalid two-factor code", gin.H{"two_factor_required": true})
+			return
+		}
+		guard.RegisterSuccess(subject)
+	}
+
 	// Start DB transaction
 	tx := h.db.Begin()
 
-	// Deduct balance immediately
-	user.Balance -= int(req.Amount)
-	if err := tx.Save(&user).Error; err != nil {
+	// Deduct balance immediately, relative to the stored balance so credits
+	// and other withdrawals made meanwhile are neither lost nor overdrawn
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND balance >= ?", user.ID, int(req.Amount)).
+		Update("balance", gorm.Expr("balance - ?", int(req.Amount)))
+	if result.Error != nil {
+		tx.Rollback()
+		apierror.Internal(c, "Failed to update balance")
+		return
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInsufficientBalance, "Insufficient balance")
+		return
+	}
+	var newBalance int
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Select("balance").Scan(&newBalance).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+		apierror.Internal(c, "Failed to update balance")
 		return
 	}
 
 	transaction := models.Transaction{
 		UserID:      userID.(uint),
+		OperatorID:  user.OperatorID,
 		Type:        models.TypeWithdraw,
 		Amount:      req.Amount,
 		Status:      models.StatusPending,
@@ -110,27 +192,33 @@ func (h *WalletHandler) RequestWithdraw(c *gin.Context) {
 
 	if err := tx.Create(&transaction).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
+		apierror.Internal(c, "Failed to create transaction")
 		return
 	}
 
 	tx.Commit()
+	services.PublishBalance(h.db, user.ID, "withdraw_requested")
 
-	c.JSON(http.StatusOK, gin.H{"message": "Withdraw request submitted", "transaction": transaction, "new_balance": user.Balance})
+	c.JSON(http.StatusOK, gin.H{"message": "Withdraw request submitted", "transaction": transaction, "new_balance": newBalance})
 }
 
+// GetHistory returns the user's deposits and withdrawals a page at a time,
+// filtered on type and status besides the shared history parameters
 func (h *WalletHandler) GetHistory(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Unauthorized(c)
 		return
 	}
 
-	var transactions []models.Transaction
-	if err := h.db.Where("user_id = ?", userID).Order("created_at desc").Find(&transactions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+	q, err := ParseHistoryQuery(c, "amount")
+	if err != nil {
+		RespondHistory[models.Transaction](c, "transactions", nil, err)
 		return
 	}
+	FilterHistory(&q, c, "type", "type")
+	FilterHistory(&q, c, "status", "status")
 
-	c.JSON(http.StatusOK, gin.H{"transactions": transactions})
+	page, err := services.PageHistory[models.Transaction](h.db.Where("user_id = ?", userID), q)
+	RespondHistory(c, "transactions", page, err)
 }
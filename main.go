@@ -1,19 +1,46 @@
 package main
 
 import (
+	"log"
 	"slot-sim/config"
+	"slot-sim/enginerpc"
 	"slot-sim/middleware"
+	"slot-sim/openapi"
 	"slot-sim/routes"
+	"slot-sim/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	r := gin.Default()
-	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.CORSMiddleware(config.LoadCORSConfig()))
+	if config.ValidateRequests() {
+		r.Use(middleware.OpenAPIMiddleware(openapi.MustLoad()))
+	}
 	config.ConnectDB()
 	routes.SetupRoutes(r)
 
+	// Close tournaments as they end and pay their prizes
+	go services.NewTournamentService(config.DB).RunScheduler(time.Minute)
+
+	// Weekly cashback and VIP tier review
+	go services.NewLoyaltyService(config.DB).RunScheduler(time.Hour)
+
+	// Resend seamless wallet credits and rollbacks that got no answer
+	go services.NewWalletReconciler(config.DB).RunScheduler(time.Minute)
+
+	// The gRPC engine service, when an address is configured
+	if addr := config.GRPCAddr(); addr != "" {
+		go func() {
+			if err := enginerpc.ListenAndServe(addr); err != nil {
+				log.Fatalf("engine service: %v", err)
+			}
+		}()
+	}
+
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
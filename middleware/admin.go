@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/config"
 	"slot-sim/models"
 
@@ -12,21 +13,30 @@ func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("userID")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
+			apierror.Unauthorized(c)
 			return
 		}
 
 		var user models.User
 		if err := config.DB.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUserNotFound, "User not found")
 			return
 		}
 
 		if user.Role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-			c.Abort()
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeAdminRequired, "Admin access required")
+			return
+		}
+
+		// Admins must have 2FA enabled and the token must come from a 2FA login
+		if !user.TOTPEnabled {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeTwoFactorSetupRequired, "Two-factor authentication must be enabled for admin access",
+				gin.H{"two_factor_setup_required": true})
+			return
+		}
+		if !c.GetBool("mfa") {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeTwoFactorRequired, "Please sign in again with your two-factor code",
+				gin.H{"two_factor_required": true})
 			return
 		}
 
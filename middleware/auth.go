@@ -3,6 +3,10 @@ package middleware
 
 import (
 	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
 	"slot-sim/utils"
 	"strings"
 
@@ -13,20 +17,39 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+		// Browsers cannot set headers on a WebSocket handshake
+		if tokenString == "" && strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			tokenString = c.Query("token")
+		}
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing token"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeAuthRequired, "Missing token")
+			return
+		}
+
+		claims, err := utils.ParseToken(tokenString, utils.PurposeAccess)
+		if err != nil {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid token")
 			return
 		}
 
-		userID, err := utils.ValidateToken(tokenString)
+		// The token must belong to a session that has not been signed out
+		session, err := services.NewSessionService(config.DB).Touch(claims.UserID, claims.Id, c.ClientIP())
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeSessionExpired, "Session expired or signed out")
+			return
+		}
+
+		// and to a player of the operator the request is for
+		var operatorID uint
+		config.DB.Model(&models.User{}).Where("id = ?", claims.UserID).Select("operator_id").Scan(&operatorID)
+		if operatorID != c.GetUint("operatorID") {
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid token")
 			return
 		}
 
-		c.Set("userID", userID)
+		c.Set("userID", claims.UserID)
+		c.Set("sessionID", session.ID)
+		c.Set("mfa", claims.MFA)
 		c.Next()
 	}
 }
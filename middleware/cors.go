@@ -1,21 +1,108 @@
 package middleware
 
 import (
+	"net/http"
+	"slot-sim/config"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
-func CORSMiddleware() gin.HandlerFunc {
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		origin := c.GetHeader("Origin")
+		isPreflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		// Responses differ per origin, caches must not mix them up
+		c.Writer.Header().Add("Vary", "Origin")
+		if isPreflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if !originAllowed(cfg.AllowedOrigins, origin) {
+			if isPreflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// Without CORS headers the browser blocks the response
+			c.Next()
 			return
 		}
 
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if isPreflight {
+			if !methodAllowed(cfg.AllowedMethods, c.GetHeader("Access-Control-Request-Method")) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Writer.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			c.Writer.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			if cfg.MaxAge > 0 {
+				c.Writer.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposedHeaders != "" {
+			c.Writer.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+		}
+
 		c.Next()
 	}
 }
+
+// OriginAllowed reports whether the CORS policy allows the origin
+func OriginAllowed(cfg config.CORSConfig, origin string) bool {
+	return originAllowed(cfg.AllowedOrigins, origin)
+}
+
+// originAllowed matches exact origins and "scheme://*.domain" patterns.
+// A wildcard pattern matches subdomains only, not the bare domain.
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == origin {
+			return true
+		}
+
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		// The origin's host must be a subdomain, not a path or userinfo that
+		// happens to end in the domain
+		originHost, ok := strings.CutPrefix(origin, scheme+"://")
+		if ok && !strings.ContainsAny(originHost, "/@") &&
+			strings.HasSuffix(originHost, "."+host) && len(originHost) > len(host)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func methodAllowed(allowed []string, method string) bool {
+	for _, m := range allowed {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
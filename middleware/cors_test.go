@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slot-sim/config"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testCORS = config.CORSConfig{
+	AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+	AllowedMethods:   []string{"GET", "POST"},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	ExposedHeaders:   []string{"X-Request-ID"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func newCORSRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORSMiddleware(testCORS))
+	r.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	r.OPTIONS("/ping", func(c *gin.Context) { c.String(http.StatusOK, "options") })
+	return r
+}
+
+func serveCORS(method, origin string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	newCORSRouter().ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	w := serveCORS(http.MethodGet, "https://app.example.com", nil)
+
+	if w.Code != http.StatusOK || w.Body.String() != "pong" {
+		t.Fatalf("got %d %q, want the handler's 200 pong", w.Code, w.Body.String())
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Expose-Headers":    "X-Request-ID",
+		"Vary":                             "Origin",
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+		t.Errorf("Access-Control-Allow-Methods = %q on a simple request", got)
+	}
+}
+
+func TestCORSRejectedOrigin(t *testing.T) {
+	w := serveCORS(http.MethodGet, "https://evil.example.net", nil)
+
+	// The request is served, the browser blocks the response for lack of headers
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q for a rejected origin", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q for a rejected origin", got)
+	}
+}
+
+func TestCORSWithoutOrigin(t *testing.T) {
+	w := serveCORS(http.MethodGet, "", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q without an Origin header", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin string
+		method string
+		status int
+		allow  string
+	}{
+		{"allowed", "https://app.example.com", "POST", http.StatusNoContent, "https://app.example.com"},
+		{"allowed method in lower case", "https://app.example.com", "post", http.StatusNoContent, "https://app.example.com"},
+		{"method not allowed", "https://app.example.com", "DELETE", http.StatusForbidden, "https://app.example.com"},
+		{"origin not allowed", "https://evil.example.net", "POST", http.StatusForbidden, ""},
+		{"wildcard subdomain", "https://shop.example.org", "GET", http.StatusNoContent, "https://shop.example.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCORS(http.MethodOptions, tt.origin, map[string]string{
+				"Access-Control-Request-Method":  tt.method,
+				"Access-Control-Request-Headers": "Authorization",
+			})
+
+			if w.Code != tt.status {
+				t.Fatalf("got %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allow {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allow)
+			}
+			vary := w.Header().Values("Vary")
+			if len(vary) != 3 || vary[0] != "Origin" || vary[1] != "Access-Control-Request-Method" || vary[2] != "Access-Control-Request-Headers" {
+				t.Errorf("Vary = %q", vary)
+			}
+			if tt.status != http.StatusNoContent {
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+				t.Errorf("Access-Control-Allow-Methods = %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+				t.Errorf("Access-Control-Allow-Headers = %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", got)
+			}
+		})
+	}
+}
+
+// An OPTIONS request without Access-Control-Request-Method is not a
+// preflight and reaches the route
+func TestCORSPlainOptions(t *testing.T) {
+	w := serveCORS(http.MethodOptions, "https://app.example.com", nil)
+
+	if w.Code != http.StatusOK || w.Body.String() != "options" {
+		t.Fatalf("got %d %q, want the handler's 200 options", w.Code, w.Body.String())
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://other.example.com", false},
+
+		// Wildcards match subdomains only, not the bare domain
+		{"https://shop.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"http://shop.example.org", false},
+		{"https://shopexample.org", false},
+		{"https://example.org.evil.com", false},
+		{"https://evil.com/.example.org", false},
+		{"https://evil.com@shop.example.org", false},
+	}
+	for _, tt := range tests {
+		if got := originAllowed(testCORS.AllowedOrigins, tt.origin); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
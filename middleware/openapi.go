@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIMiddleware rejects requests whose parameters or JSON body do not
+// conform to the API specification. Legacy aliases are checked against the
+// operation of their versioned path; other routes the specification does
+// not describe are let through.
+func OpenAPIMiddleware(spec *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := operation(spec, c)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "Could not read request body")
+				return
+			}
+			// Hand the body on to the handler
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+
+		if err := spec.ValidateRequest(op, c.Request, params, body); err != nil {
+			var verr *openapi.ValidationError
+			errors.As(err, &verr)
+			details := make([]apierror.FieldError, len(verr.Problems))
+			for i, problem := range verr.Problems {
+				details[i] = apierror.FieldError(problem)
+			}
+			apierror.RespondInvalid(c, "Request does not match the API specification", details)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OpenAPIResponseCheck validates every response of a described route
+// against the specification and passes the outcome to report. It does not
+// change the response; it is meant for development and for the specification test in routes.
+// With strict set, properties the specification does not declare count as
+// mismatches.
+func OpenAPIResponseCheck(spec *openapi.Document, strict bool, report func(c *gin.Context, err error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := operation(spec, c)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// A hijacked connection (the event WebSocket) has no response to check
+		if recorder.hijacked {
+			return
+		}
+		report(c, spec.ValidateResponse(op, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes(), strict))
+	}
+}
+
+// operation finds the specified operation of the matched route
+func operation(spec *openapi.Document, c *gin.Context) *openapi.Operation {
+	path := c.FullPath()
+	if current := CurrentPath(path); current != "" {
+		path = current
+	}
+	return spec.Operation(c.Request.Method, path)
+}
+
+// responseRecorder keeps a copy of the body written through it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return r.ResponseWriter.Hijack()
+}
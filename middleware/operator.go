@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slot-sim/apierror"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers that select the operator of a request. An API key identifies the
+// operator's own backend and takes precedence over the code.
+const (
+	OperatorHeader = "X-Operator"
+	APIKeyHeader   = "X-API-Key"
+)
+
+// OperatorMiddleware resolves the operator a request is for from the
+// X-API-Key or X-Operator header or the host, falling back to the default
+// operator, and stores it as "operator" and its ID as "operatorID"
+func OperatorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operator, err := services.NewOperatorService(config.DB).Resolve(
+			c.GetHeader(APIKeyHeader), c.GetHeader(OperatorHeader), c.Request.Host)
+		switch {
+		case errors.Is(err, services.ErrInvalidAPIKey):
+			apierror.Respond(c, http.StatusUnauthorized, apierror.CodeInvalidAPIKey, "Invalid API key")
+			return
+		case errors.Is(err, services.ErrOperatorNotFound):
+			apierror.Respond(c, http.StatusNotFound, apierror.CodeOperatorNotFound, "Operator not found")
+			return
+		case errors.Is(err, services.ErrOperatorInactive):
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeOperatorInactive, "Operator is not active")
+			return
+		case err != nil:
+			apierror.Internal(c, "Failed to resolve operator")
+			return
+		}
+
+		c.Set("operator", operator)
+		c.Set("operatorID", operator.ID)
+		c.Next()
+	}
+}
+
+// PlatformAdminMiddleware only lets through admins of the default operator,
+// who run the deployment. It must follow AdminMiddleware.
+func PlatformAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operator, ok := c.Get("operator")
+		if !ok || operator.(*models.Operator).Code != models.DefaultOperatorCode {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodePlatformAdminRequired, "Platform admin access required")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"slot-sim/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID both ways
+const RequestIDHeader = "X-Request-ID"
+
+// A caller's request ID is kept when it is short and harmless in logs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an ID, echoed in the X-Request-ID response
+// header and in error bodies. An ID sent by the caller, such as a proxy, is
+// reused.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(apierror.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix is the base path of the current API version
+const APIPrefix = "/api/v1"
+
+// The prefixes the routes had before the API was versioned and where they
+// live now. The old paths keep answering as deprecated aliases.
+var legacyPrefixes = []struct{ legacy, current string }{
+	{"/register", APIPrefix + "/auth/register"},
+	{"/login", APIPrefix + "/auth/login"},
+	{"/user", APIPrefix + "/user"},
+	{"/api", APIPrefix},
+}
+
+// LegacyPath returns the unversioned alias of a path under APIPrefix, ""
+// when it has none
+func LegacyPath(path string) string {
+	for _, p := range legacyPrefixes {
+		if hasPathPrefix(path, p.current) {
+			return p.legacy + strings.TrimPrefix(path, p.current)
+		}
+	}
+	return ""
+}
+
+// CurrentPath returns the path under APIPrefix a legacy alias stands for,
+// "" when path is not a legacy alias
+func CurrentPath(path string) string {
+	if hasPathPrefix(path, APIPrefix) {
+		return ""
+	}
+	for _, p := range legacyPrefixes {
+		if hasPathPrefix(path, p.legacy) {
+			return p.current + strings.TrimPrefix(path, p.legacy)
+		}
+	}
+	return ""
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// Deprecated marks the responses of a legacy alias with a Deprecation
+// header and a Link to the versioned path
+func Deprecated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		if successor := CurrentPath(c.Request.URL.Path); successor != "" {
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
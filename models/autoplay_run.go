@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type AutoplayStatus string
+
+const (
+	AutoplayRunning     AutoplayStatus = "running"
+	AutoplayCompleted   AutoplayStatus = "completed"   // all requested spins were played
+	AutoplayStopped     AutoplayStatus = "stopped"     // a stop condition or a refused spin ended the run
+	AutoplayCancelled   AutoplayStatus = "cancelled"   // cancelled by the player
+	AutoplayInterrupted AutoplayStatus = "interrupted" // the server restarted during the run
+)
+
+// AutoplayRun is a server-side series of spins with stop conditions.
+// Zero-valued stop thresholds are disabled.
+type AutoplayRun struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	UserID           uint           `gorm:"index" json:"user_id"`
+	Game             string         `json:"game"`
+	Bet              float64        `json:"bet"`
+	TotalSpins       int            `json:"total_spins"`
+	StopOnFeature    bool           `json:"stop_on_feature"`
+	StopOnWinAbove   float64        `json:"stop_on_win_above"`
+	StopBalanceBelow float64        `json:"stop_balance_below"`
+	StopNetLossAbove float64        `json:"stop_net_loss_above"`
+	SpinsPlayed      int            `json:"spins_played"`
+	TotalWagered     float64        `json:"total_wagered"`
+	TotalWon         float64        `json:"total_won"`
+	NetResult        float64        `json:"net_result"`
+	BiggestWin       float64        `json:"biggest_win"`
+	LastBalance      float64        `json:"last_balance"`
+	Status           AutoplayStatus `gorm:"index" json:"status"`
+	StopReason       string         `json:"stop_reason,omitempty"`
+	Results          string         `gorm:"type:text" json:"-"` // JSON array of per-spin results
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	FinishedAt       *time.Time     `json:"finished_at,omitempty"`
+}
+
+func (AutoplayRun) TableName() string {
+	return "autoplay_runs"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// BalanceAdjustment records a manual change to a user's balance, e.g. a
+// correction made by an operator, together with the reason for it
+type BalanceAdjustment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"index" json:"user_id"`
+	Amount        int       `json:"amount"` // positive credits, negative debits
+	BalanceBefore int       `json:"balance_before"`
+	BalanceAfter  int       `json:"balance_after"`
+	Reason        string    `gorm:"not null" json:"reason"`
+	Actor         string    `json:"actor"`                   // who made the adjustment
+	WalletCredit  string    `json:"wallet_credit,omitempty"` // transaction ID of the seamless wallet credit paying it
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (BalanceAdjustment) TableName() string {
+	return "balance_adjustments"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// FortuneRound is the complete record of one Fortune Gems spin, the 3x3
+// counterpart of MythicSession
+type FortuneRound struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"index:idx_fortune_rounds_user_created,priority:1" json:"user_id"`
+	GamelogID     uint      `gorm:"index" json:"gamelog_id"`
+	Bet           int       `json:"bet"`
+	Grid          string    `gorm:"type:text" json:"grid"`          // JSON array 3x3
+	WinningLines  string    `gorm:"type:text" json:"winning_lines"` // JSON array of payline indexes
+	SpecialSymbol string    `json:"special_symbol"`
+	Multiplier    int       `json:"multiplier"`
+	IsFortuneSpin bool      `json:"is_fortune_spin"`
+	WheelPrize    int       `json:"wheel_prize"` // multiple of the bet won on the wheel
+	BaseWin       int       `json:"base_win"`
+	BonusWin      int       `json:"bonus_win"`
+	FinalWin      int       `json:"final_win"`
+	JackpotWin    int       `json:"jackpot_win"`
+	BalanceBefore int       `json:"balance_before"`
+	BalanceAfter  int       `json:"balance_after"`
+	RNGSeed       int64     `json:"rng_seed"` // seed the round was drawn from
+	CreatedAt     time.Time `gorm:"index:idx_fortune_rounds_user_created,priority:2" json:"created_at"`
+}
+
+func (FortuneRound) TableName() string {
+	return "fortune_rounds"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// FreeSpinPack is a number of Mythic Lightning spins at a fixed bet that the
+// player can play without stake. Winnings are credited to the balance and
+// tracked on the pack.
+type FreeSpinPack struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	Bet       float64    `gorm:"not null" json:"bet"`
+	Total     int        `gorm:"not null" json:"total"`
+	Remaining int        `gorm:"not null" json:"remaining"`
+	TotalWin  float64    `gorm:"not null;default:0" json:"total_win"`
+	Source    string     `json:"source"` // e.g. "mission:land_scatters"
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (FreeSpinPack) TableName() string {
+	return "free_spin_packs"
+}
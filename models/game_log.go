@@ -1,11 +1,24 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Gamelog struct {
-	gorm.Model
-	UserID        uint   `json:"user_id"`
+	// Same columns as gorm.Model, spelled out for the history index
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index:idx_gamelogs_user_created,priority:2"`
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	UserID        uint   `gorm:"index:idx_gamelogs_user_created,priority:1" json:"user_id"`
+	OperatorID    uint   `gorm:"not null;default:0;index" json:"operator_id"`
 	Action        string `json:"action"`
+	Bet           int    `json:"bet"`
 	Outcome       string `json:"outcome"`        // win or lose
 	BalanceChange int    `json:"balance_change"` // Amount won or lost
+	Win           int    `json:"win"`
+	JackpotWin    int    `json:"jackpot_win"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Jackpot tiers
+const (
+	JackpotMini  = "mini"
+	JackpotMajor = "major"
+	JackpotGrand = "grand"
+)
+
+// Jackpot is one progressive pool shared by both games. Every bet adds
+// ContributionShare of the jackpot contribution to Amount; when the tier is
+// won the pool resets to SeedValue.
+type Jackpot struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	Tier              string     `gorm:"uniqueIndex;not null" json:"tier"`
+	SeedValue         float64    `gorm:"not null" json:"seed_value"`
+	Amount            float64    `gorm:"not null" json:"amount"`
+	ContributionShare float64    `gorm:"not null" json:"contribution_share"` // fraction of the contribution fed to this tier
+	TriggerChance     float64    `gorm:"not null" json:"trigger_chance"`     // probability per spin
+	LastWonAt         *time.Time `json:"last_won_at,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (Jackpot) TableName() string {
+	return "jackpots"
+}
+
+// JackpotWin is one jackpot award
+type JackpotWin struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JackpotID uint      `gorm:"index" json:"jackpot_id"`
+	Tier      string    `json:"tier"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Game      string    `json:"game"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (JackpotWin) TableName() string {
+	return "jackpot_wins"
+}
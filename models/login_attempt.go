@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Reasons recorded on a LoginAttempt
+const (
+	LoginReasonSuccess         = "success"
+	LoginReasonUnknownUser     = "unknown_user"
+	LoginReasonInvalidPassword = "invalid_password"
+	LoginReasonLocked          = "account_locked"
+	LoginReasonThrottled       = "throttled"
+	LoginReasonInvalidTOTP     = "invalid_totp"
+	LoginReasonStepUpTOTP      = "step_up_invalid_totp" // a withdrawal confirmed with a wrong code
+)
+
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index" json:"user_id"` // nil when the username does not exist
+	Username  string    `gorm:"index" json:"username"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
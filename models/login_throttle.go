@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LoginThrottle counts failed logins for a username that do not depend on
+// the account existing. The row of a client IP drives the progressive
+// delay; the row with an empty IP locks out a username no account has, the
+// way a real account is locked, so the two cannot be told apart.
+type LoginThrottle struct {
+	ID                  uint   `gorm:"primaryKey"`
+	OperatorID          uint   `gorm:"not null;uniqueIndex:idx_login_throttles_key,priority:1"`
+	Username            string `gorm:"not null;uniqueIndex:idx_login_throttles_key,priority:2"`
+	IP                  string `gorm:"not null;uniqueIndex:idx_login_throttles_key,priority:3"`
+	FailedLoginAttempts int    `gorm:"not null;default:0"`
+	LastFailedLoginAt   *time.Time
+	LockedUntil         *time.Time
+	UpdatedAt           time.Time
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// VIP tiers, lowest first
+const (
+	TierBronze   = "bronze"
+	TierSilver   = "silver"
+	TierGold     = "gold"
+	TierPlatinum = "platinum"
+)
+
+// LoyaltyAccount holds a player's loyalty points and VIP tier
+type LoyaltyAccount struct {
+	ID           uint       `gorm:"primaryKey" json:"-"`
+	UserID       uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Points       float64    `gorm:"not null;default:0" json:"points"` // earned since joining
+	Tier         string     `gorm:"not null;default:bronze" json:"tier"`
+	TierSince    time.Time  `json:"tier_since"`
+	LastReviewAt *time.Time `json:"last_review_at,omitempty"` // last downgrade review
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (LoyaltyAccount) TableName() string {
+	return "loyalty_accounts"
+}
+
+// LoyaltyPointEntry records the points earned on one spin
+type LoyaltyPointEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index:idx_loyalty_entry_user_time;not null" json:"user_id"`
+	Game      string    `json:"game"`
+	Wagered   float64   `json:"wagered"`
+	Points    float64   `json:"points"`
+	CreatedAt time.Time `gorm:"index:idx_loyalty_entry_user_time" json:"created_at"`
+}
+
+func (LoyaltyPointEntry) TableName() string {
+	return "loyalty_point_entries"
+}
+
+// CashbackPayout is the weekly cashback paid to a player on net losses. One
+// payout per player and week; the credit itself is a BalanceAdjustment.
+type CashbackPayout struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"uniqueIndex:idx_cashback_period;not null" json:"user_id"`
+	PeriodStart  time.Time `gorm:"uniqueIndex:idx_cashback_period" json:"period_start"`
+	PeriodEnd    time.Time `json:"period_end"`
+	Tier         string    `json:"tier"`
+	NetLoss      float64   `json:"net_loss"`
+	Rate         float64   `json:"rate"`
+	Amount       int       `json:"amount"`
+	AdjustmentID uint      `json:"adjustment_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (CashbackPayout) TableName() string {
+	return "cashback_payouts"
+}
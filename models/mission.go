@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Mission periods; permanent missions are achievements
+const (
+	MissionDaily     = "daily"
+	MissionWeekly    = "weekly"
+	MissionPermanent = "permanent"
+)
+
+// Mission metrics, evaluated against every settled spin
+const (
+	MetricSpins       = "spins"          // +1 per spin
+	MetricWagered     = "wagered"        // + stake
+	MetricTumbleChain = "tumble_chain"   // +1 per spin with at least MinValue tumbles
+	MetricScatters    = "scatters"       // +1 per spin landing at least MinValue scatters
+	MetricWinMultiple = "win_multiplier" // +1 per spin winning at least MinValue x the bet
+	MetricFeature     = "feature"        // +1 per free spins trigger or fortune wheel
+	MetricJackpot     = "jackpot"        // +1 per jackpot won
+)
+
+// Mission reward types
+const (
+	RewardNone      = "none"
+	RewardBalance   = "balance"
+	RewardFreeSpins = "free_spins"
+)
+
+// Mission is a rule: reach Target on Metric within the period, counting only
+// spins of Game when it is set
+type Mission struct {
+	ID           uint    `gorm:"primaryKey" json:"id"`
+	Code         string  `gorm:"uniqueIndex;not null" json:"code"`
+	Title        string  `gorm:"not null" json:"title"`
+	Description  string  `json:"description"`
+	Game         string  `json:"game"` // "" for both games
+	Metric       string  `gorm:"not null" json:"metric"`
+	MinValue     float64 `json:"min_value"`
+	Target       float64 `gorm:"not null" json:"target"`
+	Period       string  `gorm:"not null" json:"period"`
+	RewardType   string  `gorm:"not null;default:none" json:"reward_type"`
+	RewardAmount int     `json:"reward_amount"`        // credit, or number of free spins
+	RewardBet    float64 `json:"reward_bet,omitempty"` // bet of rewarded free spins
+	Badge        string  `json:"badge,omitempty"`      // shown on the profile once achieved
+	Active       bool    `gorm:"not null;default:true" json:"active"`
+}
+
+func (Mission) TableName() string {
+	return "missions"
+}
+
+// MissionProgress is a player's progress on a mission in one period
+type MissionProgress struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"uniqueIndex:idx_mission_progress;not null" json:"user_id"`
+	MissionID   uint       `gorm:"uniqueIndex:idx_mission_progress;not null" json:"mission_id"`
+	PeriodStart time.Time  `gorm:"uniqueIndex:idx_mission_progress" json:"period_start"`
+	Progress    float64    `gorm:"not null;default:0" json:"progress"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (MissionProgress) TableName() string {
+	return "mission_progress"
+}
@@ -8,18 +8,28 @@ import (
 
 type MythicSession struct {
 	ID               uint      `gorm:"primaryKey" json:"id"`
-	UserID           uint      `json:"user_id"`
-	BetAmount        float64   `json:"bet_amount"`
-	Grid             string    `json:"grid" gorm:"type:text"` // JSON array 6x5
+	UserID           uint      `gorm:"index:idx_mythic_sessions_user_created,priority:1" json:"user_id"`
+	OperatorID       uint      `gorm:"not null;default:0;index" json:"operator_id"`
+	BetAmount        float64   `json:"bet_amount"` // amount debited, the feature price on a bonus buy
+	BaseBet          float64   `json:"base_bet"`
+	FeatureBuy       bool      `json:"feature_buy" gorm:"not null;default:false;index"`
+	AnteBet          bool      `json:"ante_bet" gorm:"not null;default:false"`
+	WeightProfile    string    `json:"weight_profile"`                // symbol weights the round was drawn from
+	InitialGrid      string    `json:"initial_grid" gorm:"type:text"` // JSON array 6x5, empty on rounds recorded before it was kept
+	Grid             string    `json:"grid" gorm:"type:text"`         // JSON array 6x5, after the last tumble
 	TumblesCount     int       `json:"tumbles_count"`
-	Multipliers      string    `json:"multipliers" gorm:"type:text"` // JSON array
+	Tumbles          string    `json:"tumbles" gorm:"type:text"`                    // JSON array of TumbleResult
+	Multipliers      string    `json:"multipliers" gorm:"type:text"`                // JSON array of the strikes per tumble; older rounds hold the tumbles here
+	FreeSpinRounds   string    `json:"free_spin_rounds,omitempty" gorm:"type:text"` // JSON array of the feature buy's free spins
 	TotalWin         float64   `json:"total_win"`
+	JackpotWin       float64   `json:"jackpot_win"`
 	BaseWin          float64   `json:"base_win"`
 	MultiplierWin    float64   `json:"multiplier_win"`
 	FreeSpinsActive  bool      `json:"free_spins_active"`
 	FreeSpinsRemain  int       `json:"free_spins_remain"`
 	GlobalMultiplier float64   `json:"global_multiplier"`
-	CreatedAt        time.Time `json:"created_at"`
+	FreeSpinPackID   *uint     `gorm:"index" json:"free_spin_pack_id,omitempty"` // set when played from a free spin pack
+	CreatedAt        time.Time `gorm:"index:idx_mythic_sessions_user_created,priority:2" json:"created_at"`
 }
 
 func (MythicSession) TableName() string {
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultOperatorCode is the operator that runs the deployment. Requests
+// that name no operator belong to it, as do all rows recorded before
+// operators existed, and its admins see every operator.
+const DefaultOperatorCode = "default"
+
+// Operator is one brand served by the deployment. Players, their
+// transactions and their rounds belong to exactly one operator.
+type Operator struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	Code           string            `gorm:"uniqueIndex;not null" json:"code"` // sent in the X-Operator header
+	Name           string            `gorm:"not null" json:"name"`
+	Host           string            `gorm:"index" json:"host"` // host name the brand is served on, optional
+	Currency       string            `gorm:"not null;default:USD" json:"currency"`
+	Games          []string          `gorm:"serializer:json" json:"games"` // games offered, empty for all
+	FortuneMinBet  int               `json:"fortune_min_bet"`              // 0 = the game's own limit
+	FortuneMaxBet  int               `json:"fortune_max_bet"`
+	MythicMinBet   float64           `json:"mythic_min_bet"`
+	MythicMaxBet   float64           `json:"mythic_max_bet"`
+	Branding       map[string]string `gorm:"serializer:json" json:"branding"`           // passed to the frontend as is
+	WalletURL      string            `json:"wallet_url"`                                // seamless wallet endpoint, empty to hold balances here
+	WalletSecret   string            `json:"-"`                                         // signs the calls to WalletURL
+	FeatureBuy     bool              `gorm:"not null;default:false" json:"feature_buy"` // offers the Mythic Lightning bonus buy
+	FeatureBuyCost float64           `json:"feature_buy_cost"`                          // its price as a multiple of the bet, 0 = FEATURE_BUY_COST
+	Active         bool              `gorm:"not null;default:true" json:"active"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+func (Operator) TableName() string {
+	return "operators"
+}
+
+// SeamlessWallet reports whether the operator holds its players' balances
+// and rounds are settled by calling its wallet
+func (o *Operator) SeamlessWallet() bool {
+	return o.WalletURL != ""
+}
+
+// OperatorAPIKey lets an operator's backend identify itself with the
+// X-API-Key header. Only a hash of the key is stored.
+type OperatorAPIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	OperatorID uint       `gorm:"index;not null" json:"operator_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // first characters of the key, to tell keys apart
+	KeyHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (OperatorAPIKey) TableName() string {
+	return "operator_api_keys"
+}
+
+// Tables whose rows belong to an operator
+var operatorScopedTables = []string{"users", "transactions", "gamelogs", "mythic_sessions"}
+
+// MigrateOperators creates the default operator, offering the bonus buy
+// when featureBuy is set, and assigns it every row recorded before
+// operators existed
+func MigrateOperators(db *gorm.DB, featureBuy bool) error {
+	operator := Operator{Code: DefaultOperatorCode, Name: "Slot Sim", Currency: "USD", Active: true, FeatureBuy: featureBuy}
+	if err := db.Where(Operator{Code: DefaultOperatorCode}).FirstOrCreate(&operator).Error; err != nil {
+		return err
+	}
+
+	for _, table := range operatorScopedTables {
+		err := db.Table(table).Where("operator_id = 0 OR operator_id IS NULL").Update("operator_id", operator.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
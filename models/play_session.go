@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PlaySession groups consecutive spins of a player across both games. A
+// session ends after a period of inactivity or when the player stops after
+// a reality check.
+type PlaySession struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	UserID              uint       `gorm:"index" json:"user_id"`
+	StartedAt           time.Time  `json:"started_at"`
+	LastActivityAt      time.Time  `json:"last_activity_at"`
+	EndedAt             *time.Time `json:"ended_at,omitempty"`
+	SpinCount           int        `json:"spin_count"`
+	TotalWagered        float64    `json:"total_wagered"`
+	TotalWon            float64    `json:"total_won"`
+	NetResult           float64    `json:"net_result"` // won minus wagered
+	LastRealityCheckAt  time.Time  `json:"last_reality_check_at"`
+	RealityCheckPending bool       `json:"reality_check_pending"`
+}
+
+func (PlaySession) TableName() string {
+	return "play_sessions"
+}
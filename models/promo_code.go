@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// PromoCode grants bonus credit or a pack of Mythic Lightning free spins.
+// Each player can redeem a code once.
+type PromoCode struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Code           string     `gorm:"uniqueIndex;not null" json:"code"` // stored upper case
+	Description    string     `json:"description"`
+	RewardType     string     `gorm:"not null" json:"reward_type"` // balance or free_spins
+	BonusAmount    int        `json:"bonus_amount,omitempty"`
+	FreeSpins      int        `json:"free_spins,omitempty"`
+	FreeSpinBet    float64    `json:"free_spin_bet,omitempty"`
+	FreeSpinDays   int        `json:"free_spin_days,omitempty"` // days to play the spins, 0 for no expiry
+	MaxRedemptions int        `json:"max_redemptions"`          // 0 for unlimited
+	Redemptions    int        `gorm:"not null;default:0" json:"redemptions"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	// Eligibility rules
+	MinTier           string    `json:"min_tier,omitempty"`             // lowest VIP tier allowed
+	MaxAccountAgeDays int       `json:"max_account_age_days,omitempty"` // new players only
+	RequiresDeposit   bool      `json:"requires_deposit"`               // at least one approved deposit
+	Active            bool      `gorm:"not null;default:true" json:"active"`
+	CreatedBy         string    `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (PromoCode) TableName() string {
+	return "promo_codes"
+}
+
+// PromoRedemption records a player redeeming a promo code
+type PromoRedemption struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	PromoCodeID    uint      `gorm:"uniqueIndex:idx_promo_redemption;not null" json:"promo_code_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_promo_redemption;not null" json:"user_id"`
+	AdjustmentID   *uint     `json:"adjustment_id,omitempty"`
+	FreeSpinPackID *uint     `json:"free_spin_pack_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
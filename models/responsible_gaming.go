@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type LimitType string
+type LimitPeriod string
+
+const (
+	LimitDeposit LimitType = "deposit"
+	LimitLoss    LimitType = "loss"
+	LimitWager   LimitType = "wager"
+
+	PeriodDaily   LimitPeriod = "daily"
+	PeriodWeekly  LimitPeriod = "weekly"
+	PeriodMonthly LimitPeriod = "monthly"
+)
+
+// GamingLimit caps how much a player may deposit, lose or wager per period.
+// Lowering a limit applies immediately; raising or removing it is stored as
+// pending and only applies once PendingEffectiveAt has passed.
+type GamingLimit struct {
+	ID                 uint        `gorm:"primaryKey" json:"id"`
+	UserID             uint        `gorm:"uniqueIndex:idx_gaming_limit" json:"user_id"`
+	Type               LimitType   `gorm:"uniqueIndex:idx_gaming_limit" json:"type"`
+	Period             LimitPeriod `gorm:"uniqueIndex:idx_gaming_limit" json:"period"`
+	Amount             float64     `json:"amount"` // 0 means no limit
+	PendingAmount      *float64    `json:"pending_amount,omitempty"`
+	PendingEffectiveAt *time.Time  `json:"pending_effective_at,omitempty"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+}
+
+func (GamingLimit) TableName() string {
+	return "gaming_limits"
+}
+
+// PlayerRestriction holds the session time limit, cool-off and self-exclusion of a player
+type PlayerRestriction struct {
+	UserID                    uint       `gorm:"primaryKey" json:"user_id"`
+	SessionLimitMinutes       int        `json:"session_limit_minutes"` // 0 means no limit
+	PendingSessionLimit       *int       `json:"pending_session_limit_minutes,omitempty"`
+	PendingSessionEffectiveAt *time.Time `json:"pending_session_effective_at,omitempty"`
+	CoolOffUntil              *time.Time `json:"cool_off_until,omitempty"`
+	SelfExcludedUntil         *time.Time `json:"self_excluded_until,omitempty"`
+	SelfExcludedPermanently   bool       `json:"self_excluded_permanently"`
+	SessionWindowStartedAt    *time.Time `json:"session_window_started_at,omitempty"`   // what the session limit is measured from
+	SessionWindowLastPlayAt   *time.Time `json:"session_window_last_play_at,omitempty"` // the latest spin counted in the window
+	UpdatedAt                 time.Time  `json:"updated_at"`
+}
+
+func (PlayerRestriction) TableName() string {
+	return "player_restrictions"
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Tournament scoring rules
+const (
+	ScoreHighestMultiplier = "highest_multiplier" // best single-spin win / bet
+	ScoreTotalWagered      = "total_wagered"
+)
+
+// Tournament statuses
+const (
+	TournamentOpen   = "open"
+	TournamentClosed = "closed"
+)
+
+// Tournament is a time-boxed competition on one game, or on both when Game
+// is empty. Scores are updated as spins settle; prizes are paid by rank
+// when the tournament closes.
+type Tournament struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"not null" json:"name"`
+	Game        string     `json:"game"` // "" for both games
+	StartsAt    time.Time  `gorm:"index" json:"starts_at"`
+	EndsAt      time.Time  `gorm:"index" json:"ends_at"`
+	MinBet      float64    `json:"min_bet"`
+	ScoringRule string     `gorm:"not null" json:"scoring_rule"`
+	AutoEnroll  bool       `json:"auto_enroll"`        // every eligible spin enters the player
+	Prizes      string     `gorm:"type:text" json:"-"` // JSON array of prize amounts, rank 1 first
+	Status      string     `gorm:"not null;default:open;index" json:"status"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Tournament) TableName() string {
+	return "tournaments"
+}
+
+// TournamentEntry is a player's standing in a tournament
+type TournamentEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TournamentID uint      `gorm:"uniqueIndex:idx_tournament_user;not null" json:"tournament_id"`
+	UserID       uint      `gorm:"uniqueIndex:idx_tournament_user;not null" json:"user_id"`
+	Score        float64   `gorm:"not null;default:0" json:"score"`
+	Spins        int       `gorm:"not null;default:0" json:"spins"`
+	ScoredAt     time.Time `json:"scored_at"` // last score improvement, breaks ties
+	FinalRank    int       `json:"final_rank,omitempty"`
+	Prize        int       `json:"prize,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (TournamentEntry) TableName() string {
+	return "tournament_entries"
+}
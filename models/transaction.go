@@ -18,14 +18,15 @@ const (
 
 type Transaction struct {
 	ID          uint              `gorm:"primaryKey" json:"id"`
-	UserID      uint              `json:"user_id"`
+	UserID      uint              `gorm:"index:idx_transactions_user_created,priority:1" json:"user_id"`
+	OperatorID  uint              `gorm:"not null;default:0;index" json:"operator_id"`
 	Type        TransactionType   `json:"type"`
 	Amount      float64           `json:"amount"`
 	Status      TransactionStatus `json:"status"`
 	BankName    string            `json:"bank_name"`
 	BankAccount string            `json:"bank_account"`
 	AccountName string            `json:"account_name"`
-	CreatedAt   time.Time         `json:"created_at"`
+	CreatedAt   time.Time         `gorm:"index:idx_transactions_user_created,priority:2" json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
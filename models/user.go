@@ -3,11 +3,24 @@ package models
 import "time"
 
 type User struct {
-	ID        uint      `gorm:"primarykey"`
-	Username  string    `gorm:"unique;not null"`
-	Password  string    `gorm:"not null"`
-	Balance   int       `gorm:"not null"`
-	Role      string    `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID                  uint   `gorm:"primarykey"`
+	OperatorID          uint   `gorm:"not null;default:0;uniqueIndex:idx_users_operator_username,priority:1"`
+	Username            string `gorm:"not null;uniqueIndex:idx_users_operator_username,priority:2"` // unique per operator
+	Password            string `gorm:"not null"`
+	Balance             int    `gorm:"not null"`
+	Role                string `gorm:"not null"`
+	FailedLoginAttempts int    `gorm:"not null;default:0"`
+	LastFailedLoginAt   *time.Time
+	LockedUntil         *time.Time
+	TOTPSecret          string    `json:"-"`
+	TOTPEnabled         bool      `gorm:"not null;default:false"`
+	TOTPLastStep        int64     `json:"-"`                  // last accepted time step, prevents code reuse
+	RecoveryCodes       string    `json:"-" gorm:"type:text"` // JSON array of hashed recovery codes
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+}
+
+// IsLocked reports whether the account is temporarily locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// UserSession is a signed-in device. Every issued token references one
+// session and stops working once the session is revoked.
+type UserSession struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index" json:"user_id"`
+	TokenID     string     `gorm:"uniqueIndex;not null" json:"-"` // jti claim of the issued token
+	DeviceLabel string     `json:"device_label"`
+	IP          string     `json:"ip"`
+	UserAgent   string     `json:"user_agent"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// Active reports whether tokens of this session are still accepted
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Movements sent to a remote wallet
+const (
+	WalletDebit    = "debit"
+	WalletCredit   = "credit"
+	WalletRollback = "rollback"
+)
+
+// Outcomes of a call to a remote wallet
+const (
+	WalletStatusPending  = "pending"
+	WalletStatusOK       = "ok"
+	WalletStatusRejected = "rejected" // refused by the wallet, e.g. insufficient funds
+	WalletStatusFailed   = "failed"   // no answer after every retry
+)
+
+// WalletTransaction is one call to an operator's remote wallet, recorded
+// before it is sent and kept whatever happens to the round. A failed credit
+// or rollback is resent at NextAttemptAt until the wallet answers; a pending
+// one with NextAttemptAt set is a credit queued outside a round, e.g. a
+// prize, whose RoundID is that of its balance adjustment.
+type WalletTransaction struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	OperatorID    uint       `gorm:"index;not null" json:"operator_id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`
+	RoundID       string     `gorm:"index;not null" json:"round_id"`
+	TransactionID string     `gorm:"uniqueIndex;not null" json:"transaction_id"` // idempotency key sent to the wallet
+	ReferenceID   string     `json:"reference_id,omitempty"`                     // the transaction a rollback voids
+	Type          string     `gorm:"not null" json:"type"`
+	Game          string     `json:"game"`
+	Amount        int        `json:"amount"`
+	Status        string     `gorm:"index;not null" json:"status"`
+	Attempts      int        `json:"attempts"`
+	Balance       int        `json:"balance"` // as reported by the wallet
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `gorm:"index" json:"next_attempt_at,omitempty"` // when a queued or failed credit or rollback is sent
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (WalletTransaction) TableName() string {
+	return "wallet_transactions"
+}
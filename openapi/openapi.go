@@ -0,0 +1,382 @@
+// Package openapi holds the OpenAPI 3 specification of the HTTP API and
+// validates requests and responses against it.
+//
+// The specification is openapi.json in this directory, embedded in the
+// binary and served at /openapi.json. Only the parts of OpenAPI the spec
+// uses are understood: local $refs, path and query parameters, JSON request
+// and response bodies, and the schema keywords listed on Schema.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+// JSON returns the specification document as served
+func JSON() []byte {
+	return specJSON
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *Document
+	loadErr  error
+)
+
+// Load parses the embedded specification. The document is parsed once and
+// shared.
+func Load() (*Document, error) {
+	loadOnce.Do(func() {
+		loaded, loadErr = Parse(specJSON)
+	})
+	return loaded, loadErr
+}
+
+// MustLoad is Load for callers that cannot start without the specification
+func MustLoad() *Document {
+	doc, err := Load()
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	return doc
+}
+
+// Document is a parsed specification
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+
+	operations map[string]*Operation // "METHOD /gin/:path"
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters"`
+	Responses  map[string]*Response  `json:"responses"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+
+	Method string `json:"-"`
+	Path   string `json:"-"` // in the router's syntax, /users/:id
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path or query
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route is a method and path served by the API
+type Route struct {
+	Method string
+	Path   string // in the router's syntax
+}
+
+// Parse reads a specification document and checks that its references
+// resolve
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	doc.operations = map[string]*Operation{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			method = strings.ToUpper(method)
+			op.Method = method
+			op.Path = routerPath(path)
+			doc.operations[method+" "+op.Path] = op
+
+			for _, param := range op.Parameters {
+				if _, err := doc.parameter(param); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+			for status, resp := range op.Responses {
+				if _, err := doc.response(resp); err != nil {
+					return nil, fmt.Errorf("%s %s %s: %w", method, path, status, err)
+				}
+			}
+		}
+	}
+	for name, schema := range doc.Components.Schemas {
+		if err := doc.checkRefs(schema); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+	return &doc, nil
+}
+
+// Operation returns the operation of a route, nil when the spec has none
+func (d *Document) Operation(method, path string) *Operation {
+	return d.operations[method+" "+path]
+}
+
+// Routes returns every route the specification describes, sorted by path
+func (d *Document) Routes() []Route {
+	routes := make([]Route, 0, len(d.operations))
+	for _, op := range d.operations {
+		routes = append(routes, Route{Method: op.Method, Path: op.Path})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// routerPath turns /users/{id} into /users/:id
+func routerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (d *Document) parameter(param *Parameter) (*Parameter, error) {
+	if param.Ref == "" {
+		return param, d.checkRefs(param.Schema)
+	}
+	resolved, ok := d.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", param.Ref)
+	}
+	return resolved, d.checkRefs(resolved.Schema)
+}
+
+func (d *Document) response(resp *Response) (*Response, error) {
+	if resp.Ref != "" {
+		resolved, ok := d.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %s", resp.Ref)
+		}
+		resp = resolved
+	}
+	for _, media := range resp.Content {
+		if err := d.checkRefs(media.Schema); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (d *Document) schema(s *Schema) (*Schema, error) {
+	if s == nil || s.Ref == "" {
+		return s, nil
+	}
+	resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", s.Ref)
+	}
+	return resolved, nil
+}
+
+func (d *Document) checkRefs(s *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		_, err := d.schema(s)
+		return err
+	}
+	if s.Pattern != "" && s.pattern == nil {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = pattern
+	}
+	children := append(append([]*Schema{s.Items, s.AdditionalProperties.Schema}, s.OneOf...), s.AllOf...)
+	for _, prop := range s.Properties {
+		children = append(children, prop)
+	}
+	for _, child := range children {
+		if err := d.checkRefs(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidationError lists every way a request or response departs from the
+// specification
+type ValidationError struct {
+	Problems []Problem
+}
+
+// Problem is one departure from the specification. In is where it was
+// found (path, query or body) and Field the parameter name or the JSON path
+// inside the body, empty for the body as a whole.
+type Problem struct {
+	In      string `json:"in"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.In + " " + p.Message
+	}
+	return p.In + " " + p.Field + " " + p.Message
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+	return strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) add(at location, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, Problem{In: at.in, Field: at.field, Message: fmt.Sprintf(format, args...)})
+}
+
+// location is where a value sits in a request or response
+type location struct {
+	in    string
+	field string
+}
+
+func (l location) property(name string) location {
+	if l.field == "" {
+		return location{l.in, name}
+	}
+	return location{l.in, l.field + "." + name}
+}
+
+func (l location) index(i int) location {
+	return location{l.in, fmt.Sprintf("%s[%d]", l.field, i)}
+}
+
+func (e *ValidationError) err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateRequest checks the path parameters, query string and JSON body of
+// a request to the operation. The body is passed separately so the caller
+// can hand it on to the handler afterwards.
+func (d *Document) ValidateRequest(op *Operation, r *http.Request, pathParams map[string]string, body []byte) error {
+	verr := &ValidationError{}
+	query := r.URL.Query()
+
+	for _, ref := range op.Parameters {
+		param, _ := d.parameter(ref)
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			value, present = query.Get(param.Name), query.Has(param.Name)
+		default:
+			continue
+		}
+		if !present || value == "" {
+			if param.Required {
+				verr.add(location{param.In, param.Name}, "is required")
+			}
+			continue
+		}
+		d.validateParam(verr, param, value)
+	}
+
+	if op.RequestBody != nil {
+		media := op.RequestBody.Content["application/json"]
+		at := location{in: "body"}
+		switch {
+		case len(body) == 0:
+			if op.RequestBody.Required {
+				verr.add(at, "is required")
+			}
+		case media != nil:
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				verr.add(at, "is not valid JSON")
+			} else {
+				d.validate(verr, media.Schema, value, at, false)
+			}
+		}
+	}
+	return verr.err()
+}
+
+// ValidateResponse checks a response of the operation. Only JSON bodies are
+// checked against a schema; with strict set, objects may not carry
+// properties the schema does not declare.
+func (d *Document) ValidateResponse(op *Operation, status int, contentType string, body []byte, strict bool) error {
+	verr := &ValidationError{}
+	at := location{in: "response"}
+
+	resp, ok := op.Responses[fmt.Sprint(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		verr.add(at, "status %d is not documented", status)
+		return verr.err()
+	}
+	resp, _ = d.response(resp)
+
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if len(resp.Content) == 0 {
+		return nil
+	}
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		verr.add(at, "content type %q is not documented for status %d", mediaType, status)
+		return verr.err()
+	}
+	if mediaType != "application/json" || media.Schema == nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		verr.add(at, "body is not valid JSON")
+		return verr.err()
+	}
+	d.validate(verr, media.Schema, value, at, strict)
+	return verr.err()
+}
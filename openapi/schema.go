@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the spec uses
+type Schema struct {
+	Ref                  string               `json:"$ref"`
+	Type                 string               `json:"type"`
+	Format               string               `json:"format"` // date-time and date are checked
+	Nullable             bool                 `json:"nullable"`
+	Enum                 []interface{}        `json:"enum"`
+	Pattern              string               `json:"pattern"`
+	MinLength            *int                 `json:"minLength"`
+	Minimum              *float64             `json:"minimum"`
+	Maximum              *float64             `json:"maximum"`
+	ExclusiveMinimum     bool                 `json:"exclusiveMinimum"`
+	MinItems             *int                 `json:"minItems"`
+	MaxItems             *int                 `json:"maxItems"`
+	Items                *Schema              `json:"items"`
+	Properties           map[string]*Schema   `json:"properties"`
+	Required             []string             `json:"required"`
+	AdditionalProperties AdditionalProperties `json:"additionalProperties"`
+	OneOf                []*Schema            `json:"oneOf"`
+	AllOf                []*Schema            `json:"allOf"`
+
+	pattern *regexp.Regexp
+}
+
+// AdditionalProperties is either a boolean or a schema for the values of
+// undeclared properties
+type AdditionalProperties struct {
+	Set     bool // the keyword is present
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	a.Set = true
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
+
+// validateParam checks a path or query value, converted to the type of its
+// schema first
+func (d *Document) validateParam(verr *ValidationError, param *Parameter, raw string) {
+	schema, _ := d.schema(param.Schema)
+	if schema == nil {
+		return
+	}
+	at := location{param.In, param.Name}
+
+	var value interface{} = raw
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			verr.add(at, "must be an integer")
+			return
+		}
+		value = float64(n)
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			verr.add(at, "must be a number")
+			return
+		}
+		value = n
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			verr.add(at, "must be true or false")
+			return
+		}
+		value = b
+	}
+	d.validate(verr, schema, value, at, false)
+}
+
+// validate checks a decoded JSON value and records every problem found
+// under the given location
+func (d *Document) validate(verr *ValidationError, s *Schema, value interface{}, at location, strict bool) {
+	s, err := d.schema(s)
+	if err != nil {
+		verr.add(at, "%v", err)
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	if value == nil {
+		if !s.Nullable && (s.Type != "" || len(s.OneOf) > 0 || len(s.AllOf) > 0) {
+			verr.add(at, "must not be null")
+		}
+		return
+	}
+
+	for _, part := range s.AllOf {
+		d.validate(verr, part, value, at, strict)
+	}
+	if len(s.OneOf) > 0 {
+		d.validateOneOf(verr, s, value, at, strict)
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		verr.add(at, "must be one of %v", s.Enum)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			verr.add(at, "must be an object")
+			return
+		}
+		d.validateObject(verr, s, object, at, strict)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			verr.add(at, "must be an array")
+			return
+		}
+		if s.MinItems != nil && len(array) < *s.MinItems {
+			verr.add(at, "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(array) > *s.MaxItems {
+			verr.add(at, "must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range array {
+			d.validate(verr, s.Items, item, at.index(i), strict)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			verr.add(at, "must be a string")
+			return
+		}
+		validateString(verr, s, str, at)
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			verr.add(at, "must be a %s", s.Type)
+			return
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			verr.add(at, "must be an integer")
+			return
+		}
+		validateNumber(verr, s, n, at)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			verr.add(at, "must be a boolean")
+		}
+	}
+}
+
+func (d *Document) validateObject(verr *ValidationError, s *Schema, object map[string]interface{}, at location, strict bool) {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			verr.add(at.property(name), "is required")
+		}
+	}
+	for name, value := range object {
+		if prop, ok := s.Properties[name]; ok {
+			d.validate(verr, prop, value, at.property(name), strict)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties.Schema != nil:
+			d.validate(verr, s.AdditionalProperties.Schema, value, at.property(name), strict)
+		case s.AdditionalProperties.Set && !s.AdditionalProperties.Allowed,
+			strict && !s.AdditionalProperties.Set && len(s.Properties) > 0:
+			verr.add(at.property(name), "is not a documented property")
+		}
+	}
+}
+
+// validateOneOf accepts the value when exactly one alternative matches
+func (d *Document) validateOneOf(verr *ValidationError, s *Schema, value interface{}, at location, strict bool) {
+	matches := 0
+	var first *ValidationError
+	for _, alternative := range s.OneOf {
+		attempt := &ValidationError{}
+		d.validate(attempt, alternative, value, at, strict)
+		if len(attempt.Problems) == 0 {
+			matches++
+		} else if first == nil {
+			first = attempt
+		}
+	}
+	switch {
+	case matches == 0:
+		verr.add(at, "matches none of the allowed schemas (%s)", first.Error())
+	case matches > 1:
+		verr.add(at, "matches more than one of the allowed schemas")
+	}
+}
+
+func validateString(verr *ValidationError, s *Schema, str string, at location) {
+	if s.MinLength != nil && len(str) < *s.MinLength {
+		verr.add(at, "must be at least %d characters", *s.MinLength)
+	}
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			verr.add(at, "must be an RFC 3339 date-time")
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			verr.add(at, "must be a date (YYYY-MM-DD)")
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		verr.add(at, "must match %s", s.Pattern)
+	}
+}
+
+func validateNumber(verr *ValidationError, s *Schema, n float64, at location) {
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && n <= *s.Minimum {
+			verr.add(at, "must be greater than %v", *s.Minimum)
+		} else if n < *s.Minimum {
+			verr.add(at, "must be at least %v", *s.Minimum)
+		}
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		verr.add(at, "must be at most %v", *s.Maximum)
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,522 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slot-sim/apierror"
+	"slot-sim/config"
+	"slot-sim/middleware"
+	"slot-sim/models"
+	"slot-sim/openapi"
+	"slot-sim/services"
+	"slot-sim/utils"
+	"slot-sim/walletstub"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operations the check cannot exercise over a recorded response
+var unchecked = map[string]string{
+	"GET /api/v1/events": "WebSocket upgrade",
+}
+
+type checker struct {
+	t       *testing.T
+	router  *gin.Engine
+	checked map[string]bool // operations with a validated response
+}
+
+// TestOpenAPI keeps openapi/openapi.json honest. It serves the real routes
+// against a scratch database, plays through every endpoint as a player and
+// as an admin, and validates each response against the specification.
+// Properties the specification does not declare count as mismatches, as do
+// routes missing from either side.
+func TestOpenAPI(t *testing.T) {
+	db, err := config.OpenDB(filepath.Join(t.TempDir(), "openapi.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	config.DB = db
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("invalid specification: %v", err)
+	}
+
+	ck := &checker{t: t, checked: map[string]bool{}}
+	gin.SetMode(gin.TestMode)
+	ck.router = gin.New()
+	ck.router.Use(middleware.RequestID())
+	ck.router.Use(middleware.OpenAPIResponseCheck(spec, true, func(c *gin.Context, err error) {
+		path := c.FullPath()
+		if current := middleware.CurrentPath(path); current != "" {
+			path = current
+		}
+		key := c.Request.Method + " " + path
+		if err != nil {
+			ck.fail("%s -> %d: %v", key, c.Writer.Status(), err)
+			return
+		}
+		ck.checked[key] = true
+	}))
+	ck.router.Use(middleware.OpenAPIMiddleware(spec))
+	SetupRoutes(ck.router)
+
+	ck.checkRoutes(spec)
+	ck.run()
+
+	missing := []string{}
+	for _, route := range spec.Routes() {
+		key := route.Method + " " + route.Path
+		if !ck.checked[key] && unchecked[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		ck.fail("%s: no response was checked", key)
+	}
+	t.Logf("%d operations checked against the specification", len(ck.checked))
+}
+
+// checkRoutes compares the router's routes with the specification's.
+// Deprecated aliases are served for the operation of their versioned path.
+func (ck *checker) checkRoutes(spec *openapi.Document) {
+	served := map[string]bool{}
+	for _, route := range ck.router.Routes() {
+		served[route.Method+" "+route.Path] = true
+		path := route.Path
+		if current := middleware.CurrentPath(path); current != "" {
+			path = current
+		}
+		if spec.Operation(route.Method, path) == nil {
+			ck.fail("%s %s is served but not in the specification", route.Method, route.Path)
+		}
+	}
+	for _, route := range spec.Routes() {
+		if !served[route.Method+" "+route.Path] {
+			ck.fail("%s %s is in the specification but not served", route.Method, route.Path)
+		}
+	}
+}
+
+func (ck *checker) fail(format string, args ...interface{}) {
+	ck.t.Helper()
+	ck.t.Errorf(format, args...)
+}
+
+// call sends a request and decodes the JSON response. A status other than
+// want is a failure, since the rest of the run depends on it.
+func (ck *checker) call(method, path, token string, body interface{}, want int) map[string]interface{} {
+	return ck.callWith(nil, method, path, token, body, want)
+}
+
+// callWith is call with extra request headers, such as X-Operator
+func (ck *checker) callWith(header map[string]string, method, path, token string, body interface{}, want int) map[string]interface{} {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	ck.router.ServeHTTP(rec, req)
+
+	ck.t.Logf("%s %s -> %d", method, path, rec.Code)
+	if rec.Code != want {
+		ck.fail("%s %s: status %d, want %d: %s", method, path, rec.Code, want, strings.TrimSpace(rec.Body.String()))
+	}
+
+	result := map[string]interface{}{}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	return result
+}
+
+func (ck *checker) run() {
+	// Player
+	ck.call("GET", "/openapi.json", "", nil, http.StatusOK)
+	ck.call("POST", "/api/v1/auth/register", "", map[string]string{"username": "player", "password": "secret"}, http.StatusOK)
+	ck.call("POST", "/api/v1/auth/register", "", map[string]string{"username": "player", "password": "secret"}, http.StatusBadRequest)
+	ck.call("POST", "/api/v1/auth/login", "", map[string]string{"username": "nobody", "password": "secret"}, http.StatusUnauthorized)
+	token := str(ck.call("POST", "/api/v1/auth/login", "", map[string]string{"username": "player", "password": "secret"}, http.StatusOK)["token"])
+	second := ck.call("POST", "/api/v1/auth/login", "", map[string]interface{}{"username": "player", "password": "secret", "device_label": "Tablet"}, http.StatusOK)
+
+	player := ck.findUser("player")
+	config.DB.Model(player).Update("balance", 1000000)
+
+	ck.call("GET", "/api/v1/user/me", "", nil, http.StatusUnauthorized)
+	ck.call("GET", "/api/v1/user/me", token, nil, http.StatusOK)
+	ck.checkAlias("/user/me", "/api/v1/user/me", token)
+	ck.checkAlias("/api/jackpots", "/api/v1/jackpots", "")
+	ck.call("GET", "/api/v1/user/logins", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/sessions", token, nil, http.StatusOK)
+	ck.call("DELETE", fmt.Sprintf("/api/v1/user/sessions/%v", num(second["session_id"])), token, nil, http.StatusOK)
+	ck.call("DELETE", "/api/v1/user/sessions/999999", token, nil, http.StatusNotFound)
+
+	// Fortune Gems
+	ck.expectCode(ck.call("POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 5}, http.StatusBadRequest), apierror.CodeBetOutOfRange)
+	var roundID float64
+	for i := 0; i < 5; i++ {
+		roundID = num(ck.call("POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusOK)["round_id"])
+	}
+	page := ck.call("GET", "/api/v1/user/history?limit=2&sort=-win", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/history?cursor="+str(page["next_cursor"])+"&limit=2&sort=-win", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/history?sort=outcome", token, nil, http.StatusBadRequest)
+	ck.call("GET", fmt.Sprintf("/api/v1/user/rounds/%v", roundID), token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/rounds/999999", token, nil, http.StatusNotFound)
+	ck.call("GET", fmt.Sprintf("/api/v1/rounds/fg-%v", roundID), token, nil, http.StatusOK)
+
+	// Mythic Lightning
+	var mythicRef string
+	for i := 0; i < 5; i++ {
+		mythicRef = str(ck.call("POST", "/api/v1/mythic/spin", token, map[string]interface{}{"bet": 1, "ante_bet": i%2 == 1}, http.StatusOK)["round_ref"])
+	}
+	ck.call("POST", "/api/v1/mythic/spin/stream", token, map[string]float64{"bet": 1}, http.StatusOK)
+	ck.call("GET", "/api/v1/mythic/feature-buy", token, nil, http.StatusOK)
+	featureRef := str(ck.call("POST", "/api/v1/mythic/feature-buy", token, map[string]float64{"bet": 1}, http.StatusOK)["round_ref"])
+	ck.call("GET", "/api/v1/mythic/history?mode=feature_buy", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/mythic/history?mode=bonus", token, nil, http.StatusBadRequest)
+	ck.call("GET", "/api/v1/rounds/"+mythicRef, token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/rounds/"+featureRef, token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/rounds/ml-999999", token, nil, http.StatusNotFound)
+	ck.call("GET", "/api/v1/jackpots", "", nil, http.StatusOK)
+	ck.call("GET", "/api/v1/jackpots/winners", "", nil, http.StatusOK)
+
+	// Two-factor enrolment and sign-in
+	ck.call("GET", "/api/v1/user/2fa", token, nil, http.StatusOK)
+	secret := str(ck.call("POST", "/api/v1/user/2fa/setup", token, nil, http.StatusOK)["secret"])
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	ck.call("POST", "/api/v1/user/2fa/confirm", token, map[string]string{"code": "000000x"}, http.StatusUnauthorized)
+	recovery := ck.call("POST", "/api/v1/user/2fa/confirm", token, map[string]string{"code": code}, http.StatusOK)["recovery_codes"]
+	codes, _ := recovery.([]interface{})
+	if len(codes) < 2 {
+		ck.fail("POST /user/2fa/confirm: expected recovery codes")
+		return
+	}
+	challenge := str(ck.call("POST", "/api/v1/auth/login", "", map[string]string{"username": "player", "password": "secret"}, http.StatusOK)["challenge_token"])
+	ck.call("POST", "/api/v1/auth/login/2fa", "", map[string]string{"challenge_token": challenge, "code": str(codes[0])}, http.StatusOK)
+	ck.call("POST", "/api/v1/auth/login/2fa", "", map[string]string{"challenge_token": challenge, "code": "nope"}, http.StatusUnauthorized)
+	ck.call("POST", "/api/v1/user/2fa/disable", token, map[string]string{"code": str(codes[1])}, http.StatusOK)
+
+	// Limits and play sessions
+	ck.call("GET", "/api/v1/user/limits", token, nil, http.StatusOK)
+	ck.call("PUT", "/api/v1/user/limits", token, map[string]interface{}{"type": "wager", "period": "daily", "amount": 500000}, http.StatusOK)
+	ck.call("PUT", "/api/v1/user/limits", token, map[string]interface{}{"type": "bets", "period": "daily", "amount": 1}, http.StatusBadRequest)
+	ck.call("PUT", "/api/v1/user/limits/session", token, map[string]int{"minutes": 120}, http.StatusOK)
+	ck.call("GET", "/api/v1/user/play-sessions", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/play-sessions/current", token, nil, http.StatusOK)
+	config.DB.Model(&models.PlaySession{}).Where("user_id = ?", player.ID).Update("reality_check_pending", true)
+	ck.call("POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusPreconditionRequired)
+	ck.call("POST", "/api/v1/user/play-sessions/reality-check/ack", token, map[string]string{"action": "continue"}, http.StatusOK)
+
+	// Autoplay
+	runID := idOf(ck.call("POST", "/api/v1/autoplay", token, map[string]interface{}{"game": "fortune_gems", "bet": 10, "spins": 3}, http.StatusAccepted)["run"])
+	var autoplay map[string]interface{}
+	for i := 0; i < 100; i++ {
+		autoplay = ck.call("GET", fmt.Sprintf("/api/v1/autoplay/%v", runID), token, nil, http.StatusOK)
+		if done, _ := autoplay["done"].(bool); done {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	// Every spin points at its round
+	results, _ := autoplay["results"].([]interface{})
+	if len(results) == 0 {
+		ck.fail("GET /api/v1/autoplay/%v: no spin results", runID)
+	}
+	for _, result := range results {
+		ref := str(result.(map[string]interface{})["round_ref"])
+		if !strings.HasPrefix(ref, "fg-") {
+			ck.fail("GET /api/v1/autoplay/%v: round_ref %q, want a Fortune Gems round", runID, ref)
+			continue
+		}
+		ck.call("GET", "/api/v1/rounds/"+ref, token, nil, http.StatusOK)
+	}
+	ck.call("GET", "/api/v1/autoplay", token, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/autoplay/%v/cancel", runID), token, nil, http.StatusConflict)
+
+	// Wallet
+	topUp := map[string]interface{}{"amount": 100000, "bank_name": "BCA", "bank_account": "1234567890", "account_name": "Player"}
+	depositID := idOf(ck.call("POST", "/api/v1/wallet/topup", token, topUp, http.StatusOK)["transaction"])
+	withdraw := map[string]interface{}{"amount": 1000, "bank_name": "BCA", "bank_account": "1234567890", "account_name": "Player"}
+	ck.call("POST", "/api/v1/wallet/withdraw", token, withdraw, http.StatusOK)
+	ck.expectCode(ck.call("POST", "/api/v1/wallet/topup", token, map[string]interface{}{"amount": -1}, http.StatusBadRequest), apierror.CodeValidationFailed)
+	ck.call("GET", "/api/v1/wallet/history?type=deposit", token, nil, http.StatusOK)
+
+	// Admin
+	defaultOperator, err := services.NewOperatorService(config.DB).Default()
+	if err != nil {
+		ck.t.Fatalf("default operator: %v", err)
+	}
+	admin := models.User{OperatorID: defaultOperator.ID, Username: "admin", Password: "secret", Balance: 0, Role: "admin", TOTPEnabled: true}
+	config.DB.Create(&admin)
+	adminToken, _, err := services.NewSessionService(config.DB).Start(admin.ID, true, services.DeviceInfo{Label: "openapi test"})
+	if err != nil {
+		ck.fail("admin session: %v", err)
+		return
+	}
+	ck.call("GET", "/api/v1/admin/dashboard", token, nil, http.StatusForbidden)
+	ck.call("GET", "/api/v1/admin/dashboard", adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/admin/transactions?status=pending", adminToken, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/admin/transactions/%v/process", depositID), adminToken, map[string]string{"action": "approve"}, http.StatusOK)
+	ck.expectCode(ck.call("POST", fmt.Sprintf("/api/v1/admin/transactions/%v/process", depositID), adminToken, map[string]string{"action": "approve"}, http.StatusBadRequest),
+		apierror.CodeTxAlreadyProcessed)
+	ck.call("GET", "/api/v1/admin/login-attempts?success=false", adminToken, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/admin/users/%d/unlock", player.ID), adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/admin/play-sessions", adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/admin/rtp/mythic", adminToken, nil, http.StatusOK)
+
+	now := time.Now()
+	tournament := ck.call("POST", "/api/v1/admin/tournaments", adminToken, map[string]interface{}{
+		"name": "Weekend", "starts_at": now.Add(-time.Minute), "ends_at": now.Add(time.Hour),
+		"scoring_rule": "total_wagered", "prizes": []int{500, 200},
+	}, http.StatusCreated)
+	tournamentID := idOf(tournament["tournament"])
+	ck.call("GET", "/api/v1/admin/tournaments", adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/tournaments", token, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/tournaments/%v/join", tournamentID), token, nil, http.StatusOK)
+	ck.call("POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusOK)
+	ck.call("GET", fmt.Sprintf("/api/v1/tournaments/%v/leaderboard?limit=10", tournamentID), token, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/admin/tournaments/%v/close", tournamentID), adminToken, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/tournaments/%v/join", tournamentID), token, nil, http.StatusConflict)
+
+	ck.call("GET", "/api/v1/missions", token, nil, http.StatusOK)
+	ck.call("POST", "/api/v1/missions/999999/claim", token, nil, http.StatusNotFound)
+	var spinsMission models.Mission
+	if config.DB.Where("metric = ? AND period = ?", models.MetricSpins, models.MissionDaily).First(&spinsMission).Error == nil {
+		config.DB.Model(&models.MissionProgress{}).Where("user_id = ? AND mission_id = ?", player.ID, spinsMission.ID).
+			Updates(map[string]interface{}{"progress": spinsMission.Target, "completed_at": now})
+		ck.call("POST", fmt.Sprintf("/api/v1/missions/%d/claim", spinsMission.ID), token, nil, http.StatusOK)
+	}
+
+	promo := ck.call("POST", "/api/v1/admin/promo-codes", adminToken, map[string]interface{}{
+		"code": "welcome", "reward_type": "free_spins", "free_spins": 5, "free_spin_bet": 1, "free_spin_days": 7,
+	}, http.StatusCreated)
+	ck.call("GET", "/api/v1/admin/promo-codes", adminToken, nil, http.StatusOK)
+	ck.call("POST", "/api/v1/promo/redeem", token, map[string]string{"code": "WELCOME"}, http.StatusOK)
+	ck.expectCode(ck.call("POST", "/api/v1/promo/redeem", token, map[string]string{"code": "WELCOME"}, http.StatusConflict), apierror.CodePromoAlreadyRedeemed)
+	ck.call("POST", fmt.Sprintf("/api/v1/admin/promo-codes/%v/deactivate", idOf(promo["promo_code"])), adminToken, nil, http.StatusOK)
+
+	pack := ck.call("POST", fmt.Sprintf("/api/v1/admin/users/%d/free-spins", player.ID), adminToken, map[string]interface{}{
+		"spins": 3, "bet": 1, "reason": "openapi test",
+	}, http.StatusCreated)
+	ck.call("GET", "/api/v1/admin/free-spins?user_id="+fmt.Sprint(player.ID), adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/mythic/free-spins", token, nil, http.StatusOK)
+	ck.call("POST", fmt.Sprintf("/api/v1/mythic/free-spins/%v/spin", idOf(pack["free_spin_pack"])), token, nil, http.StatusOK)
+
+	ck.checkOperators(player, adminToken)
+	ck.checkSeamlessWallet(adminToken)
+
+	// Protection measures last, they stop the player from playing
+	ck.call("POST", "/api/v1/user/limits/cool-off", token, map[string]int{"hours": 5}, http.StatusBadRequest)
+	ck.call("POST", "/api/v1/user/limits/cool-off", token, map[string]int{"hours": 24}, http.StatusOK)
+	ck.call("POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusForbidden)
+	ck.call("POST", "/api/v1/user/limits/self-exclusion", token, map[string]int{"months": 6}, http.StatusOK)
+	ck.call("DELETE", fmt.Sprintf("/api/v1/admin/users/%d/sessions", player.ID), adminToken, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/me", token, nil, http.StatusUnauthorized)
+}
+
+// checkOperators sets up a second operator and checks that its players and
+// admins are kept apart from the default operator's
+func (ck *checker) checkOperators(defaultPlayer *models.User, platformToken string) {
+	ck.call("GET", "/api/v1/operator", "", nil, http.StatusOK)
+	acme := map[string]interface{}{
+		"code": "acme", "name": "Acme Casino", "currency": "EUR", "games": []string{"mythic_lightning"},
+		"mythic_min_bet": 2, "mythic_max_bet": 50, "branding": map[string]string{"primary_color": "#c00"},
+	}
+	operatorID := idOf(ck.call("POST", "/api/v1/admin/operators", platformToken, acme, http.StatusCreated)["operator"])
+	ck.expectCode(ck.call("POST", "/api/v1/admin/operators", platformToken, acme, http.StatusBadRequest), apierror.CodeInvalidOperator)
+	acme["host"] = "acme.example.com"
+	ck.call("PUT", fmt.Sprintf("/api/v1/admin/operators/%v", operatorID), platformToken, acme, http.StatusOK)
+	ck.expectCode(ck.call("PUT", "/api/v1/admin/operators/999999", platformToken, acme, http.StatusNotFound), apierror.CodeOperatorNotFound)
+	ck.call("GET", "/api/v1/admin/operators", platformToken, nil, http.StatusOK)
+
+	// API keys
+	keysPath := fmt.Sprintf("/api/v1/admin/operators/%v/api-keys", operatorID)
+	created := ck.call("POST", keysPath, platformToken, map[string]string{"name": "backend"}, http.StatusCreated)
+	ck.call("GET", keysPath, platformToken, nil, http.StatusOK)
+	byKey := map[string]string{"X-API-Key": str(created["key"])}
+	if config := ck.callWith(byKey, "GET", "/api/v1/operator", "", nil, http.StatusOK)["operator"]; str(config.(map[string]interface{})["code"]) != "acme" {
+		ck.fail("GET /api/v1/operator: API key did not resolve to its operator")
+	}
+	ck.call("DELETE", fmt.Sprintf("%s/%v", keysPath, idOf(created["api_key"])), platformToken, nil, http.StatusOK)
+	ck.expectCode(ck.call("DELETE", keysPath+"/999999", platformToken, nil, http.StatusNotFound), apierror.CodeAPIKeyNotFound)
+	ck.expectCode(ck.callWith(byKey, "GET", "/api/v1/operator", "", nil, http.StatusUnauthorized), apierror.CodeInvalidAPIKey)
+	ck.expectCode(ck.callWith(map[string]string{"X-Operator": "nobody"}, "GET", "/api/v1/operator", "", nil, http.StatusNotFound), apierror.CodeOperatorNotFound)
+
+	// Usernames are per operator, and so are tokens
+	asAcme := map[string]string{"X-Operator": "acme"}
+	credentials := map[string]string{"username": "player", "password": "secret"}
+	ck.callWith(asAcme, "POST", "/api/v1/auth/register", "", credentials, http.StatusOK)
+	token := str(ck.callWith(asAcme, "POST", "/api/v1/auth/login", "", credentials, http.StatusOK)["token"])
+	ck.callWith(asAcme, "GET", "/api/v1/user/me", token, nil, http.StatusOK)
+	ck.call("GET", "/api/v1/user/me", token, nil, http.StatusUnauthorized)
+
+	// Game availability and bet limits
+	ck.expectCode(ck.callWith(asAcme, "POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusForbidden), apierror.CodeGameUnavailable)
+	ck.expectCode(ck.callWith(asAcme, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 1}, http.StatusBadRequest), apierror.CodeBetOutOfRange)
+	ck.callWith(asAcme, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 2}, http.StatusOK)
+
+	// The bonus buy is offered per operator, at its own price
+	ck.expectCode(ck.callWith(asAcme, "POST", "/api/v1/mythic/feature-buy", token, map[string]float64{"bet": 2}, http.StatusForbidden), apierror.CodeFeatureBuyDisabled)
+	acme["feature_buy"], acme["feature_buy_cost"] = true, 50
+	ck.call("PUT", fmt.Sprintf("/api/v1/admin/operators/%v", operatorID), platformToken, acme, http.StatusOK)
+	if offer := ck.callWith(asAcme, "GET", "/api/v1/mythic/feature-buy", token, nil, http.StatusOK); num(offer["cost_multiplier"]) != 50 {
+		ck.fail("GET /api/v1/mythic/feature-buy: cost_multiplier %v, want the operator's 50", offer["cost_multiplier"])
+	}
+	if round := ck.callWith(asAcme, "POST", "/api/v1/mythic/feature-buy", token, map[string]float64{"bet": 2}, http.StatusOK); num(round["stake"]) != 100 {
+		ck.fail("POST /api/v1/mythic/feature-buy: stake %v, want 100", round["stake"])
+	}
+
+	topUp := map[string]interface{}{"amount": 5000, "bank_name": "BCA", "bank_account": "1234567890", "account_name": "Acme Player"}
+	ck.callWith(asAcme, "POST", "/api/v1/wallet/topup", token, topUp, http.StatusOK)
+
+	// The operator's admins only see its players
+	admin := models.User{OperatorID: uint(operatorID), Username: "admin", Password: "secret", Role: "admin", TOTPEnabled: true}
+	config.DB.Create(&admin)
+	adminToken, _, err := services.NewSessionService(config.DB).Start(admin.ID, true, services.DeviceInfo{Label: "openapi test"})
+	if err != nil {
+		ck.fail("operator admin session: %v", err)
+		return
+	}
+	transactions, _ := ck.callWith(asAcme, "GET", "/api/v1/admin/transactions", adminToken, nil, http.StatusOK)["transactions"].([]interface{})
+	if len(transactions) != 1 {
+		ck.fail("GET /api/v1/admin/transactions: operator admin sees %d transactions, want 1", len(transactions))
+	}
+	ck.expectCode(ck.callWith(asAcme, "POST", fmt.Sprintf("/api/v1/admin/users/%d/unlock", defaultPlayer.ID), adminToken, nil, http.StatusNotFound), apierror.CodeUserNotFound)
+	ck.expectCode(ck.callWith(asAcme, "GET", "/api/v1/admin/operators", adminToken, nil, http.StatusForbidden), apierror.CodePlatformAdminRequired)
+	ck.expectCode(ck.callWith(asAcme, "GET", "/api/v1/admin/promo-codes", adminToken, nil, http.StatusForbidden), apierror.CodePlatformAdminRequired)
+}
+
+// checkSeamlessWallet plays rounds of an operator whose balances are held by
+// a stub wallet, including calls that are retried and a round rolled back
+func (ck *checker) checkSeamlessWallet(platformToken string) {
+	stub := walletstub.New("stub-secret", 0)
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	operator := map[string]interface{}{"code": "seamless", "name": "Seamless Casino", "wallet_url": server.URL}
+	ck.expectCode(ck.call("POST", "/api/v1/admin/operators", platformToken, operator, http.StatusBadRequest), apierror.CodeInvalidOperator)
+	operator["wallet_secret"] = "stub-secret"
+	ck.call("POST", "/api/v1/admin/operators", platformToken, operator, http.StatusCreated)
+
+	asSeamless := map[string]string{"X-Operator": "seamless"}
+	credentials := map[string]string{"username": "wallet-player", "password": "secret"}
+	ck.callWith(asSeamless, "POST", "/api/v1/auth/register", "", credentials, http.StatusOK)
+	token := str(ck.callWith(asSeamless, "POST", "/api/v1/auth/login", "", credentials, http.StatusOK)["token"])
+
+	// The operator's balance decides, not the one held here
+	ck.expectCode(ck.callWith(asSeamless, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 1}, http.StatusBadRequest), apierror.CodeInsufficientBalance)
+	stub.SetBalance("wallet-player", 500)
+	round := ck.callWith(asSeamless, "POST", "/api/v1/user/play-slot", token, map[string]int{"bet": 10}, http.StatusOK)
+	if balance := num(round["current_balance"]); balance != stub.Balance("wallet-player") {
+		ck.fail("POST /api/v1/user/play-slot: balance %v, wallet holds %v", balance, stub.Balance("wallet-player"))
+	}
+
+	stub.FailNext(1)
+	ck.callWith(asSeamless, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 1}, http.StatusOK)
+
+	// A debit that never gets an answer fails the spin and is rolled back
+	before := stub.Balance("wallet-player")
+	stub.FailNext(3)
+	ck.expectCode(ck.callWith(asSeamless, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 1}, http.StatusServiceUnavailable), apierror.CodeWalletUnavailable)
+	if after := stub.Balance("wallet-player"); after != before {
+		ck.fail("POST /api/v1/mythic/spin: failed round left the wallet at %v, want %v", after, before)
+	}
+
+	// A recorded round stands and its unanswered credit is resent later
+	stub.FailCredits(true)
+	round = ck.callWith(asSeamless, "POST", "/api/v1/mythic/spin", token, map[string]float64{"bet": 1}, http.StatusOK)
+	stub.FailCredits(false)
+	if after := stub.Balance("wallet-player"); after != before-1 {
+		ck.fail("POST /api/v1/mythic/spin: wallet at %v before the credit, want %v", after, before-1)
+	}
+	delivered, err := services.NewWalletReconciler(config.DB).Reconcile(time.Now().Add(time.Hour))
+	if err != nil || delivered != 1 {
+		ck.fail("WalletReconciler.Reconcile: delivered %d (%v), want the queued credit", delivered, err)
+	}
+	if balance := num(round["current_balance"]); balance != stub.Balance("wallet-player") {
+		ck.fail("POST /api/v1/mythic/spin: balance %v, wallet holds %v once credited", balance, stub.Balance("wallet-player"))
+	}
+
+	topUp := map[string]interface{}{"amount": 100, "bank_name": "BCA", "bank_account": "1234567890", "account_name": "Wallet Player"}
+	ck.expectCode(ck.callWith(asSeamless, "POST", "/api/v1/wallet/topup", token, topUp, http.StatusConflict), apierror.CodeSeamlessWallet)
+
+	rollbacks, _ := ck.call("GET", "/api/v1/admin/wallet-transactions?type=rollback", platformToken, nil, http.StatusOK)["wallet_transactions"].([]interface{})
+	// Only the unanswered debit is voided
+	if len(rollbacks) != 1 {
+		ck.fail("GET /api/v1/admin/wallet-transactions: %d rollbacks, want 1", len(rollbacks))
+	}
+}
+
+// expectCode checks the machine readable code of an error response
+func (ck *checker) expectCode(resp map[string]interface{}, code string) {
+	if got := str(resp["code"]); got != code {
+		ck.fail("error code %q, want %q", got, code)
+	}
+}
+
+// checkAlias requests a deprecated alias and checks it answers like its
+// versioned path and points there
+func (ck *checker) checkAlias(legacy, current, token string) {
+	req := httptest.NewRequest(http.MethodGet, legacy, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ck.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		ck.fail("GET %s: status %d, want %d", legacy, rec.Code, http.StatusOK)
+	}
+	if rec.Header().Get("Deprecation") != "true" {
+		ck.fail("GET %s: no Deprecation header", legacy)
+	}
+	if link, want := rec.Header().Get("Link"), "<"+current+`>; rel="successor-version"`; link != want {
+		ck.fail("GET %s: Link %q, want %q", legacy, link, want)
+	}
+}
+
+func (ck *checker) findUser(username string) *models.User {
+	var user models.User
+	if err := config.DB.Where("operator_id = (SELECT id FROM operators WHERE code = ?) AND username = ?", models.DefaultOperatorCode, username).First(&user).Error; err != nil {
+		ck.t.Fatalf("user %q: %v", username, err)
+	}
+	return &user
+}
+
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func num(v interface{}) float64 {
+	n, _ := v.(float64)
+	return n
+}
+
+// idOf returns the id of a decoded object
+func idOf(v interface{}) float64 {
+	object, _ := v.(map[string]interface{})
+	return num(object["id"])
+}
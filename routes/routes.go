@@ -1,54 +1,235 @@
 package routes
 
 import (
+	"net/http"
+	"slot-sim/apierror"
 	"slot-sim/config"
 	"slot-sim/controllers"
 	"slot-sim/handlers"
 	"slot-sim/middleware"
+	"slot-sim/openapi"
+	"slot-sim/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// SetupRoutes registers the API under /api/v1. Every route also answers on
+// the path it had before the API was versioned, as a deprecated alias; see
+// middleware.LegacyPath.
 func SetupRoutes(r *gin.Engine) {
-	r.POST("/register", controllers.Register)
-	r.POST("/login", controllers.Login)
+	// API specification, see openapi/openapi.json
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", openapi.JSON())
+	})
+
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRouteNotFound, "Route not found")
+	})
+
+	authRoutes := newGroup(r, "/auth")
+	{
+		authRoutes.POST("/register", controllers.Register)
+		authRoutes.POST("/login", controllers.Login)
+		authRoutes.POST("/login/2fa", controllers.LoginTwoFactor)
+	}
 
 	// Fortune Gems routes (existing)
-	userRoutes := r.Group("/user")
-	userRoutes.Use(middleware.AuthMiddleware())
+	userRoutes := newGroup(r, "/user", middleware.AuthMiddleware())
 	{
 		userRoutes.GET("/me", controllers.GetProfile)
 		userRoutes.GET("/history", controllers.GetHistory)
+		userRoutes.GET("/rounds/:id", controllers.GetRound)
+		userRoutes.GET("/logins", controllers.GetLoginHistory)
 		userRoutes.POST("/play-slot", controllers.PlaySlot)
 	}
 
+	// Two-factor authentication routes
+	twoFactorHandler := handlers.NewTwoFactorHandler(config.DB)
+	twoFactorRoutes := newGroup(r, "/user/2fa", middleware.AuthMiddleware())
+	{
+		twoFactorRoutes.GET("", twoFactorHandler.Status)
+		twoFactorRoutes.POST("/setup", twoFactorHandler.Setup)
+		twoFactorRoutes.POST("/confirm", twoFactorHandler.Confirm)
+		twoFactorRoutes.POST("/disable", twoFactorHandler.Disable)
+	}
+
+	// Device session routes
+	sessionHandler := handlers.NewSessionHandler(config.DB)
+	sessionRoutes := newGroup(r, "/user/sessions", middleware.AuthMiddleware())
+	{
+		sessionRoutes.GET("", sessionHandler.List)
+		sessionRoutes.DELETE("/:id", sessionHandler.Revoke)
+	}
+
+	// Responsible gaming routes
+	rgHandler := handlers.NewResponsibleGamingHandler(config.DB)
+	rgRoutes := newGroup(r, "/user/limits", middleware.AuthMiddleware())
+	{
+		rgRoutes.GET("", rgHandler.GetLimits)
+		rgRoutes.PUT("", rgHandler.SetLimit)
+		rgRoutes.PUT("/session", rgHandler.SetSessionLimit)
+		rgRoutes.POST("/cool-off", rgHandler.CoolOff)
+		rgRoutes.POST("/self-exclusion", rgHandler.SelfExclude)
+	}
+
+	// Play session and reality check routes
+	playSessionHandler := handlers.NewPlaySessionHandler(config.DB)
+	playSessionRoutes := newGroup(r, "/user/play-sessions", middleware.AuthMiddleware())
+	{
+		playSessionRoutes.GET("", playSessionHandler.List)
+		playSessionRoutes.GET("/current", playSessionHandler.Current)
+		playSessionRoutes.POST("/reality-check/ack", playSessionHandler.AcknowledgeRealityCheck)
+	}
+
+	// Round replay, both games
+	roundHandler := handlers.NewRoundHandler(config.DB)
+	roundRoutes := newGroup(r, "/rounds", middleware.AuthMiddleware())
+	{
+		roundRoutes.GET("/:id", roundHandler.Get)
+	}
+
+	// Live event channel (WebSocket)
+	eventsHandler := handlers.NewEventsHandler(services.Events)
+	newGroup(r, "/events", middleware.AuthMiddleware()).GET("", eventsHandler.Stream)
+
 	// Mythic Lightning routes (new)
 	mythicHandler := handlers.NewMythicHandler(config.DB)
-	mythicRoutes := r.Group("/api/mythic")
-	mythicRoutes.Use(middleware.AuthMiddleware())
+	mythicRoutes := newGroup(r, "/mythic", middleware.AuthMiddleware())
 	{
 		mythicRoutes.POST("/spin", mythicHandler.Spin)
+		mythicRoutes.POST("/spin/stream", mythicHandler.SpinStream)
 		mythicRoutes.GET("/history", mythicHandler.GetHistory)
+		mythicRoutes.GET("/feature-buy", mythicHandler.GetFeatureBuy)
+		mythicRoutes.POST("/feature-buy", mythicHandler.BuyFeature)
+		mythicRoutes.GET("/free-spins", mythicHandler.GetFreeSpins)
+		mythicRoutes.POST("/free-spins/:id/spin", mythicHandler.PlayFreeSpin)
+	}
+
+	// Operator configuration (public)
+	newGroup(r, "/operator").GET("", handlers.CurrentOperator)
+
+	// Jackpot routes (public)
+	jackpotHandler := handlers.NewJackpotHandler(config.DB)
+	jackpotRoutes := newGroup(r, "/jackpots")
+	{
+		jackpotRoutes.GET("", jackpotHandler.List)
+		jackpotRoutes.GET("/winners", jackpotHandler.Winners)
+	}
+
+	// Tournament routes
+	tournamentHandler := handlers.NewTournamentHandler(config.DB)
+	tournamentRoutes := newGroup(r, "/tournaments", middleware.AuthMiddleware())
+	{
+		tournamentRoutes.GET("", tournamentHandler.List)
+		tournamentRoutes.POST("/:id/join", tournamentHandler.Join)
+		tournamentRoutes.GET("/:id/leaderboard", tournamentHandler.Leaderboard)
+	}
+
+	// Mission and achievement routes
+	missionHandler := handlers.NewMissionHandler(config.DB)
+	missionRoutes := newGroup(r, "/missions", middleware.AuthMiddleware())
+	{
+		missionRoutes.GET("", missionHandler.List)
+		missionRoutes.POST("/:id/claim", missionHandler.Claim)
+	}
+
+	// Promo code routes
+	promoHandler := handlers.NewPromoHandler(config.DB)
+	promoRoutes := newGroup(r, "/promo", middleware.AuthMiddleware())
+	{
+		promoRoutes.POST("/redeem", promoHandler.Redeem)
+	}
+
+	// Autoplay routes (both games)
+	autoplayHandler := handlers.NewAutoplayHandler(config.DB)
+	autoplayRoutes := newGroup(r, "/autoplay", middleware.AuthMiddleware())
+	{
+		autoplayRoutes.POST("", autoplayHandler.Start)
+		autoplayRoutes.GET("", autoplayHandler.List)
+		autoplayRoutes.GET("/:id", autoplayHandler.Get)
+		autoplayRoutes.POST("/:id/cancel", autoplayHandler.Cancel)
 	}
 
 	// Wallet routes
 	walletHandler := handlers.NewWalletHandler(config.DB)
-	walletRoutes := r.Group("/api/wallet")
-	walletRoutes.Use(middleware.AuthMiddleware())
+	walletRoutes := newGroup(r, "/wallet", middleware.AuthMiddleware())
 	{
 		walletRoutes.POST("/topup", walletHandler.RequestTopUp)
 		walletRoutes.POST("/withdraw", walletHandler.RequestWithdraw)
 		walletRoutes.GET("/history", walletHandler.GetHistory)
 	}
 
-	// Admin routes
+	// Admin routes. Tournaments and promo codes are open to the players of
+	// every operator, so only platform admins manage them.
 	adminController := controllers.NewAdminController(config.DB)
-	adminRoutes := r.Group("/api/admin")
-	adminRoutes.Use(middleware.AuthMiddleware())
-	adminRoutes.Use(middleware.AdminMiddleware())
+	platformAdmin := middleware.PlatformAdminMiddleware()
+	adminRoutes := newGroup(r, "/admin", middleware.AuthMiddleware(), middleware.AdminMiddleware())
 	{
 		adminRoutes.GET("/transactions", adminController.GetAllTransactions)
 		adminRoutes.POST("/transactions/:id/process", adminController.ProcessTransaction)
 		adminRoutes.GET("/dashboard", adminController.GetDashboardStats)
+		adminRoutes.GET("/login-attempts", adminController.GetLoginAttempts)
+		adminRoutes.POST("/users/:id/unlock", adminController.UnlockUser)
+		adminRoutes.DELETE("/users/:id/sessions", adminController.TerminateUserSessions)
+		adminRoutes.GET("/play-sessions", adminController.GetPlaySessions)
+		adminRoutes.GET("/rtp/mythic", adminController.GetMythicRTP)
+		adminRoutes.GET("/tournaments", platformAdmin, adminController.GetTournaments)
+		adminRoutes.POST("/tournaments", platformAdmin, adminController.CreateTournament)
+		adminRoutes.POST("/tournaments/:id/close", platformAdmin, adminController.CloseTournament)
+		adminRoutes.GET("/promo-codes", platformAdmin, adminController.GetPromoCodes)
+		adminRoutes.POST("/promo-codes", platformAdmin, adminController.CreatePromoCode)
+		adminRoutes.POST("/promo-codes/:id/deactivate", platformAdmin, adminController.DeactivatePromoCode)
+		adminRoutes.POST("/users/:id/free-spins", adminController.GrantFreeSpins)
+		adminRoutes.GET("/free-spins", adminController.GetFreeSpinPacks)
+		adminRoutes.GET("/wallet-transactions", adminController.GetWalletTransactions)
 	}
+
+	// Operator management, for admins of the default operator only
+	operatorRoutes := newGroup(r, "/admin/operators", middleware.AuthMiddleware(), middleware.AdminMiddleware(), platformAdmin)
+	{
+		operatorRoutes.GET("", adminController.GetOperators)
+		operatorRoutes.POST("", adminController.CreateOperator)
+		operatorRoutes.PUT("/:id", adminController.UpdateOperator)
+		operatorRoutes.GET("/:id/api-keys", adminController.GetOperatorAPIKeys)
+		operatorRoutes.POST("/:id/api-keys", adminController.CreateOperatorAPIKey)
+		operatorRoutes.DELETE("/:id/api-keys/:keyId", adminController.RevokeOperatorAPIKey)
+	}
+}
+
+// routeGroup registers each route under middleware.APIPrefix and again at its
+// legacy path. Every route first resolves the operator the request is for.
+type routeGroup struct {
+	engine   *gin.Engine
+	path     string
+	handlers []gin.HandlerFunc
+}
+
+func newGroup(r *gin.Engine, path string, handlers ...gin.HandlerFunc) *routeGroup {
+	handlers = append([]gin.HandlerFunc{middleware.OperatorMiddleware()}, handlers...)
+	return &routeGroup{engine: r, path: path, handlers: handlers}
+}
+
+func (g *routeGroup) handle(method, relativePath string, handlers ...gin.HandlerFunc) {
+	path := middleware.APIPrefix + g.path + relativePath
+	chain := append(append([]gin.HandlerFunc{}, g.handlers...), handlers...)
+	g.engine.Handle(method, path, chain...)
+	if legacy := middleware.LegacyPath(path); legacy != "" {
+		g.engine.Handle(method, legacy, append([]gin.HandlerFunc{middleware.Deprecated()}, chain...)...)
+	}
+}
+
+func (g *routeGroup) GET(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, path, handlers...)
+}
+
+func (g *routeGroup) POST(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, path, handlers...)
+}
+
+func (g *routeGroup) PUT(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, path, handlers...)
+}
+
+func (g *routeGroup) DELETE(path string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, path, handlers...)
 }
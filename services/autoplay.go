@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MaxAutoplaySpins     = 1000
+	autoplaySpinInterval = 200 * time.Millisecond // pause between spins of a run
+)
+
+// Stop reasons recorded on an AutoplayRun
+const (
+	StopReasonFeature     = "feature_triggered"
+	StopReasonWinAbove    = "single_win_above"
+	StopReasonBalance     = "balance_below"
+	StopReasonNetLoss     = "net_loss_above"
+	StopReasonBalanceLow  = "insufficient_balance"
+	StopReasonRealityChk  = "reality_check"
+	StopReasonSpinRefused = "spin_refused"
+)
+
+// Fortune Gems bet range, matching PlayInput
+const (
+	fortuneMinBet = 10
+	fortuneMaxBet = 1000
+)
+
+var (
+	ErrAutoplayActive     = errors.New("an autoplay run is already active")
+	ErrAutoplayNotFound   = errors.New("autoplay run not found")
+	ErrAutoplayNotRunning = errors.New("autoplay run is not running")
+	ErrInvalidAutoplay    = errors.New("invalid autoplay settings")
+)
+
+// AutoplayOptions are the settings of a new run. Zero thresholds are disabled.
+type AutoplayOptions struct {
+	Game             string
+	Bet              float64
+	Spins            int
+	StopOnFeature    bool
+	StopOnWinAbove   float64
+	StopBalanceBelow float64
+	StopNetLossAbove float64
+}
+
+// AutoplaySpinResult is the compact per-spin record kept with a run
+type AutoplaySpinResult struct {
+	Spin         int     `json:"spin"`
+	RoundID      uint    `json:"round_id,omitempty"`  // in the table of the run's game
+	RoundRef     string  `json:"round_ref,omitempty"` // for GET /api/v1/rounds/{ref}
+	Win          float64 `json:"win"`                 // including any jackpot
+	Jackpot      string  `json:"jackpot,omitempty"`
+	Balance      float64 `json:"balance"`
+	Feature      bool    `json:"feature"`
+	RealityCheck bool    `json:"reality_check,omitempty"`
+	PlayedAt     string  `json:"played_at"`
+}
+
+// AutoplayManager runs autoplay series in the background. There is one
+// manager per process; it keeps the cancel function of every active run.
+type AutoplayManager struct {
+	db      *gorm.DB
+	games   *GameService
+	mu      sync.Mutex
+	cancels map[uint]context.CancelFunc
+}
+
+func NewAutoplayManager(db *gorm.DB, games *GameService) *AutoplayManager {
+	// Runs left "running" by a previous process can no longer progress
+	db.Model(&models.AutoplayRun{}).
+		Where("status = ?", models.AutoplayRunning).
+		Updates(map[string]interface{}{"status": models.AutoplayInterrupted, "finished_at": time.Now()})
+
+	return &AutoplayManager{
+		db:      db,
+		games:   games,
+		cancels: make(map[uint]context.CancelFunc),
+	}
+}
+
+// Start validates the options and starts a new run for the user
+func (m *AutoplayManager) Start(userID uint, opts AutoplayOptions) (*models.AutoplayRun, error) {
+	if err := validateAutoplay(opts); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var active int64
+	if err := m.db.Model(&models.AutoplayRun{}).
+		Where("user_id = ? AND status = ?", userID, models.AutoplayRunning).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, ErrAutoplayActive
+	}
+
+	run := &models.AutoplayRun{
+		UserID:           userID,
+		Game:             opts.Game,
+		Bet:              opts.Bet,
+		TotalSpins:       opts.Spins,
+		StopOnFeature:    opts.StopOnFeature,
+		StopOnWinAbove:   opts.StopOnWinAbove,
+		StopBalanceBelow: opts.StopBalanceBelow,
+		StopNetLossAbove: opts.StopNetLossAbove,
+		Status:           models.AutoplayRunning,
+		Results:          "[]",
+	}
+	if err := m.db.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancels[run.ID] = cancel
+	go m.run(ctx, *run)
+
+	return run, nil
+}
+
+// Cancel stops a running run of the user after the spin in progress
+func (m *AutoplayManager) Cancel(userID, runID uint) error {
+	run, err := m.Get(userID, runID)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	cancel, ok := m.cancels[run.ID]
+	m.mu.Unlock()
+	if !ok || run.Status != models.AutoplayRunning {
+		return ErrAutoplayNotRunning
+	}
+
+	cancel()
+	return nil
+}
+
+// Get returns one run of the user
+func (m *AutoplayManager) Get(userID, runID uint) (*models.AutoplayRun, error) {
+	var run models.AutoplayRun
+	if err := m.db.Where("id = ? AND user_id = ?", runID, userID).First(&run).Error; err != nil {
+		return nil, ErrAutoplayNotFound
+	}
+	return &run, nil
+}
+
+// List returns the user's most recent runs
+func (m *AutoplayManager) List(userID uint) ([]models.AutoplayRun, error) {
+	var runs []models.AutoplayRun
+	err := m.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(20).Find(&runs).Error
+	return runs, err
+}
+
+// Results decodes the per-spin results of a run
+func (m *AutoplayManager) Results(run *models.AutoplayRun) []AutoplaySpinResult {
+	results := []AutoplaySpinResult{}
+	json.Unmarshal([]byte(run.Results), &results)
+	return results
+}
+
+// run plays the spins one after another through the normal settlement path
+func (m *AutoplayManager) run(ctx context.Context, run models.AutoplayRun) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancels, run.ID)
+		m.mu.Unlock()
+	}()
+
+	results := []AutoplaySpinResult{}
+	status := models.AutoplayCompleted
+	stopReason := ""
+
+loop:
+	for run.SpinsPlayed < run.TotalSpins {
+		if ctx.Err() != nil {
+			status = models.AutoplayCancelled
+			break
+		}
+
+		result, err := m.spin(run)
+		if err != nil {
+			status, stopReason = models.AutoplayStopped, refusalReason(err)
+			break
+		}
+
+		run.SpinsPlayed++
+		result.Spin = run.SpinsPlayed
+		run.TotalWagered += run.Bet
+		run.TotalWon += result.Win
+		run.NetResult = run.TotalWon - run.TotalWagered
+		run.LastBalance = result.Balance
+		if result.Win > run.BiggestWin {
+			run.BiggestWin = result.Win
+		}
+		results = append(results, result)
+		m.saveProgress(&run, results)
+
+		if reason := stopConditionMet(&run, result); reason != "" {
+			status, stopReason = models.AutoplayStopped, reason
+			break
+		}
+
+		if run.SpinsPlayed < run.TotalSpins {
+			select {
+			case <-ctx.Done():
+				status = models.AutoplayCancelled
+				break loop
+			case <-time.After(autoplaySpinInterval):
+			}
+		}
+	}
+
+	now := time.Now()
+	run.Status = status
+	run.StopReason = stopReason
+	run.FinishedAt = &now
+	m.saveProgress(&run, results)
+}
+
+// spin plays one spin of the run's game
+func (m *AutoplayManager) spin(run models.AutoplayRun) (AutoplaySpinResult, error) {
+	result := AutoplaySpinResult{PlayedAt: time.Now().Format(time.RFC3339)}
+
+	switch run.Game {
+	case GameFortuneGems:
+		round, err := m.games.PlayFortuneGems(run.UserID, int(run.Bet))
+		if err != nil {
+			return result, err
+		}
+		result.RoundID = round.RoundID
+		result.RoundRef = FortuneRoundRef(round.RoundID)
+		result.Win = float64(round.FinalWin)
+		if round.Jackpot != nil {
+			result.Win += round.Jackpot.Amount
+			result.Jackpot = round.Jackpot.Tier
+		}
+		result.Balance = float64(round.CurrentBalance)
+		result.Feature = round.IsFortuneSpin
+		result.RealityCheck = round.RealityCheck != nil
+	case GameMythic:
+		round, err := m.games.SpinMythic(run.UserID, run.Bet, false)
+		if err != nil {
+			return result, err
+		}
+		result.RoundID = round.SessionID
+		result.RoundRef = MythicRoundRef(round.SessionID)
+		result.Win = round.TotalWin
+		if round.Jackpot != nil {
+			result.Win += round.Jackpot.Amount
+			result.Jackpot = round.Jackpot.Tier
+		}
+		result.Balance = round.CurrentBalance
+		result.Feature = round.FreeSpinsAwarded > 0
+		result.RealityCheck = round.RealityCheck != nil
+	default:
+		return result, ErrInvalidAutoplay
+	}
+	return result, nil
+}
+
+func (m *AutoplayManager) saveProgress(run *models.AutoplayRun, results []AutoplaySpinResult) {
+	resultsJSON, _ := json.Marshal(results)
+	run.Results = string(resultsJSON)
+	if err := m.db.Save(run).Error; err != nil {
+		println("Failed to save autoplay progress:", err.Error())
+	}
+}
+
+func stopConditionMet(run *models.AutoplayRun, result AutoplaySpinResult) string {
+	switch {
+	case result.RealityCheck:
+		// The next spin would be refused until the player acknowledges it
+		return StopReasonRealityChk
+	case run.StopOnFeature && result.Feature:
+		return StopReasonFeature
+	case run.StopOnWinAbove > 0 && result.Win > run.StopOnWinAbove:
+		return StopReasonWinAbove
+	case run.StopBalanceBelow > 0 && result.Balance < run.StopBalanceBelow:
+		return StopReasonBalance
+	case run.StopNetLossAbove > 0 && -run.NetResult > run.StopNetLossAbove:
+		return StopReasonNetLoss
+	}
+	return ""
+}
+
+// refusalReason turns an error from the settlement path into a stop reason
+func refusalReason(err error) string {
+	var rgErr *ResponsibleGamingError
+	var rcErr *RealityCheckRequiredError
+	switch {
+	case errors.Is(err, ErrInsufficientBalance):
+		return StopReasonBalanceLow
+	case errors.As(err, &rcErr):
+		return StopReasonRealityChk
+	case errors.As(err, &rgErr):
+		return rgErr.Code
+	}
+	return StopReasonSpinRefused
+}
+
+func validateAutoplay(opts AutoplayOptions) error {
+	if opts.Spins < 1 || opts.Spins > MaxAutoplaySpins {
+		return fmt.Errorf("%w: spins must be between 1 and %d", ErrInvalidAutoplay, MaxAutoplaySpins)
+	}
+	if opts.StopOnWinAbove < 0 || opts.StopBalanceBelow < 0 || opts.StopNetLossAbove < 0 {
+		return fmt.Errorf("%w: stop thresholds cannot be negative", ErrInvalidAutoplay)
+	}
+
+	switch opts.Game {
+	case GameFortuneGems:
+		bet := int(opts.Bet)
+		if float64(bet) != opts.Bet || bet < fortuneMinBet || bet > fortuneMaxBet {
+			return fmt.Errorf("%w: Fortune Gems bet must be a whole amount between %d and %d", ErrInvalidAutoplay, fortuneMinBet, fortuneMaxBet)
+		}
+	case GameMythic:
+		if opts.Bet <= 0 {
+			return fmt.Errorf("%w: bet must be greater than 0", ErrInvalidAutoplay)
+		}
+	default:
+		return fmt.Errorf("%w: unknown game %q", ErrInvalidAutoplay, opts.Game)
+	}
+	return nil
+}
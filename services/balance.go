@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"slot-sim/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrSeamlessWallet      = errors.New("balance is held by the operator's wallet")
+)
+
+// AdjustBalance credits (positive amount) or debits (negative amount) a user's
+// balance and records the change with its reason. It runs inside a database
+// transaction so the balance and the audit record are written together, and
+// changes the balance with a single conditional update, so concurrent
+// adjustments and spins can neither be lost nor take it below zero.
+//
+// A player of a seamless wallet operator is credited through the operator's
+// wallet instead: the credit is queued with the adjustment and sent by the
+// WalletReconciler, and the balance shown here follows once it is paid.
+// Their balance cannot be debited here.
+func AdjustBalance(db *gorm.DB, userID uint, amount int, reason, actor string) (*models.BalanceAdjustment, error) {
+	var adjustment *models.BalanceAdjustment
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id", "operator_id", "username", "balance").First(&user, userID).Error; err != nil {
+			return err
+		}
+		var operator models.Operator
+		if err := tx.First(&operator, user.OperatorID).Error; err != nil {
+			return ErrOperatorNotFound
+		}
+		if operator.SeamlessWallet() {
+			adjustment = &models.BalanceAdjustment{
+				UserID:        userID,
+				Amount:        amount,
+				BalanceBefore: user.Balance,
+				BalanceAfter:  user.Balance + amount,
+				Reason:        reason,
+				Actor:         actor,
+			}
+			return queueWalletCredit(tx, &operator, &user, adjustment)
+		}
+
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND balance + ? >= 0", userID, amount).
+			Update("balance", gorm.Expr("balance + ?", amount))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientBalance
+		}
+
+		balance, err := localBalance(tx, userID)
+		if err != nil {
+			return err
+		}
+		adjustment = &models.BalanceAdjustment{
+			UserID:        userID,
+			Amount:        amount,
+			BalanceBefore: balance - amount,
+			BalanceAfter:  balance,
+			Reason:        reason,
+			Actor:         actor,
+		}
+		return tx.Create(adjustment).Error
+	})
+
+	return adjustment, err
+}
+
+// queueWalletCredit records an adjustment of a seamless wallet player along
+// with the wallet credit that pays it
+func queueWalletCredit(tx *gorm.DB, operator *models.Operator, user *models.User, adjustment *models.BalanceAdjustment) error {
+	if adjustment.Amount < 0 {
+		return ErrSeamlessWallet
+	}
+	reference := newWalletReference("adj_")
+	adjustment.WalletCredit = reference + "-" + models.WalletCredit
+	if err := tx.Create(adjustment).Error; err != nil {
+		return err
+	}
+	return NewRemoteWallet(tx, operator).QueueCredit(tx, WalletRequest{
+		TransactionID: adjustment.WalletCredit,
+		RoundID:       reference,
+		OperatorID:    operator.ID,
+		UserID:        user.ID,
+		Player:        user.Username,
+		Amount:        adjustment.Amount,
+	})
+}
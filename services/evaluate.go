@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slot-sim/utils"
+)
+
+// Grid sizes of the two games
+const (
+	MythicRows  = 5
+	MythicCols  = 6
+	FortuneSize = 3
+)
+
+var (
+	ErrInvalidGrid = errors.New("invalid grid")
+	ErrInvalidBet  = errors.New("invalid bet")
+)
+
+// ClusterWin is a cluster on a Mythic Lightning grid and what it pays
+type ClusterWin struct {
+	utils.Cluster
+	Win float64 `json:"win"`
+}
+
+// GridEvaluation is what a Mythic Lightning grid pays as it lands, before
+// tumbles and lightning multipliers
+type GridEvaluation struct {
+	Clusters         []ClusterWin `json:"clusters"`
+	ClusterWin       float64      `json:"cluster_win"`
+	ScatterCount     int          `json:"scatter_count"`
+	FreeSpinsAwarded int          `json:"free_spins_awarded"`
+	ScatterWin       float64      `json:"scatter_win"`
+	TotalWin         float64      `json:"total_win"`
+}
+
+// Evaluate detects the clusters of a supplied grid and pays them and the
+// scatters on the bet, as the first tumble of a base game spin would
+func (e *MythicEngine) Evaluate(grid [][]string, bet float64) (GridEvaluation, error) {
+	if err := CheckMythicGrid(grid); err != nil {
+		return GridEvaluation{}, err
+	}
+
+	evaluation := GridEvaluation{Clusters: []ClusterWin{}}
+	for _, cluster := range utils.DetectClusters(grid) {
+		win := e.CalculateClusterWin(cluster, bet)
+		evaluation.Clusters = append(evaluation.Clusters, ClusterWin{Cluster: cluster, Win: win})
+		evaluation.ClusterWin += win
+	}
+
+	evaluation.ScatterCount = e.CountScatters(grid)
+	evaluation.FreeSpinsAwarded, evaluation.ScatterWin = scatterAward(evaluation.ScatterCount, bet)
+	evaluation.TotalWin = evaluation.ClusterWin + evaluation.ScatterWin
+	return evaluation, nil
+}
+
+// CheckMythicGrid reports whether a grid has the Mythic Lightning shape and
+// only its symbols
+func CheckMythicGrid(grid [][]string) error {
+	if len(grid) != MythicRows {
+		return fmt.Errorf("%w: Mythic Lightning grids have %d rows", ErrInvalidGrid, MythicRows)
+	}
+	for r, row := range grid {
+		if len(row) != MythicCols {
+			return fmt.Errorf("%w: row %d must have %d symbols", ErrInvalidGrid, r, MythicCols)
+		}
+		for c, symbol := range row {
+			if _, ok := symbolWeights[symbol]; !ok {
+				return fmt.Errorf("%w: unknown symbol %q at row %d, column %d", ErrInvalidGrid, symbol, r, c)
+			}
+		}
+	}
+	return nil
+}
+
+// LineWin is one paying Fortune Gems line
+type LineWin struct {
+	Line   int    `json:"line"` // index into the 5 paylines
+	Symbol string `json:"symbol"`
+	Win    int    `json:"win"`
+}
+
+// EvaluateLines pays the lines of a supplied grid, before the special reel
+func (e *FortuneEngine) EvaluateLines(grid [3][3]string, bet int) []LineWin {
+	lines := []LineWin{}
+	for i, line := range fortuneLines {
+		if symbol, win, ok := lineWin(grid, line, bet); ok {
+			lines = append(lines, LineWin{Line: i, Symbol: symbol, Win: win})
+		}
+	}
+	return lines
+}
+
+// FortuneGrid converts a grid of rows to a Fortune Gems grid, checking its
+// shape and symbols
+func FortuneGrid(rows [][]string) ([3][3]string, error) {
+	var grid [3][3]string
+	if len(rows) != FortuneSize {
+		return grid, fmt.Errorf("%w: Fortune Gems grids have %d rows", ErrInvalidGrid, FortuneSize)
+	}
+	for r, row := range rows {
+		if len(row) != FortuneSize {
+			return grid, fmt.Errorf("%w: row %d must have %d symbols", ErrInvalidGrid, r, FortuneSize)
+		}
+		for c, symbol := range row {
+			if _, ok := fortunePaytable[symbol]; !ok {
+				return grid, fmt.Errorf("%w: unknown symbol %q at row %d, column %d", ErrInvalidGrid, symbol, r, c)
+			}
+			grid[r][c] = symbol
+		}
+	}
+	return grid, nil
+}
+
+// CheckBet reports whether a game accepts a bet. Fortune Gems takes whole
+// amounts within its range; Mythic Lightning any positive amount.
+func CheckBet(game string, bet float64) error {
+	switch game {
+	case GameFortuneGems:
+		if float64(int(bet)) != bet || bet < fortuneMinBet || bet > fortuneMaxBet {
+			return fmt.Errorf("%w: Fortune Gems bet must be a whole amount between %d and %d", ErrInvalidBet, fortuneMinBet, fortuneMaxBet)
+		}
+	case GameMythic:
+		if bet <= 0 {
+			return fmt.Errorf("%w: bet must be greater than 0", ErrInvalidBet)
+		}
+	default:
+		return fmt.Errorf("%w: unknown game %q", ErrInvalidBet, game)
+	}
+	return nil
+}
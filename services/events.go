@@ -0,0 +1,190 @@
+package services
+
+import (
+	"slot-sim/config"
+	"slot-sim/models"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Live event types pushed to connected clients
+const (
+	EventBalanceChanged       = "balance_changed"
+	EventTransactionProcessed = "transaction_processed"
+	EventFreeSpinsGranted     = "free_spins_granted"
+	EventBigWin               = "big_win"
+	EventJackpotUpdate        = "jackpot_update"
+
+	// Connection control, sent by the transport rather than published
+	EventHeartbeat = "heartbeat"
+	EventResync    = "resync" // the client fell behind and must reload its state
+)
+
+// EventBufferSize is how many events a subscriber may fall behind by before
+// it is disconnected
+const EventBufferSize = 64
+
+// jackpotUpdateInterval throttles the pool updates sent after every spin.
+// A won jackpot is always sent straight away.
+const jackpotUpdateInterval = 5 * time.Second
+
+// Event is a message on the live event channel. Events with a UserID go to
+// that player's connections only, the others to everyone.
+type Event struct {
+	Type   string      `json:"type"`
+	UserID uint        `json:"-"`
+	Data   interface{} `json:"data,omitempty"`
+	Time   time.Time   `json:"time"`
+}
+
+// Subscription receives the events of one connection. C is closed when the
+// subscription ends, either by Unsubscribe or because the subscriber fell
+// behind; Lagged tells the two apart.
+type Subscription struct {
+	UserID uint
+	C      <-chan Event
+
+	ch     chan Event
+	hub    *EventHub
+	lagged atomic.Bool
+	once   sync.Once
+}
+
+// Lagged reports whether the hub dropped the subscription for not keeping up
+func (sub *Subscription) Lagged() bool {
+	return sub.lagged.Load()
+}
+
+// Unsubscribe removes the subscription from the hub and closes C
+func (sub *Subscription) Unsubscribe() {
+	sub.hub.remove(sub)
+}
+
+// EventHub is the in-process pub/sub hub behind the live event channel.
+// Publishing never blocks: a subscriber whose buffer is full is dropped and
+// has to reconnect and reload its state.
+type EventHub struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subs: map[*Subscription]struct{}{}}
+}
+
+// Events is the hub shared by the whole process
+var Events = NewEventHub()
+
+// Subscribe opens a subscription for the player's events and the global ones
+func (h *EventHub) Subscribe(userID uint) *Subscription {
+	ch := make(chan Event, EventBufferSize)
+	sub := &Subscription{UserID: userID, C: ch, ch: ch, hub: h}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish delivers the event to every matching subscriber
+func (h *EventHub) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	var lagging []*Subscription
+	h.mu.RLock()
+	for sub := range h.subs {
+		if event.UserID != 0 && event.UserID != sub.UserID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range lagging {
+		sub.lagged.Store(true)
+		h.remove(sub)
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (h *EventHub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+func (h *EventHub) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		h.mu.Lock()
+		delete(h.subs, sub)
+		h.mu.Unlock()
+		close(sub.ch)
+	})
+}
+
+// PublishBalance sends the player's current balance. Call it after the
+// change is committed.
+func PublishBalance(db *gorm.DB, userID uint, reason string) {
+	var balance int
+	if err := db.Model(&models.User{}).Where("id = ?", userID).Select("balance").Scan(&balance).Error; err != nil {
+		return
+	}
+	Events.Publish(Event{
+		Type:   EventBalanceChanged,
+		UserID: userID,
+		Data:   map[string]interface{}{"balance": balance, "reason": reason},
+	})
+}
+
+// PublishFreeSpins tells the player about a new free spin pack
+func PublishFreeSpins(pack *models.FreeSpinPack) {
+	Events.Publish(Event{Type: EventFreeSpinsGranted, UserID: pack.UserID, Data: pack})
+}
+
+var lastJackpotUpdate atomic.Int64
+
+// publishRound sends the events of a settled spin: the player's new balance,
+// a big win to everyone when the win reaches the configured multiple of the
+// bet, and the jackpot pools
+func publishRound(db *gorm.DB, userID uint, game string, bet, win float64, balance int, jackpot *JackpotAward) {
+	Events.Publish(Event{
+		Type:   EventBalanceChanged,
+		UserID: userID,
+		Data:   map[string]interface{}{"balance": balance, "reason": "spin"},
+	})
+
+	if bet > 0 && win/bet >= config.BigWinMultiplier() {
+		Events.Publish(Event{
+			Type: EventBigWin,
+			Data: map[string]interface{}{"game": game, "bet": bet, "win": win, "multiplier": win / bet},
+		})
+	}
+
+	now := time.Now().UnixNano()
+	last := lastJackpotUpdate.Load()
+	if jackpot == nil && now-last < int64(jackpotUpdateInterval) {
+		return
+	}
+	if !lastJackpotUpdate.CompareAndSwap(last, now) && jackpot == nil {
+		return
+	}
+
+	pools, err := NewJackpotService(db).Pools()
+	if err != nil {
+		return
+	}
+	data := map[string]interface{}{"jackpots": pools}
+	if jackpot != nil {
+		data["won"] = map[string]interface{}{"game": game, "tier": jackpot.Tier, "amount": jackpot.Amount}
+	}
+	Events.Publish(Event{Type: EventJackpotUpdate, Data: data})
+}
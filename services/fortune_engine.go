@@ -0,0 +1,191 @@
+package services
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Fortune Gems (3x3) symbols
+const (
+	SymWild = "WILD"
+	Sym7    = "777"
+	SymGemR = "GEM_RED"
+	SymGemG = "GEM_GREEN"
+	SymGemB = "GEM_BLUE"
+	SymA    = "A"
+	SymK    = "K"
+	SymQ    = "Q"
+	SymJ    = "J"
+)
+
+// Fortune Gems paytable (base multipliers per line)
+var fortunePaytable = map[string]int{
+	SymWild: 100, // 3x Wild
+	Sym7:    50,
+	SymGemR: 30,
+	SymGemG: 20,
+	SymGemB: 15,
+	SymA:    10,
+	SymK:    8,
+	SymQ:    5,
+	SymJ:    2,
+}
+
+var fortuneSymbols = []string{SymWild, Sym7, SymGemR, SymGemG, SymGemB, SymA, SymK, SymQ, SymJ}
+
+// Special Reel Items
+const (
+	Mult1x    = "1x"
+	Mult2x    = "2x"
+	Mult3x    = "3x"
+	Mult5x    = "5x"
+	Mult10x   = "10x"
+	Mult15x   = "15x"
+	FeatWheel = "WHEEL" // Triggers Fortune Spin
+)
+
+var specialReel = []string{Mult1x, Mult1x, Mult2x, Mult2x, Mult3x, Mult3x, Mult5x, Mult5x, Mult10x, Mult15x, FeatWheel}
+
+var specialMultipliers = map[string]int{
+	Mult1x:  1,
+	Mult2x:  2,
+	Mult3x:  3,
+	Mult5x:  5,
+	Mult10x: 10,
+	Mult15x: 15,
+}
+
+// Wheel Prizes (Multipliers of Bet)
+var wheelPrizes = []int{10, 20, 50, 100, 200, 500, 1000}
+
+// 5 Paylines: Top, Middle, Bottom, Diagonal 1, Diagonal 2
+var fortuneLines = [][3][2]int{
+	{{0, 0}, {0, 1}, {0, 2}}, // Row 0
+	{{1, 0}, {1, 1}, {1, 2}}, // Row 1
+	{{2, 0}, {2, 1}, {2, 2}}, // Row 2
+	{{0, 0}, {1, 1}, {2, 2}}, // Diag TL-BR
+	{{2, 0}, {1, 1}, {0, 2}}, // Diag BL-TR
+}
+
+type FortuneEngine struct {
+	rng *rand.Rand
+}
+
+func NewFortuneEngine() *FortuneEngine {
+	return &FortuneEngine{
+		rng: newLockedRand(time.Now().UnixNano()),
+	}
+}
+
+// FortuneOutcome is the result of one Fortune Gems spin before settlement
+type FortuneOutcome struct {
+	Grid          [3][3]string `json:"grid"`
+	SpecialSymbol string       `json:"special_symbol"`
+	BaseWin       int          `json:"base_win"`
+	BonusWin      int          `json:"bonus_win"`
+	FinalWin      int          `json:"final_win"`
+	Multiplier    int          `json:"multiplier"`
+	IsFortuneSpin bool         `json:"is_fortune_spin"`
+	WheelPrize    int          `json:"wheel_prize,omitempty"` // multiple of the bet won on the wheel
+	WinningLines  []int        `json:"winning_lines,omitempty"`
+	RNGSeed       int64        `json:"rng_seed"` // replays the round with PlaySeed
+}
+
+// GenerateGrid fills the 3x3 grid with uniformly random symbols
+func (e *FortuneEngine) GenerateGrid() [3][3]string {
+	var grid [3][3]string
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			grid[r][c] = fortuneSymbols[e.rng.Intn(len(fortuneSymbols))]
+		}
+	}
+	return grid
+}
+
+// GetSpecialReel spins the special reel next to the grid
+func (e *FortuneEngine) GetSpecialReel() string {
+	return specialReel[e.rng.Intn(len(specialReel))]
+}
+
+// SpinWheel returns a wheel prize as a multiple of the bet
+func (e *FortuneEngine) SpinWheel() int {
+	return wheelPrizes[e.rng.Intn(len(wheelPrizes))]
+}
+
+// CalculateWin evaluates the 5 paylines of a grid
+func (e *FortuneEngine) CalculateWin(grid [3][3]string, bet int) (int, []int) {
+	totalWin := 0
+	var winningLines []int
+
+	for i, line := range fortuneLines {
+		if _, win, ok := lineWin(grid, line, bet); ok {
+			totalWin += win
+			winningLines = append(winningLines, i)
+		}
+	}
+
+	return totalWin, winningLines
+}
+
+// lineWin evaluates one payline, returning the symbol it pays for
+func lineWin(grid [3][3]string, line [3][2]int, bet int) (string, int, bool) {
+	s1 := grid[line[0][0]][line[0][1]]
+	s2 := grid[line[1][0]][line[1][1]]
+	s3 := grid[line[2][0]][line[2][1]]
+
+	// Check Match (considering Wild)
+	matchSymbol := ""
+	if s1 == SymWild {
+		if s2 == SymWild {
+			matchSymbol = s3 // WW? -> match 3rd
+		} else {
+			matchSymbol = s2 // W? -> match 2nd
+		}
+	} else {
+		matchSymbol = s1
+	}
+
+	// If mostly Wild scenarios
+	if s1 == SymWild && s2 == SymWild && s3 == SymWild {
+		matchSymbol = SymWild
+	}
+
+	if (s1 == matchSymbol || s1 == SymWild) &&
+		(s2 == matchSymbol || s2 == SymWild) &&
+		(s3 == matchSymbol || s3 == SymWild) {
+		return matchSymbol, fortunePaytable[matchSymbol] * (bet / 10), true // Simplified bet unit
+	}
+	return "", 0, false
+}
+
+// Play runs one complete spin: grid, special reel and wheel or multiplier
+func (e *FortuneEngine) Play(bet int) FortuneOutcome {
+	return e.PlaySeed(bet, NewRoundSeed())
+}
+
+// PlaySeed plays the spin the seed determines: the same seed and bet always
+// give the same round
+func (e *FortuneEngine) PlaySeed(bet int, seed int64) FortuneOutcome {
+	round := &FortuneEngine{rng: rand.New(rand.NewSource(seed))}
+
+	outcome := FortuneOutcome{
+		Grid:          round.GenerateGrid(),
+		SpecialSymbol: round.GetSpecialReel(),
+		Multiplier:    1,
+		RNGSeed:       seed,
+	}
+	outcome.BaseWin, outcome.WinningLines = round.CalculateWin(outcome.Grid, bet)
+
+	if outcome.SpecialSymbol == FeatWheel {
+		// The wheel pays a multiple of the bet on top of the line wins
+		outcome.IsFortuneSpin = true
+		outcome.WheelPrize = round.SpinWheel()
+		outcome.BonusWin = bet * outcome.WheelPrize
+		outcome.FinalWin = outcome.BaseWin + outcome.BonusWin
+	} else {
+		outcome.Multiplier = specialMultipliers[outcome.SpecialSymbol]
+		outcome.FinalWin = outcome.BaseWin * outcome.Multiplier
+	}
+
+	return outcome
+}
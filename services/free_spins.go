@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoFreeSpins         = errors.New("no free spins left in this pack")
+	ErrFreeSpinPackExpired = errors.New("free spin pack has expired")
+	ErrInvalidFreeSpins    = errors.New("invalid free spin grant")
+)
+
+// GrantFreeSpins gives the player a pack of Mythic Lightning spins at a
+// fixed bet. Pass a transaction to grant together with other changes.
+func GrantFreeSpins(db *gorm.DB, userID uint, spins int, bet float64, source string, expiresAt *time.Time) (*models.FreeSpinPack, error) {
+	if spins <= 0 || bet <= 0 {
+		return nil, fmt.Errorf("%w: spins and bet must be greater than 0", ErrInvalidFreeSpins)
+	}
+
+	pack := &models.FreeSpinPack{
+		UserID:    userID,
+		Bet:       bet,
+		Total:     spins,
+		Remaining: spins,
+		Source:    source,
+		ExpiresAt: expiresAt,
+	}
+	if err := db.Create(pack).Error; err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// FreeSpinPacks returns the player's packs that still have spins to play
+func FreeSpinPacks(db *gorm.DB, userID uint) ([]models.FreeSpinPack, error) {
+	var packs []models.FreeSpinPack
+	err := db.Where("user_id = ? AND remaining > 0", userID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at").
+		Find(&packs).Error
+	return packs, err
+}
+
+// consumeFreeSpin takes one spin from the pack inside the settlement
+// transaction. The conditional update keeps concurrent spins from playing
+// the same spin twice.
+func consumeFreeSpin(tx *gorm.DB, userID, packID uint) (*models.FreeSpinPack, error) {
+	var pack models.FreeSpinPack
+	if err := tx.Where("id = ? AND user_id = ?", packID, userID).First(&pack).Error; err != nil {
+		return nil, ErrNoFreeSpins
+	}
+	if pack.ExpiresAt != nil && time.Now().After(*pack.ExpiresAt) {
+		return nil, ErrFreeSpinPackExpired
+	}
+
+	result := tx.Model(&models.FreeSpinPack{}).
+		Where("id = ? AND remaining > 0", pack.ID).
+		Update("remaining", gorm.Expr("remaining - 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNoFreeSpins
+	}
+
+	pack.Remaining--
+	return &pack, nil
+}
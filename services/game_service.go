@@ -0,0 +1,506 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"slot-sim/config"
+	"slot-sim/models"
+
+	"gorm.io/gorm"
+)
+
+// Game identifiers used across play sessions, autoplay and reporting
+const (
+	GameFortuneGems = "fortune_gems"
+	GameMythic      = "mythic_lightning"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrFeatureBuyDisabled = errors.New("feature buy is not available")
+	ErrAnteWithFeatureBuy = errors.New("ante bet cannot be combined with feature buy")
+)
+
+// FortuneRound is a settled Fortune Gems spin
+type FortuneRound struct {
+	FortuneOutcome
+	RoundID        uint          `json:"round_id"`
+	Bet            int           `json:"bet"`
+	BalanceChange  int           `json:"balance_change"`
+	CurrentBalance int           `json:"current_balance"`
+	Jackpot        *JackpotAward `json:"jackpot,omitempty"`
+	Missions       []string      `json:"missions_completed,omitempty"`
+	RealityCheck   *RealityCheck `json:"reality_check,omitempty"`
+}
+
+// MythicRound is a settled Mythic Lightning spin
+type MythicRound struct {
+	MythicOutcome
+	Bet            float64              `json:"bet"`
+	Cost           float64              `json:"cost"` // amount debited: the bet with any ante, or the feature price
+	SessionID      uint                 `json:"session_id"`
+	CurrentBalance float64              `json:"current_balance"`
+	Jackpot        *JackpotAward        `json:"jackpot,omitempty"`
+	Missions       []string             `json:"missions_completed,omitempty"`
+	FreeSpinPack   *models.FreeSpinPack `json:"free_spin_pack,omitempty"` // pack the spin was played from
+	RealityCheck   *RealityCheck        `json:"reality_check,omitempty"`
+}
+
+// GameService is the single settlement path for both games: every spin,
+// whether sent by the client or run by autoplay, goes through it and moves
+// its money through the wallet of the player's operator
+type GameService struct {
+	db           *gorm.DB
+	fortune      *FortuneEngine
+	mythic       *MythicEngine
+	playSessions *PlaySessionService
+	limits       *ResponsibleGamingService
+	jackpots     *JackpotService
+	tournaments  *TournamentService
+	missions     *MissionService
+	loyalty      *LoyaltyService
+	featureBuy   config.FeatureBuyConfig
+}
+
+func NewGameService(db *gorm.DB) *GameService {
+	return &GameService{
+		db:           db,
+		fortune:      NewFortuneEngine(),
+		mythic:       NewMythicEngine(),
+		playSessions: NewPlaySessionService(db),
+		limits:       NewResponsibleGamingService(db),
+		jackpots:     NewJackpotService(db),
+		tournaments:  NewTournamentService(db),
+		missions:     NewMissionService(db),
+		loyalty:      NewLoyaltyService(db),
+		featureBuy:   config.LoadFeatureBuyConfig(),
+	}
+}
+
+// PlayFortuneGems plays and settles one Fortune Gems spin
+func (s *GameService) PlayFortuneGems(userID uint, bet int) (*FortuneRound, error) {
+	spin, err := s.beforeSpin(userID, GameFortuneGems, float64(bet), float64(bet))
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := s.fortune.Play(bet)
+	round := &FortuneRound{
+		FortuneOutcome: outcome,
+		Bet:            bet,
+		BalanceChange:  outcome.FinalWin - bet,
+	}
+
+	if err := spin.wallet.debit(bet); err != nil {
+		return nil, err
+	}
+
+	var jackpotWin int
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		jackpot, err := s.jackpots.Settle(tx, userID, GameFortuneGems, float64(bet))
+		if err != nil {
+			return err
+		}
+		if jackpot != nil {
+			jackpotWin = int(jackpot.Amount)
+		}
+
+		balance, err := spin.wallet.settle(tx, outcome.FinalWin+jackpotWin)
+		if err != nil {
+			return err
+		}
+		round.CurrentBalance = balance
+		round.BalanceChange += jackpotWin
+		round.Jackpot = jackpot
+
+		multiplier := float64(outcome.FinalWin) / float64(bet)
+		if err := s.tournaments.RecordSpin(tx, userID, GameFortuneGems, float64(bet), multiplier); err != nil {
+			return err
+		}
+		if err := s.loyalty.RecordSpin(tx, userID, GameFortuneGems, float64(bet)); err != nil {
+			return err
+		}
+
+		round.Missions, err = s.missions.RecordSpin(tx, SpinEvent{
+			UserID:  userID,
+			Game:    GameFortuneGems,
+			Stake:   float64(bet),
+			Bet:     float64(bet),
+			Win:     float64(outcome.FinalWin),
+			Feature: outcome.IsFortuneSpin,
+			Jackpot: jackpot != nil,
+		})
+		if err != nil {
+			return err
+		}
+
+		gamelog := models.Gamelog{
+			UserID:        userID,
+			OperatorID:    spin.operatorID,
+			Action:        "slot_3x3",
+			Bet:           bet,
+			Outcome:       "spin", // Simplified for now
+			BalanceChange: round.BalanceChange,
+			Win:           outcome.FinalWin,
+			JackpotWin:    jackpotWin,
+		}
+		if err := tx.Create(&gamelog).Error; err != nil {
+			return err
+		}
+
+		gridJSON, _ := json.Marshal(outcome.Grid)
+		linesJSON, _ := json.Marshal(outcome.WinningLines)
+
+		record := models.FortuneRound{
+			UserID:        userID,
+			GamelogID:     gamelog.ID,
+			Bet:           bet,
+			Grid:          string(gridJSON),
+			WinningLines:  string(linesJSON),
+			SpecialSymbol: outcome.SpecialSymbol,
+			Multiplier:    outcome.Multiplier,
+			IsFortuneSpin: outcome.IsFortuneSpin,
+			WheelPrize:    outcome.WheelPrize,
+			BaseWin:       outcome.BaseWin,
+			BonusWin:      outcome.BonusWin,
+			FinalWin:      outcome.FinalWin,
+			JackpotWin:    jackpotWin,
+			BalanceBefore: balance - round.BalanceChange,
+			BalanceAfter:  balance,
+			RNGSeed:       outcome.RNGSeed,
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		round.RoundID = record.ID
+		return nil
+	})
+	if err != nil {
+		spin.wallet.void()
+		return nil, err
+	}
+	spin.wallet.credit()
+
+	publishRound(s.db, userID, GameFortuneGems, float64(bet), float64(outcome.FinalWin), round.CurrentBalance, round.Jackpot)
+
+	round.RealityCheck, err = s.playSessions.RecordSpin(spin.playSession, float64(bet), float64(outcome.FinalWin+jackpotWin))
+	if err != nil {
+		println("Failed to update play session:", err.Error())
+	}
+	return round, nil
+}
+
+// PendingMythicSpin is a Mythic Lightning spin whose outcome is drawn while
+// its settlement is still being committed
+type PendingMythicSpin struct {
+	Outcome MythicOutcome
+	Bet     float64
+	Cost    float64
+
+	done  chan struct{}
+	round *MythicRound
+	err   error
+}
+
+// Wait blocks until the settlement is committed or has failed. Nothing of
+// the outcome counts as won before Wait returns without an error.
+func (p *PendingMythicSpin) Wait() (*MythicRound, error) {
+	<-p.done
+	return p.round, p.err
+}
+
+// SpinMythic plays and settles one Mythic Lightning spin. With ante the
+// stake is AnteBetMultiplier times the bet, rounded up to whole balance units.
+func (s *GameService) SpinMythic(userID uint, bet float64, ante bool) (*MythicRound, error) {
+	pending, err := s.StartMythicSpin(userID, bet, ante)
+	if err != nil {
+		return nil, err
+	}
+	return pending.Wait()
+}
+
+// StartMythicSpin runs the pre-spin checks and draws the outcome, then
+// settles it in the background so the caller can start presenting the
+// tumbles straight away
+func (s *GameService) StartMythicSpin(userID uint, bet float64, ante bool) (*PendingMythicSpin, error) {
+	cost := bet
+	if ante {
+		cost = math.Ceil(bet * AnteBetMultiplier)
+	}
+
+	spin, err := s.beforeSpin(userID, GameMythic, bet, cost)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &PendingMythicSpin{
+		Outcome: s.mythic.Spin(bet, ante),
+		Bet:     bet,
+		Cost:    cost,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(pending.done)
+		pending.round, pending.err = s.settleMythic(userID, spin, bet, cost, pending.Outcome, 0)
+	}()
+	return pending, nil
+}
+
+// PlayFreeSpin plays one spin of a free spin pack: no stake is debited and
+// the win is credited and added to the pack's winnings
+func (s *GameService) PlayFreeSpin(userID, packID uint) (*MythicRound, error) {
+	var pack models.FreeSpinPack
+	if err := s.db.Where("id = ? AND user_id = ?", packID, userID).First(&pack).Error; err != nil {
+		return nil, ErrNoFreeSpins
+	}
+
+	spin, err := s.beforeSpin(userID, GameMythic, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settleMythic(userID, spin, pack.Bet, 0, s.mythic.Spin(pack.Bet, false), pack.ID)
+}
+
+// FeatureBuy returns the bonus buy settings of an operator, with the
+// deployment's price where it sets none
+func (s *GameService) FeatureBuy(operator *models.Operator) config.FeatureBuyConfig {
+	cfg := config.FeatureBuyConfig{Enabled: operator.FeatureBuy, CostMultiplier: operator.FeatureBuyCost}
+	if cfg.CostMultiplier == 0 {
+		cfg.CostMultiplier = s.featureBuy.CostMultiplier
+	}
+	return cfg
+}
+
+// BuyMythicFeature charges the feature price and plays the free spins
+// feature directly. The player's operator must offer it; the spin is then
+// checked and settled like a regular spin for the full price.
+func (s *GameService) BuyMythicFeature(userID uint, bet float64) (*MythicRound, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	var operator models.Operator
+	if err := s.db.First(&operator, user.OperatorID).Error; err != nil {
+		return nil, ErrOperatorNotFound
+	}
+	if err := CheckOperatorFeatureBuy(&operator); err != nil {
+		return nil, err
+	}
+	cost := bet * s.FeatureBuy(&operator).CostMultiplier
+
+	spin, err := s.beforeSpin(userID, GameMythic, bet, cost)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settleMythic(userID, spin, bet, cost, s.mythic.BuyFeature(bet), 0)
+}
+
+// settleMythic debits the cost, credits the win and records the round. A
+// non-zero packID plays the spin from that free spin pack.
+func (s *GameService) settleMythic(userID uint, spin *checkedSpin, bet, cost float64, outcome MythicOutcome, packID uint) (*MythicRound, error) {
+	round := &MythicRound{
+		MythicOutcome: outcome,
+		Bet:           bet,
+		Cost:          cost,
+	}
+
+	if err := spin.wallet.debit(int(cost)); err != nil {
+		return nil, err
+	}
+
+	var jackpotWin float64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var packRef *uint
+		if packID != 0 {
+			pack, err := consumeFreeSpin(tx, userID, packID)
+			if err != nil {
+				return err
+			}
+			err = tx.Model(pack).Update("total_win", gorm.Expr("total_win + ?", outcome.TotalWin)).Error
+			if err != nil {
+				return err
+			}
+			pack.TotalWin += outcome.TotalWin
+			round.FreeSpinPack = pack
+			packRef = &pack.ID
+		}
+
+		jackpot, err := s.jackpots.Settle(tx, userID, GameMythic, cost)
+		if err != nil {
+			return err
+		}
+		if jackpot != nil {
+			jackpotWin = jackpot.Amount
+		}
+
+		balance, err := spin.wallet.settle(tx, int(outcome.TotalWin)+int(jackpotWin))
+		if err != nil {
+			return err
+		}
+		round.CurrentBalance = float64(balance)
+		round.Jackpot = jackpot
+
+		if err := s.tournaments.RecordSpin(tx, userID, GameMythic, cost, outcome.TotalWin/bet); err != nil {
+			return err
+		}
+		if err := s.loyalty.RecordSpin(tx, userID, GameMythic, cost); err != nil {
+			return err
+		}
+
+		round.Missions, err = s.missions.RecordSpin(tx, SpinEvent{
+			UserID:   userID,
+			Game:     GameMythic,
+			Stake:    cost,
+			Bet:      bet,
+			Win:      outcome.TotalWin,
+			Tumbles:  len(outcome.Tumbles),
+			Scatters: outcome.ScatterCount,
+			Feature:  outcome.FreeSpinsAwarded > 0,
+			Jackpot:  jackpot != nil,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Save session to database
+		initialGridJSON, _ := json.Marshal(outcome.InitialGrid)
+		gridJSON, _ := json.Marshal(outcome.Grid)
+		tumblesJSON, _ := json.Marshal(outcome.Tumbles)
+		strikes := make([][]float64, len(outcome.Tumbles))
+		for i, tumble := range outcome.Tumbles {
+			strikes[i] = tumble.Strikes
+		}
+		strikesJSON, _ := json.Marshal(strikes)
+		var freeSpinsJSON []byte
+		if len(outcome.FreeSpins) > 0 {
+			freeSpinsJSON, _ = json.Marshal(outcome.FreeSpins)
+		}
+
+		session := models.MythicSession{
+			UserID:           userID,
+			OperatorID:       spin.operatorID,
+			BetAmount:        cost,
+			BaseBet:          bet,
+			FeatureBuy:       outcome.FeatureBuy,
+			AnteBet:          outcome.AnteBet,
+			WeightProfile:    outcome.WeightProfile,
+			InitialGrid:      string(initialGridJSON),
+			Grid:             string(gridJSON),
+			TumblesCount:     len(outcome.Tumbles),
+			Tumbles:          string(tumblesJSON),
+			Multipliers:      string(strikesJSON),
+			FreeSpinRounds:   string(freeSpinsJSON),
+			TotalWin:         outcome.TotalWin,
+			JackpotWin:       jackpotWin,
+			BaseWin:          outcome.BaseWin,
+			MultiplierWin:    outcome.TotalWin - outcome.BaseWin,
+			FreeSpinsActive:  outcome.FreeSpinsAwarded > 0,
+			FreeSpinsRemain:  outcome.FreeSpinsAwarded - len(outcome.FreeSpins),
+			GlobalMultiplier: outcome.TotalMultiplier,
+			FreeSpinPackID:   packRef,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		round.SessionID = session.ID
+		return nil
+	})
+	if err != nil {
+		spin.wallet.void()
+		return nil, err
+	}
+	spin.wallet.credit()
+
+	// Big wins are measured against the price paid, or the bet on a free spin
+	publishRound(s.db, userID, GameMythic, math.Max(bet, cost), outcome.TotalWin, int(round.CurrentBalance), round.Jackpot)
+
+	round.RealityCheck, err = s.playSessions.RecordSpin(spin.playSession, cost, outcome.TotalWin+jackpotWin)
+	if err != nil {
+		println("Failed to update play session:", err.Error())
+	}
+	return round, nil
+}
+
+// MythicRTP is the return to player of one Mythic Lightning play mode
+type MythicRTP struct {
+	FeatureBuy bool    `json:"feature_buy"`
+	AnteBet    bool    `json:"ante_bet"`
+	Rounds     int64   `json:"rounds"`
+	Wagered    float64 `json:"wagered"`
+	Won        float64 `json:"won"`
+	RTP        float64 `json:"rtp"` // percentage
+}
+
+// MythicRTPReport reports the realised RTP of regular spins, ante spins and
+// feature buys separately. Free spin pack rounds have no stake and are left
+// out; scopes narrow the rounds counted, such as to one operator's.
+func (s *GameService) MythicRTPReport(scopes ...func(*gorm.DB) *gorm.DB) ([]MythicRTP, error) {
+	var report []MythicRTP
+	err := s.db.Model(&models.MythicSession{}).Scopes(scopes...).
+		Where("free_spin_pack_id IS NULL").
+		Select("feature_buy, ante_bet, COUNT(*) AS rounds, COALESCE(SUM(bet_amount), 0) AS wagered, COALESCE(SUM(total_win), 0) AS won").
+		Group("feature_buy, ante_bet").
+		Order("feature_buy, ante_bet").
+		Scan(&report).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report {
+		if report[i].Wagered > 0 {
+			report[i].RTP = report[i].Won / report[i].Wagered * 100
+		}
+	}
+	return report, nil
+}
+
+// checkedSpin is a spin that passed the pre-spin checks
+type checkedSpin struct {
+	playSession *models.PlaySession
+	operatorID  uint
+	wallet      *walletRound // settles the spin's money
+}
+
+// beforeSpin runs the checks every spin must pass before any money moves.
+// bet is checked against the operator's limits, cost against the balance
+// and the player's own limits; a free spin has neither. The balance of a
+// seamless wallet is left to the wallet to check when it is debited.
+func (s *GameService) beforeSpin(userID uint, game string, bet, cost float64) (*checkedSpin, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	// The operator must offer the game at this bet
+	var operator models.Operator
+	if err := s.db.First(&operator, user.OperatorID).Error; err != nil {
+		return nil, ErrOperatorNotFound
+	}
+	if err := CheckOperatorSpin(&operator, game, bet); err != nil {
+		return nil, err
+	}
+
+	if !operator.SeamlessWallet() && float64(user.Balance) < cost {
+		return nil, ErrInsufficientBalance
+	}
+
+	// Play session tracking, refused while a reality check is unacknowledged
+	playSession, err := s.playSessions.BeforeSpin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Responsible gaming: exclusions, session time, loss and wager limits
+	if err := s.limits.CheckPlay(userID, cost); err != nil {
+		return nil, err
+	}
+
+	return &checkedSpin{
+		playSession: playSession,
+		operatorID:  user.OperatorID,
+		wallet:      newWalletRound(WalletFor(s.db, &operator), &user, game),
+	}, nil
+}
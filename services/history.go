@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// History page size limits
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+// HistoryQuery is the paging, filtering and sorting shared by the history
+// endpoints. Pages are keyset based: the cursor holds the sort value and ID
+// of the last row sent, so paging stays stable while new rows are written.
+type HistoryQuery struct {
+	Cursor string
+	Limit  int
+	From   *time.Time // created_at >= From
+	To     *time.Time // created_at < To
+	Sort   string     // column to sort by
+	Desc   bool
+	Scopes []func(*gorm.DB) *gorm.DB // endpoint specific filters
+}
+
+// HistoryPage is one page of history rows. Total counts every row matching
+// the filters, not only the ones on this page.
+type HistoryPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+type historyCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// PageHistory runs the query on the rows of T the base query selects, for
+// example one player's rows
+func PageHistory[T any](base *gorm.DB, q HistoryQuery) (*HistoryPage[T], error) {
+	stmt := &gorm.Statement{DB: base}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	sortField := stmt.Schema.LookUpField(q.Sort)
+	idField := stmt.Schema.PrioritizedPrimaryField
+	if sortField == nil || idField == nil {
+		return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidHistoryQuery, q.Sort)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultHistoryLimit
+	}
+	if q.Limit > MaxHistoryLimit {
+		q.Limit = MaxHistoryLimit
+	}
+
+	query := base.Model(new(T)).Scopes(q.Scopes...)
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at < ?", *q.To)
+	}
+
+	page := &HistoryPage[T]{Items: []T{}}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	direction, compare, sortKey := "ASC", ">", sortField.DBName
+	if q.Desc {
+		direction, compare, sortKey = "DESC", "<", "-"+sortField.DBName
+	}
+
+	if q.Cursor != "" {
+		value, id, err := decodeHistoryCursor(q.Cursor, sortKey, sortField.FieldType)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND %[3]s %[2]s ?))", sortField.DBName, compare, idField.DBName),
+			value, value, id,
+		)
+	}
+
+	// One extra row tells whether there is a next page
+	err := query.
+		Order(fmt.Sprintf("%s %s, %s %s", sortField.DBName, direction, idField.DBName, direction)).
+		Limit(q.Limit + 1).
+		Find(&page.Items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		last := reflect.ValueOf(&page.Items[q.Limit-1]).Elem()
+		value, _ := sortField.ValueOf(context.Background(), last)
+		id, _ := idField.ValueOf(context.Background(), last)
+		page.NextCursor, err = encodeHistoryCursor(sortKey, value, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func encodeHistoryCursor(sort string, value, id interface{}) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	idValue, _ := id.(uint)
+	cursor, err := json.Marshal(historyCursor{Sort: sort, Value: raw, ID: idValue})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+// decodeHistoryCursor returns the cursor's sort value as the column's Go
+// type. A cursor from a page sorted by another column or in the other
+// direction is refused.
+func decodeHistoryCursor(encoded, sort string, fieldType reflect.Type) (interface{}, uint, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidHistoryQuery)
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	var cursor historyCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort {
+		return nil, 0, invalid
+	}
+
+	value := reflect.New(fieldType)
+	if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+		return nil, 0, invalid
+	}
+	return value.Elem().Interface(), cursor.ID, nil
+}
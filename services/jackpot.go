@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slot-sim/config"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultJackpots seed the pools the first time they are needed. Values can
+// be tuned afterwards in the jackpots table.
+var defaultJackpots = []models.Jackpot{
+	{Tier: models.JackpotMini, SeedValue: 100, ContributionShare: 0.5, TriggerChance: 1.0 / 1000},
+	{Tier: models.JackpotMajor, SeedValue: 1000, ContributionShare: 0.3, TriggerChance: 1.0 / 20000},
+	{Tier: models.JackpotGrand, SeedValue: 10000, ContributionShare: 0.2, TriggerChance: 1.0 / 500000},
+}
+
+// Highest tier first, at most one jackpot is awarded per spin
+var jackpotDrawOrder = []string{models.JackpotGrand, models.JackpotMajor, models.JackpotMini}
+
+const maxAwardAttempts = 5
+
+var errJackpotContended = errors.New("jackpot pool changed during award")
+
+// JackpotAward is a jackpot won on a spin
+type JackpotAward struct {
+	Tier   string  `json:"tier"`
+	Amount float64 `json:"amount"`
+}
+
+type JackpotService struct {
+	db           *gorm.DB
+	rng          *rand.Rand
+	contribution float64
+}
+
+func NewJackpotService(db *gorm.DB) *JackpotService {
+	return &JackpotService{
+		db:           db,
+		rng:          newLockedRand(time.Now().UnixNano()),
+		contribution: config.JackpotContributionRate(),
+	}
+}
+
+// Pools returns the live pool values
+func (s *JackpotService) Pools() ([]models.Jackpot, error) {
+	return s.pools(s.db)
+}
+
+// Winners returns the most recent jackpot awards
+func (s *JackpotService) Winners(limit int) ([]models.JackpotWin, error) {
+	var wins []models.JackpotWin
+	err := s.db.Order("created_at DESC").Limit(limit).Find(&wins).Error
+	return wins, err
+}
+
+// Settle runs inside the spin's settlement transaction: the stake feeds
+// every pool and one draw decides whether a tier is won. The award is
+// returned so the caller credits it with the game win.
+func (s *JackpotService) Settle(tx *gorm.DB, userID uint, game string, stake float64) (*JackpotAward, error) {
+	pools, err := s.pools(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if contribution := stake * s.contribution; contribution > 0 {
+		err := tx.Model(&models.Jackpot{}).
+			Where("1 = 1").
+			Update("amount", gorm.Expr("amount + ? * contribution_share", contribution)).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	won := s.draw(pools)
+	if won == nil {
+		return nil, nil
+	}
+
+	for attempt := 0; attempt < maxAwardAttempts; attempt++ {
+		award, err := s.award(tx, won.ID, userID, game)
+		if errors.Is(err, errJackpotContended) {
+			continue
+		}
+		return award, err
+	}
+	return nil, errJackpotContended
+}
+
+// draw rolls each tier, highest first
+func (s *JackpotService) draw(pools []models.Jackpot) *models.Jackpot {
+	byTier := make(map[string]*models.Jackpot, len(pools))
+	for i := range pools {
+		byTier[pools[i].Tier] = &pools[i]
+	}
+
+	for _, tier := range jackpotDrawOrder {
+		if pool, ok := byTier[tier]; ok && s.rng.Float64() < pool.TriggerChance {
+			return pool
+		}
+	}
+	return nil
+}
+
+// award pays out a pool and resets it to its seed. The reset only applies
+// if the pool still holds the amount read, so two concurrent winners can
+// never both be paid the same pool.
+func (s *JackpotService) award(tx *gorm.DB, jackpotID, userID uint, game string) (*JackpotAward, error) {
+	var pool models.Jackpot
+	if err := tx.First(&pool, jackpotID).Error; err != nil {
+		return nil, err
+	}
+
+	// Balances are whole units, the fraction stays in the pool
+	amount := math.Floor(pool.Amount)
+	now := time.Now()
+
+	result := tx.Model(&models.Jackpot{}).
+		Where("id = ? AND amount = ?", pool.ID, pool.Amount).
+		Updates(map[string]interface{}{
+			"amount":      pool.SeedValue + pool.Amount - amount,
+			"last_won_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errJackpotContended
+	}
+
+	win := models.JackpotWin{
+		JackpotID: pool.ID,
+		Tier:      pool.Tier,
+		UserID:    userID,
+		Game:      game,
+		Amount:    amount,
+	}
+	if err := tx.Create(&win).Error; err != nil {
+		return nil, err
+	}
+
+	return &JackpotAward{Tier: pool.Tier, Amount: amount}, nil
+}
+
+// pools loads the pools, seeding any tier that does not exist yet
+func (s *JackpotService) pools(db *gorm.DB) ([]models.Jackpot, error) {
+	var pools []models.Jackpot
+	if err := db.Order("seed_value").Find(&pools).Error; err != nil {
+		return nil, err
+	}
+	if len(pools) >= len(defaultJackpots) {
+		return pools, nil
+	}
+
+	for _, jackpot := range defaultJackpots {
+		jackpot.Amount = jackpot.SeedValue
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&jackpot).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	pools = nil
+	err := db.Order("seed_value").Find(&pools).Error
+	return pools, err
+}
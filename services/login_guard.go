@@ -0,0 +1,195 @@
+package services
+
+import (
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Brute-force protection settings
+const (
+	MaxFailedLogins  = 5                // failures before the account is locked
+	LockoutDuration  = 15 * time.Minute // how long a locked account stays locked
+	baseLoginDelay   = 1 * time.Second  // delay after the first failure
+	maxLoginDelay    = 30 * time.Second // cap for the progressive delay
+	recentLoginLimit = 20
+)
+
+type LoginGuard struct {
+	db *gorm.DB
+}
+
+func NewLoginGuard(db *gorm.DB) *LoginGuard {
+	return &LoginGuard{db: db}
+}
+
+// LoginDelay returns how long a client must wait after the given number of
+// consecutive failures before the next attempt is evaluated (1s, 2s, 4s, ...)
+func LoginDelay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := baseLoginDelay << (failures - 1)
+	if delay > maxLoginDelay || delay <= 0 {
+		return maxLoginDelay
+	}
+	return delay
+}
+
+// LoginSubject is who a login attempt is for: the username tried, from
+// which IP, and the account with that username when there is one
+type LoginSubject struct {
+	OperatorID uint
+	Username   string
+	IP         string
+	User       *models.User // nil when no account has the username
+}
+
+// RetryAfter returns how long the client has to wait before trying again,
+// either because the account is locked or because of the progressive delay
+// of the username from this IP. Usernames no account has are locked and
+// delayed the same way, so the answer does not reveal which exist.
+// The returned reason is empty when an attempt is allowed.
+func (g *LoginGuard) RetryAfter(subject LoginSubject, now time.Time) (time.Duration, string) {
+	lockedUntil := g.throttle(subject.OperatorID, subject.Username, "").LockedUntil
+	if subject.User != nil {
+		lockedUntil = subject.User.LockedUntil
+	}
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		return lockedUntil.Sub(now), models.LoginReasonLocked
+	}
+
+	throttle := g.throttle(subject.OperatorID, subject.Username, subject.IP)
+	if throttle.LastFailedLoginAt != nil {
+		next := throttle.LastFailedLoginAt.Add(LoginDelay(throttle.FailedLoginAttempts))
+		if now.Before(next) {
+			return next.Sub(now), models.LoginReasonThrottled
+		}
+	}
+	return 0, ""
+}
+
+// RegisterFailure counts a failed attempt against the username from this IP
+// and against the account, or the username when there is no account, which
+// is locked once MaxFailedLogins is reached
+func (g *LoginGuard) RegisterFailure(subject LoginSubject, now time.Time) error {
+	if _, err := g.countFailure(g.throttleRow(subject.OperatorID, subject.Username, subject.IP), now, false); err != nil {
+		return err
+	}
+
+	account := g.throttleRow(subject.OperatorID, subject.Username, "")
+	if subject.User != nil {
+		userID := subject.User.ID
+		account = func() *gorm.DB { return g.db.Model(&models.User{}).Where("id = ?", userID) }
+	}
+	_, err := g.countFailure(account, now, true)
+	return err
+}
+
+// countFailure increments the failure counter of the row selected by query
+// in the database, so concurrent attempts are all counted, and returns the
+// new count. With lock set the row is locked once MaxFailedLogins is reached.
+func (g *LoginGuard) countFailure(query func() *gorm.DB, now time.Time, lock bool) (int, error) {
+	// A lock that expired, or failures older than a lockout, start a fresh series
+	err := query().Where("(locked_until IS NOT NULL AND locked_until <= ?) OR last_failed_login_at < ?", now, now.Add(-LockoutDuration)).
+		Updates(map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = query().Updates(map[string]interface{}{
+		"failed_login_attempts": gorm.Expr("failed_login_attempts + 1"),
+		"last_failed_login_at":  now,
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var failures int
+	if err := query().Select("failed_login_attempts").Scan(&failures).Error; err != nil {
+		return 0, err
+	}
+	if lock && failures >= MaxFailedLogins {
+		err = query().Where("locked_until IS NULL OR locked_until <= ?", now).Update("locked_until", now.Add(LockoutDuration)).Error
+	}
+	return failures, err
+}
+
+// throttleRow creates the throttle row of a username and IP if needed and
+// returns a query selecting it
+func (g *LoginGuard) throttleRow(operatorID uint, username, ip string) func() *gorm.DB {
+	g.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LoginThrottle{OperatorID: operatorID, Username: username, IP: ip})
+	return func() *gorm.DB {
+		return g.db.Model(&models.LoginThrottle{}).
+			Where("operator_id = ? AND username = ? AND ip = ?", operatorID, username, ip)
+	}
+}
+
+// throttle returns the throttle row of a username and IP, empty when there
+// is none
+func (g *LoginGuard) throttle(operatorID uint, username, ip string) models.LoginThrottle {
+	var throttle models.LoginThrottle
+	g.db.Where("operator_id = ? AND username = ? AND ip = ?", operatorID, username, ip).Limit(1).Find(&throttle)
+	return throttle
+}
+
+// RegisterSuccess clears the failure counters after a successful login
+func (g *LoginGuard) RegisterSuccess(subject LoginSubject) error {
+	err := g.db.Where("operator_id = ? AND username = ? AND ip = ?", subject.OperatorID, subject.Username, subject.IP).
+		Delete(&models.LoginThrottle{}).Error
+	if err != nil {
+		return err
+	}
+	user := subject.User
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil && user.LastFailedLoginAt == nil {
+		return nil
+	}
+	return g.clearUser(user)
+}
+
+// Lock locks an account until the given time
+func (g *LoginGuard) Lock(user *models.User, until time.Time) error {
+	user.LockedUntil = &until
+	return g.db.Model(user).Update("locked_until", until).Error
+}
+
+// Unlock clears the lockout and failure counters of an account, including
+// the delays of its username from every IP
+func (g *LoginGuard) Unlock(user *models.User) error {
+	err := g.db.Where("operator_id = ? AND username = ?", user.OperatorID, user.Username).
+		Delete(&models.LoginThrottle{}).Error
+	if err != nil {
+		return err
+	}
+	return g.clearUser(user)
+}
+
+func (g *LoginGuard) clearUser(user *models.User) error {
+	user.FailedLoginAttempts = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+
+	return g.db.Model(user).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+	}).Error
+}
+
+// Record stores a login attempt in the audit log
+func (g *LoginGuard) Record(attempt *models.LoginAttempt) error {
+	return g.db.Create(attempt).Error
+}
+
+// RecentLogins returns the latest login attempts for a user, newest first
+func (g *LoginGuard) RecentLogins(userID uint) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := g.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(recentLoginLimit).
+		Find(&attempts).Error
+	return attempts, err
+}
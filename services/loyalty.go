@@ -0,0 +1,337 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slot-sim/config"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QualifyingWindow is how far back earned points count towards the tier
+const QualifyingWindow = 90 * 24 * time.Hour
+
+// VIPTier is a tier threshold and its benefits
+type VIPTier struct {
+	Name               string  `json:"name"`
+	Threshold          float64 `json:"threshold"`            // qualifying points needed
+	CashbackRate       float64 `json:"cashback_rate"`        // share of weekly net losses paid back
+	DailyWithdrawLimit float64 `json:"daily_withdraw_limit"` // total withdrawals requested per day
+}
+
+// vipTiers, lowest first. Players move up as soon as their qualifying
+// points reach a threshold; the weekly review moves them down at most one
+// tier at a time when they no longer qualify.
+var vipTiers = []VIPTier{
+	{Name: models.TierBronze, Threshold: 0, CashbackRate: 0, DailyWithdrawLimit: 5000},
+	{Name: models.TierSilver, Threshold: 1000, CashbackRate: 0.05, DailyWithdrawLimit: 20000},
+	{Name: models.TierGold, Threshold: 10000, CashbackRate: 0.10, DailyWithdrawLimit: 100000},
+	{Name: models.TierPlatinum, Threshold: 50000, CashbackRate: 0.15, DailyWithdrawLimit: 500000},
+}
+
+var ErrWithdrawLimitExceeded = errors.New("daily withdrawal limit exceeded")
+
+// LoyaltyStatus is what the player sees of their loyalty account
+type LoyaltyStatus struct {
+	Tier             VIPTier   `json:"tier"`
+	TierSince        time.Time `json:"tier_since"`
+	Points           float64   `json:"points"`
+	QualifyingPoints float64   `json:"qualifying_points"`
+	NextTier         *VIPTier  `json:"next_tier,omitempty"`
+	PointsToNextTier float64   `json:"points_to_next_tier,omitempty"`
+}
+
+type LoyaltyService struct {
+	db     *gorm.DB
+	limits *ResponsibleGamingService
+}
+
+func NewLoyaltyService(db *gorm.DB) *LoyaltyService {
+	return &LoyaltyService{db: db, limits: NewResponsibleGamingService(db)}
+}
+
+// RecordSpin runs inside the spin's settlement transaction: it awards the
+// points for the stake and upgrades the tier when a threshold is reached
+func (s *LoyaltyService) RecordSpin(tx *gorm.DB, userID uint, game string, stake float64) error {
+	points := stake * config.LoyaltyPointRate(game)
+	if points <= 0 {
+		return nil
+	}
+
+	entry := models.LoyaltyPointEntry{UserID: userID, Game: game, Wagered: stake, Points: points}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	account, err := s.account(tx, userID)
+	if err != nil {
+		return err
+	}
+	err = tx.Model(account).Update("points", gorm.Expr("points + ?", points)).Error
+	if err != nil {
+		return err
+	}
+
+	qualifying, err := s.qualifyingPoints(tx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if earned := tierFor(qualifying); tierIndex(earned.Name) > tierIndex(account.Tier) {
+		return tx.Model(account).Updates(map[string]interface{}{"tier": earned.Name, "tier_since": time.Now()}).Error
+	}
+	return nil
+}
+
+// Status returns the player's tier, points and progress to the next tier
+func (s *LoyaltyService) Status(userID uint) (*LoyaltyStatus, error) {
+	account, err := s.account(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	qualifying, err := s.qualifyingPoints(s.db, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	status := &LoyaltyStatus{
+		Tier:             vipTiers[tierIndex(account.Tier)],
+		TierSince:        account.TierSince,
+		Points:           account.Points,
+		QualifyingPoints: qualifying,
+	}
+	if i := tierIndex(account.Tier) + 1; i < len(vipTiers) {
+		next := vipTiers[i]
+		status.NextTier = &next
+		status.PointsToNextTier = math.Max(0, next.Threshold-qualifying)
+	}
+	return status, nil
+}
+
+// CheckWithdraw refuses a withdrawal that would take the day's requested
+// withdrawals above the tier's limit
+func (s *LoyaltyService) CheckWithdraw(userID uint, amount float64) error {
+	account, err := s.account(s.db, userID)
+	if err != nil {
+		return err
+	}
+	limit := vipTiers[tierIndex(account.Tier)].DailyWithdrawLimit
+
+	var requested float64
+	err = s.db.Model(&models.Transaction{}).
+		Where("user_id = ? AND type = ? AND status <> ? AND created_at >= ?",
+			userID, models.TypeWithdraw, models.StatusRejected, PeriodStart(models.PeriodDaily, time.Now())).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&requested).Error
+	if err != nil {
+		return err
+	}
+
+	if requested+amount > limit {
+		return fmt.Errorf("%w: %s tier allows %.0f per day, %.0f remaining",
+			ErrWithdrawLimitExceeded, account.Tier, limit, math.Max(0, limit-requested))
+	}
+	return nil
+}
+
+// PayCashback pays the cashback for the last completed week to every player
+// with a net loss in it. Payouts are recorded per player and week, so
+// running it again for the same week pays nothing twice.
+func (s *LoyaltyService) PayCashback(now time.Time) (int, error) {
+	periodEnd := PeriodStart(models.PeriodWeekly, now)
+	periodStart := periodEnd.AddDate(0, 0, -7)
+
+	userIDs, err := s.activePlayers(periodStart, periodEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	paid := 0
+	for _, userID := range userIDs {
+		ok, err := s.payCashback(userID, periodStart, periodEnd)
+		if err != nil {
+			println("Failed to pay cashback to user", userID, err.Error())
+			continue
+		}
+		if ok {
+			paid++
+		}
+	}
+	return paid, nil
+}
+
+func (s *LoyaltyService) payCashback(userID uint, periodStart, periodEnd time.Time) (bool, error) {
+	account, err := s.account(s.db, userID)
+	if err != nil {
+		return false, err
+	}
+	tier := vipTiers[tierIndex(account.Tier)]
+	if tier.CashbackRate <= 0 {
+		return false, nil
+	}
+
+	wagered, won, err := s.limits.playTotalsBetween(userID, periodStart, periodEnd)
+	if err != nil {
+		return false, err
+	}
+	netLoss := wagered - won
+	amount := int(math.Floor(netLoss * tier.CashbackRate))
+	if amount <= 0 {
+		return false, nil
+	}
+
+	paid := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		payout := models.CashbackPayout{
+			UserID:      userID,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Tier:        tier.Name,
+			NetLoss:     netLoss,
+			Rate:        tier.CashbackRate,
+			Amount:      amount,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&payout)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error // already paid for this week
+		}
+
+		reason := fmt.Sprintf("Weekly cashback %s-%s (%s, %.0f%% of %.2f net loss)",
+			periodStart.Format("2006-01-02"), periodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+			tier.Name, tier.CashbackRate*100, netLoss)
+		adjustment, err := AdjustBalance(tx, userID, amount, reason, "cashback")
+		if err != nil {
+			return err
+		}
+
+		paid = true
+		return tx.Model(&payout).Update("adjustment_id", adjustment.ID).Error
+	})
+	if paid && err == nil {
+		PublishBalance(s.db, userID, "cashback")
+	}
+	return paid, err
+}
+
+// ReviewTiers moves players who no longer reach their tier's threshold down
+// one tier. Each account is reviewed at most once a week.
+func (s *LoyaltyService) ReviewTiers(now time.Time) (int, error) {
+	weekStart := PeriodStart(models.PeriodWeekly, now)
+
+	var accounts []models.LoyaltyAccount
+	err := s.db.Where("tier <> ?", models.TierBronze).
+		Where("last_review_at IS NULL OR last_review_at < ?", weekStart).
+		Find(&accounts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	downgraded := 0
+	for _, account := range accounts {
+		updates := map[string]interface{}{"last_review_at": now}
+
+		// A tier reached during the week is kept until the next review
+		current := tierIndex(account.Tier)
+		if account.TierSince.Before(weekStart) {
+			qualifying, err := s.qualifyingPoints(s.db, account.UserID, now)
+			if err != nil {
+				return downgraded, err
+			}
+			if qualifying < vipTiers[current].Threshold {
+				updates["tier"] = vipTiers[current-1].Name
+				updates["tier_since"] = now
+				downgraded++
+			}
+		}
+
+		if err := s.db.Model(&account).Updates(updates).Error; err != nil {
+			return downgraded, err
+		}
+	}
+	return downgraded, nil
+}
+
+// RunScheduler runs the weekly cashback and tier review. Both are safe to
+// repeat, so it simply runs them on every tick. It blocks, run it in its
+// own goroutine.
+func (s *LoyaltyService) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if _, err := s.PayCashback(now); err != nil {
+			println("Failed to pay cashback:", err.Error())
+		}
+		if _, err := s.ReviewTiers(now); err != nil {
+			println("Failed to review VIP tiers:", err.Error())
+		}
+		<-ticker.C
+	}
+}
+
+// account returns the player's loyalty account, opening it at bronze
+func (s *LoyaltyService) account(db *gorm.DB, userID uint) (*models.LoyaltyAccount, error) {
+	account := models.LoyaltyAccount{UserID: userID, Tier: models.TierBronze, TierSince: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&account).Error; err != nil {
+		return nil, err
+	}
+	err := db.Where("user_id = ?", userID).First(&account).Error
+	return &account, err
+}
+
+func (s *LoyaltyService) qualifyingPoints(db *gorm.DB, userID uint, now time.Time) (float64, error) {
+	var points float64
+	err := db.Model(&models.LoyaltyPointEntry{}).
+		Where("user_id = ? AND created_at >= ?", userID, now.Add(-QualifyingWindow)).
+		Select("COALESCE(SUM(points), 0)").
+		Scan(&points).Error
+	return points, err
+}
+
+// activePlayers returns the players who played in the period
+func (s *LoyaltyService) activePlayers(from, to time.Time) ([]uint, error) {
+	seen := map[uint]bool{}
+	var userIDs []uint
+
+	for _, model := range []interface{}{&models.Gamelog{}, &models.MythicSession{}} {
+		var ids []uint
+		err := s.db.Model(model).
+			Where("created_at >= ? AND created_at < ?", from, to).
+			Distinct("user_id").
+			Pluck("user_id", &ids).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+// tierFor returns the highest tier the qualifying points reach
+func tierFor(points float64) VIPTier {
+	tier := vipTiers[0]
+	for _, t := range vipTiers {
+		if points >= t.Threshold {
+			tier = t
+		}
+	}
+	return tier
+}
+
+func tierIndex(name string) int {
+	for i, tier := range vipTiers {
+		if tier.Name == name {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrMissionNotFound     = errors.New("mission not found")
+	ErrMissionNotCompleted = errors.New("mission is not completed")
+	ErrMissionClaimed      = errors.New("mission reward already claimed")
+)
+
+// defaultMissions seed the missions table the first time it is needed.
+// Rules can be tuned or deactivated afterwards in the table.
+var defaultMissions = []models.Mission{
+	{
+		Code: "daily_tumble_chains", Title: "Chain Reaction", Description: "Trigger 3 tumble chains of 5+",
+		Game: GameMythic, Metric: models.MetricTumbleChain, MinValue: 5, Target: 3,
+		Period: models.MissionDaily, RewardType: models.RewardBalance, RewardAmount: 50,
+	},
+	{
+		Code: "daily_fortune_spins", Title: "Gem Collector", Description: "Spin Fortune Gems 50 times today",
+		Game: GameFortuneGems, Metric: models.MetricSpins, Target: 50,
+		Period: models.MissionDaily, RewardType: models.RewardBalance, RewardAmount: 100,
+	},
+	{
+		Code: "weekly_scatters", Title: "Storm Chaser", Description: "Land 4 scatters in one spin",
+		Game: GameMythic, Metric: models.MetricScatters, MinValue: 4, Target: 1,
+		Period: models.MissionWeekly, RewardType: models.RewardFreeSpins, RewardAmount: 10, RewardBet: 10,
+	},
+	{
+		Code: "weekly_wager", Title: "High Roller", Description: "Wager 10,000 this week",
+		Metric: models.MetricWagered, Target: 10000,
+		Period: models.MissionWeekly, RewardType: models.RewardBalance, RewardAmount: 250,
+	},
+	{
+		Code: "achievement_big_win", Title: "Big Winner", Description: "Win 50x your bet in a single spin",
+		Metric: models.MetricWinMultiple, MinValue: 50, Target: 1,
+		Period: models.MissionPermanent, RewardType: models.RewardNone, Badge: "big_winner",
+	},
+	{
+		Code: "achievement_veteran", Title: "Veteran", Description: "Play 1,000 spins",
+		Metric: models.MetricSpins, Target: 1000,
+		Period: models.MissionPermanent, RewardType: models.RewardBalance, RewardAmount: 500, Badge: "veteran",
+	},
+	{
+		Code: "achievement_storm_caller", Title: "Storm Caller", Description: "Land 6 scatters in one spin",
+		Game: GameMythic, Metric: models.MetricScatters, MinValue: 6, Target: 1,
+		Period: models.MissionPermanent, RewardType: models.RewardNone, Badge: "storm_caller",
+	},
+	{
+		Code: "achievement_jackpot", Title: "Jackpot Hunter", Description: "Win any jackpot",
+		Metric: models.MetricJackpot, Target: 1,
+		Period: models.MissionPermanent, RewardType: models.RewardNone, Badge: "jackpot_hunter",
+	},
+}
+
+// SpinEvent is what missions see of a settled spin
+type SpinEvent struct {
+	UserID   uint
+	Game     string
+	Stake    float64 // amount debited
+	Bet      float64 // bet that wins are paid on
+	Win      float64
+	Tumbles  int
+	Scatters int
+	Feature  bool
+	Jackpot  bool
+}
+
+// MissionStatus is a mission with the player's progress in the current period
+type MissionStatus struct {
+	models.Mission
+	Progress    float64    `json:"progress"`
+	ResetsAt    *time.Time `json:"resets_at,omitempty"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Claimed     bool       `json:"claimed"`
+}
+
+// Badge is an achievement shown on the profile
+type Badge struct {
+	Code     string    `json:"code"`
+	Title    string    `json:"title"`
+	Badge    string    `json:"badge"`
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+type MissionService struct {
+	db *gorm.DB
+}
+
+func NewMissionService(db *gorm.DB) *MissionService {
+	return &MissionService{db: db}
+}
+
+// RecordSpin runs inside the spin's settlement transaction and advances
+// every mission the spin counts towards. It returns the missions the spin
+// completed.
+func (s *MissionService) RecordSpin(tx *gorm.DB, event SpinEvent) ([]string, error) {
+	missions, err := s.missions(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var completed []string
+	for _, mission := range missions {
+		increment := missionIncrement(mission, event)
+		if increment <= 0 {
+			continue
+		}
+
+		progress, err := s.progress(tx, event.UserID, mission, now)
+		if err != nil {
+			return nil, err
+		}
+		if progress.CompletedAt != nil {
+			continue
+		}
+
+		progress.Progress += increment
+		updates := map[string]interface{}{"progress": gorm.Expr("progress + ?", increment)}
+		if progress.Progress >= mission.Target {
+			updates["completed_at"] = now
+			completed = append(completed, mission.Title)
+		}
+
+		err = tx.Model(&models.MissionProgress{}).
+			Where("id = ? AND completed_at IS NULL", progress.ID).
+			Updates(updates).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return completed, nil
+}
+
+// List returns the active missions and achievements with the player's
+// progress in the current period
+func (s *MissionService) List(userID uint) ([]MissionStatus, error) {
+	missions, err := s.missions(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]MissionStatus, 0, len(missions))
+	for _, mission := range missions {
+		status := MissionStatus{Mission: mission}
+		if mission.Period != models.MissionPermanent {
+			resetsAt := PeriodEnd(models.LimitPeriod(mission.Period), now)
+			status.ResetsAt = &resetsAt
+		}
+
+		var progress models.MissionProgress
+		result := s.db.Where("user_id = ? AND mission_id = ? AND period_start = ?",
+			userID, mission.ID, missionPeriodStart(mission, now)).Limit(1).Find(&progress)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			status.Progress = progress.Progress
+			status.Completed = progress.CompletedAt != nil
+			status.CompletedAt = progress.CompletedAt
+			status.Claimed = progress.ClaimedAt != nil
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Claim pays the reward of a mission completed in the current period
+func (s *MissionService) Claim(userID, missionID uint) (*MissionStatus, error) {
+	var mission models.Mission
+	if err := s.db.Where("id = ? AND active = ?", missionID, true).First(&mission).Error; err != nil {
+		return nil, ErrMissionNotFound
+	}
+
+	now := time.Now()
+	var progress models.MissionProgress
+	var pack *models.FreeSpinPack
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND mission_id = ? AND period_start = ?",
+			userID, mission.ID, missionPeriodStart(mission, now)).Limit(1).Find(&progress)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 || progress.CompletedAt == nil {
+			return ErrMissionNotCompleted
+		}
+
+		// The conditional update makes a double claim impossible
+		result = tx.Model(&models.MissionProgress{}).
+			Where("id = ? AND claimed_at IS NULL", progress.ID).
+			Update("claimed_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMissionClaimed
+		}
+		progress.ClaimedAt = &now
+
+		source := "mission:" + mission.Code
+		switch mission.RewardType {
+		case models.RewardBalance:
+			_, err := AdjustBalance(tx, userID, mission.RewardAmount, fmt.Sprintf("Mission reward: %s", mission.Title), source)
+			return err
+		case models.RewardFreeSpins:
+			var err error
+			pack, err = GrantFreeSpins(tx, userID, mission.RewardAmount, mission.RewardBet, source, nil)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case pack != nil:
+		PublishFreeSpins(pack)
+	case mission.RewardType == models.RewardBalance:
+		PublishBalance(s.db, userID, "mission_reward")
+	}
+
+	return &MissionStatus{
+		Mission:     mission,
+		Progress:    progress.Progress,
+		Completed:   true,
+		CompletedAt: progress.CompletedAt,
+		Claimed:     true,
+	}, nil
+}
+
+// Badges returns the achievements the player has earned
+func (s *MissionService) Badges(userID uint) ([]Badge, error) {
+	badges := []Badge{}
+	err := s.db.Table("mission_progress").
+		Select("missions.code, missions.title, missions.badge, mission_progress.completed_at AS earned_at").
+		Joins("JOIN missions ON missions.id = mission_progress.mission_id").
+		Where("mission_progress.user_id = ? AND mission_progress.completed_at IS NOT NULL", userID).
+		Where("missions.period = ? AND missions.badge <> ''", models.MissionPermanent).
+		Order("mission_progress.completed_at").
+		Scan(&badges).Error
+	return badges, err
+}
+
+// progress returns the player's progress row for the current period,
+// creating it on the first counting spin
+func (s *MissionService) progress(tx *gorm.DB, userID uint, mission models.Mission, now time.Time) (*models.MissionProgress, error) {
+	progress := models.MissionProgress{
+		UserID:      userID,
+		MissionID:   mission.ID,
+		PeriodStart: missionPeriodStart(mission, now),
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&progress).Error; err != nil {
+		return nil, err
+	}
+
+	err := tx.Where("user_id = ? AND mission_id = ? AND period_start = ?",
+		userID, mission.ID, progress.PeriodStart).First(&progress).Error
+	return &progress, err
+}
+
+// missions loads the active missions, seeding any default that is missing
+func (s *MissionService) missions(db *gorm.DB) ([]models.Mission, error) {
+	var count int64
+	if err := db.Model(&models.Mission{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count < int64(len(defaultMissions)) {
+		for _, mission := range defaultMissions {
+			mission.Active = true
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mission).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var missions []models.Mission
+	err := db.Where("active = ?", true).Order("id").Find(&missions).Error
+	return missions, err
+}
+
+// missionPeriodStart returns the start of the mission's current period.
+// Achievements have a single period.
+func missionPeriodStart(mission models.Mission, now time.Time) time.Time {
+	if mission.Period == models.MissionPermanent {
+		return time.Time{}
+	}
+	return PeriodStart(models.LimitPeriod(mission.Period), now)
+}
+
+// missionIncrement returns how much a spin advances a mission
+func missionIncrement(mission models.Mission, event SpinEvent) float64 {
+	if mission.Game != "" && mission.Game != event.Game {
+		return 0
+	}
+
+	counts := false
+	switch mission.Metric {
+	case models.MetricSpins:
+		return 1
+	case models.MetricWagered:
+		return event.Stake
+	case models.MetricTumbleChain:
+		counts = event.Tumbles > 0 && float64(event.Tumbles) >= mission.MinValue
+	case models.MetricScatters:
+		counts = event.Scatters > 0 && float64(event.Scatters) >= mission.MinValue
+	case models.MetricWinMultiple:
+		counts = event.Bet > 0 && event.Win > 0 && event.Win/event.Bet >= mission.MinValue
+	case models.MetricFeature:
+		counts = event.Feature
+	case models.MetricJackpot:
+		counts = event.Jackpot
+	}
+
+	if counts {
+		return 1
+	}
+	return 0
+}
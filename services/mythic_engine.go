@@ -23,6 +23,19 @@ const (
 	SCATTER = "SCATTER" // Special
 )
 
+// Symbol weight profiles, selected per spin
+const (
+	WeightsBase = "base"
+	WeightsAnte = "ante" // ante bet: doubled scatter weight
+)
+
+// AnteBetMultiplier is the stake of an ante spin as a multiple of the bet
+const AnteBetMultiplier = 1.25
+
+// mythicSymbols fixes the order symbols are drawn in, so a seeded engine
+// always draws the same symbols
+var mythicSymbols = []string{ZEUS, CROWN, TRIDENT, EAGLE, VASE, FIRE, GEM, SWORD, ACE, KING, QUEEN, JACK, SCATTER}
+
 // Symbol weights for random generation
 var symbolWeights = map[string]int{
 	ZEUS:    2,
@@ -40,6 +53,35 @@ var symbolWeights = map[string]int{
 	SCATTER: 2,
 }
 
+var anteSymbolWeights = map[string]int{
+	ZEUS:    2,
+	CROWN:   3,
+	TRIDENT: 4,
+	EAGLE:   5,
+	VASE:    6,
+	FIRE:    8,
+	GEM:     10,
+	SWORD:   12,
+	ACE:     14,
+	KING:    14,
+	QUEEN:   14,
+	JACK:    14,
+	SCATTER: 4,
+}
+
+var weightProfiles = map[string]map[string]int{
+	WeightsBase: symbolWeights,
+	WeightsAnte: anteSymbolWeights,
+}
+
+// weightsFor returns the weights of a profile, the base weights when unknown
+func weightsFor(profile string) map[string]int {
+	if weights, ok := weightProfiles[profile]; ok {
+		return weights
+	}
+	return symbolWeights
+}
+
 // Paytable: symbol -> cluster size -> multiplier
 var paytable = map[string]map[int]float64{
 	ZEUS:    {6: 500, 5: 200, 4: 100, 3: 50},
@@ -66,34 +108,43 @@ type MythicEngine struct {
 
 func NewMythicEngine() *MythicEngine {
 	return &MythicEngine{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng: newLockedRand(time.Now().UnixNano()),
+	}
+}
+
+// NewSeededMythicEngine returns an engine whose spins the seed determines:
+// the same seed and sequence of calls always play the same spins
+func NewSeededMythicEngine(seed int64) *MythicEngine {
+	return &MythicEngine{
+		rng: newLockedRand(seed),
 	}
 }
 
-// GenerateGrid creates a 6x5 grid with weighted random symbols
-func (e *MythicEngine) GenerateGrid() [][]string {
+// GenerateGrid creates a 6x5 grid with symbols drawn from a weight profile
+func (e *MythicEngine) GenerateGrid(profile string) [][]string {
+	weights := weightsFor(profile)
 	grid := make([][]string, 5) // 5 rows
 	for i := range grid {
 		grid[i] = make([]string, 6) // 6 columns
 		for j := range grid[i] {
-			grid[i][j] = e.getRandomSymbol()
+			grid[i][j] = e.getRandomSymbol(weights)
 		}
 	}
 	return grid
 }
 
 // getRandomSymbol returns a weighted random symbol
-func (e *MythicEngine) getRandomSymbol() string {
+func (e *MythicEngine) getRandomSymbol(weights map[string]int) string {
 	totalWeight := 0
-	for _, weight := range symbolWeights {
+	for _, weight := range weights {
 		totalWeight += weight
 	}
 
 	randomValue := e.rng.Intn(totalWeight)
 	currentWeight := 0
 
-	for symbol, weight := range symbolWeights {
-		currentWeight += weight
+	for _, symbol := range mythicSymbols {
+		currentWeight += weights[symbol]
 		if randomValue < currentWeight {
 			return symbol
 		}
@@ -103,13 +154,14 @@ func (e *MythicEngine) getRandomSymbol() string {
 }
 
 // FillEmptyPositions fills EMPTY cells with new random symbols
-func (e *MythicEngine) FillEmptyPositions(grid [][]string) [][]string {
+func (e *MythicEngine) FillEmptyPositions(grid [][]string, profile string) [][]string {
+	weights := weightsFor(profile)
 	newGrid := make([][]string, len(grid))
 	for i := range grid {
 		newGrid[i] = make([]string, len(grid[i]))
 		for j := range grid[i] {
 			if grid[i][j] == "EMPTY" {
-				newGrid[i][j] = e.getRandomSymbol()
+				newGrid[i][j] = e.getRandomSymbol(weights)
 			} else {
 				newGrid[i][j] = grid[i][j]
 			}
@@ -196,14 +248,16 @@ func (e *MythicEngine) CountScatters(grid [][]string) int {
 
 // ProcessTumble handles one complete tumble cycle
 type TumbleResult struct {
-	Grid       [][]string      `json:"grid"`
-	Clusters   []utils.Cluster `json:"clusters"`
-	Win        float64         `json:"win"`
-	HasWins    bool            `json:"has_wins"`
-	Multiplier float64         `json:"multiplier"`
+	Grid       [][]string       `json:"grid"`
+	Clusters   []utils.Cluster  `json:"clusters"`
+	Win        float64          `json:"win"`
+	HasWins    bool             `json:"has_wins"`
+	Multiplier float64          `json:"multiplier"`
+	Strikes    []float64        `json:"strikes,omitempty"`  // lightning multipliers that struck
+	Refilled   []utils.Position `json:"refilled,omitempty"` // positions filled with new symbols
 }
 
-func (e *MythicEngine) ProcessTumble(grid [][]string, bet float64, isFreeSpin bool) TumbleResult {
+func (e *MythicEngine) ProcessTumble(grid [][]string, bet float64, isFreeSpin bool, profile string) TumbleResult {
 	// Detect clusters
 	clusters := utils.DetectClusters(grid)
 
@@ -239,9 +293,10 @@ func (e *MythicEngine) ProcessTumble(grid [][]string, bet float64, isFreeSpin bo
 
 	// Apply gravity
 	newGrid = utils.ApplyGravity(newGrid)
+	refilled := emptyPositions(newGrid)
 
 	// Fill empty positions
-	newGrid = e.FillEmptyPositions(newGrid)
+	newGrid = e.FillEmptyPositions(newGrid, profile)
 
 	return TumbleResult{
 		Grid:       newGrid,
@@ -249,5 +304,163 @@ func (e *MythicEngine) ProcessTumble(grid [][]string, bet float64, isFreeSpin bo
 		Win:        win,
 		HasWins:    true,
 		Multiplier: totalMultiplier,
+		Strikes:    multipliers,
+		Refilled:   refilled,
+	}
+}
+
+func emptyPositions(grid [][]string) []utils.Position {
+	var positions []utils.Position
+	for row := range grid {
+		for col := range grid[row] {
+			if grid[row][col] == "EMPTY" {
+				positions = append(positions, utils.Position{Row: row, Col: col})
+			}
+		}
+	}
+	return positions
+}
+
+// Scatters placed on the initial grid of a bought feature, the minimum
+// that triggers free spins
+const featureBuyScatters = 4
+
+// GenerateFeatureGrid creates a regular grid with the given number of
+// scatters landed on distinct random positions
+func (e *MythicEngine) GenerateFeatureGrid(scatters int) [][]string {
+	grid := e.GenerateGrid(WeightsBase)
+	rows, cols := len(grid), len(grid[0])
+
+	for _, pos := range e.rng.Perm(rows * cols) {
+		if e.CountScatters(grid) >= scatters {
+			break
+		}
+		if grid[pos/cols][pos%cols] != SCATTER {
+			grid[pos/cols][pos%cols] = SCATTER
+		}
+	}
+	return grid
+}
+
+// MythicOutcome is the result of one Mythic Lightning spin before settlement
+type MythicOutcome struct {
+	InitialGrid      [][]string      `json:"initial_grid"` // grid before the first tumble
+	Grid             [][]string      `json:"grid"`
+	Tumbles          []TumbleResult  `json:"tumbles"`
+	BaseWin          float64         `json:"base_win"`
+	TotalMultiplier  float64         `json:"total_multiplier"`
+	ScatterCount     int             `json:"scatter_count"`
+	ScatterWin       float64         `json:"scatter_win"`
+	FreeSpinsAwarded int             `json:"free_spins_awarded"`
+	AnteBet          bool            `json:"ante_bet"`
+	WeightProfile    string          `json:"weight_profile"`
+	FeatureBuy       bool            `json:"feature_buy"`
+	FreeSpins        []MythicOutcome `json:"free_spins,omitempty"` // played straight away on a feature buy
+	FreeSpinsWin     float64         `json:"free_spins_win,omitempty"`
+	TotalWin         float64         `json:"total_win"`
+}
+
+// Spin plays a complete spin: the initial grid, tumbles until no cluster
+// wins remain, the accumulated multiplier and the scatter check. An ante
+// spin draws from the ante weights; wins are still paid on the bet.
+func (e *MythicEngine) Spin(bet float64, ante bool) MythicOutcome {
+	profile := WeightsBase
+	if ante {
+		profile = WeightsAnte
+	}
+
+	outcome := e.resolve(e.GenerateGrid(profile), bet, false, profile)
+	outcome.AnteBet = ante
+	return outcome
+}
+
+// BuyFeature plays a bought bonus: a trigger spin with scatters guaranteed
+// on the initial grid, followed by all the free spins it awards
+func (e *MythicEngine) BuyFeature(bet float64) MythicOutcome {
+	grid := e.GenerateFeatureGrid(featureBuyScatters)
+	initialScatters := e.CountScatters(grid)
+
+	outcome := e.resolve(grid, bet, false, WeightsBase)
+	outcome.FeatureBuy = true
+
+	// Tumbles can clear scatters, the trigger is what landed initially
+	if outcome.ScatterCount < initialScatters {
+		outcome.TotalWin -= outcome.ScatterWin
+		outcome.ScatterCount = initialScatters
+		outcome.FreeSpinsAwarded, outcome.ScatterWin = scatterAward(initialScatters, bet)
+		outcome.TotalWin += outcome.ScatterWin
+	}
+
+	for i := 0; i < outcome.FreeSpinsAwarded; i++ {
+		freeSpin := e.resolve(e.GenerateGrid(WeightsBase), bet, true, WeightsBase)
+		outcome.FreeSpins = append(outcome.FreeSpins, freeSpin)
+		outcome.FreeSpinsWin += freeSpin.TotalWin
+	}
+	outcome.TotalWin += outcome.FreeSpinsWin
+
+	return outcome
+}
+
+// resolve plays out a grid: tumbles, the accumulated multiplier and, outside
+// free spins, the scatter check
+func (e *MythicEngine) resolve(grid [][]string, bet float64, isFreeSpin bool, profile string) MythicOutcome {
+	initialGrid := grid
+
+	// Process tumbles until no more wins
+	var tumbles []TumbleResult
+	totalWin := 0.0
+	totalMultiplier := 1.0
+
+	maxTumbles := 20 // Safety limit
+	for i := 0; i < maxTumbles; i++ {
+		tumbleResult := e.ProcessTumble(grid, bet, isFreeSpin, profile)
+
+		if !tumbleResult.HasWins {
+			break
+		}
+
+		tumbles = append(tumbles, tumbleResult)
+		totalWin += tumbleResult.Win
+		totalMultiplier += (tumbleResult.Multiplier - 1) // Accumulate bonus multipliers
+		grid = tumbleResult.Grid
+	}
+
+	// Apply total multiplier to total win
+	finalWin := totalWin * totalMultiplier
+
+	// Check for scatters (free spins trigger)
+	scatterCount := e.CountScatters(grid)
+	freeSpinsAwarded := 0
+	scatterWin := 0.0
+
+	if !isFreeSpin {
+		freeSpinsAwarded, scatterWin = scatterAward(scatterCount, bet)
+		finalWin += scatterWin
+	}
+
+	return MythicOutcome{
+		InitialGrid:      initialGrid,
+		Grid:             grid,
+		Tumbles:          tumbles,
+		BaseWin:          totalWin,
+		TotalMultiplier:  totalMultiplier,
+		ScatterCount:     scatterCount,
+		ScatterWin:       scatterWin,
+		FreeSpinsAwarded: freeSpinsAwarded,
+		WeightProfile:    profile,
+		TotalWin:         finalWin,
+	}
+}
+
+// scatterAward returns the free spins and scatter win for a scatter count
+func scatterAward(scatterCount int, bet float64) (int, float64) {
+	switch {
+	case scatterCount >= 6:
+		return 20, bet * 10
+	case scatterCount == 5:
+		return 15, bet * 5
+	case scatterCount == 4:
+		return 10, bet * 2
 	}
+	return 0, 0
 }
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"slot-sim/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOperatorNotFound = errors.New("operator not found")
+	ErrOperatorInactive = errors.New("operator is not active")
+	ErrInvalidOperator  = errors.New("invalid operator")
+	ErrInvalidAPIKey    = errors.New("invalid API key")
+	ErrAPIKeyNotFound   = errors.New("API key not found")
+	ErrGameUnavailable  = errors.New("game is not offered by this operator")
+	ErrBetOutOfRange    = errors.New("bet is outside the operator's limits")
+)
+
+// BetLimitError is a bet outside the operator's limits
+type BetLimitError struct {
+	Limit string // e.g. "must be at least 20"
+}
+
+func (e *BetLimitError) Error() string { return "bet " + e.Limit }
+
+func (e *BetLimitError) Unwrap() error { return ErrBetOutOfRange }
+
+var (
+	operatorCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,31}$`)
+	currencyPattern     = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// How often the last use of an API key is written back
+const apiKeyTouchInterval = time.Minute
+
+// OperatorInput is what a platform admin configures for an operator. The
+// code cannot be changed once the operator exists.
+type OperatorInput struct {
+	Code           string
+	Name           string
+	Host           string
+	Currency       string
+	Games          []string
+	FortuneMinBet  int
+	FortuneMaxBet  int
+	MythicMinBet   float64
+	MythicMaxBet   float64
+	Branding       map[string]string
+	WalletURL      string // empty to hold the players' balances here
+	WalletSecret   string // empty to keep the current one
+	FeatureBuy     bool
+	FeatureBuyCost float64 // 0 for the deployment's price
+	Active         bool
+}
+
+type OperatorService struct {
+	db *gorm.DB
+}
+
+func NewOperatorService(db *gorm.DB) *OperatorService {
+	return &OperatorService{db: db}
+}
+
+// Resolve finds the operator a request is for: the owner of the API key when
+// one is sent, else the operator with the code, else the one served on the
+// host, else the default operator
+func (s *OperatorService) Resolve(apiKey, code, host string) (*models.Operator, error) {
+	var operator models.Operator
+	switch {
+	case apiKey != "":
+		key, err := s.authenticate(apiKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.First(&operator, key.OperatorID).Error; err != nil {
+			return nil, ErrInvalidAPIKey
+		}
+	case code != "":
+		if err := s.db.Where("code = ?", strings.ToLower(code)).First(&operator).Error; err != nil {
+			return nil, ErrOperatorNotFound
+		}
+	default:
+		host = normalizeHost(host)
+		result := s.db.Where("host = ? AND host <> ''", host).Limit(1).Find(&operator)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return s.Default()
+		}
+	}
+
+	if !operator.Active {
+		return nil, ErrOperatorInactive
+	}
+	return &operator, nil
+}
+
+// Default returns the operator that runs the deployment
+func (s *OperatorService) Default() (*models.Operator, error) {
+	var operator models.Operator
+	if err := s.db.Where("code = ?", models.DefaultOperatorCode).First(&operator).Error; err != nil {
+		return nil, err
+	}
+	return &operator, nil
+}
+
+// authenticate looks up an unrevoked API key
+func (s *OperatorService) authenticate(apiKey string) (*models.OperatorAPIKey, error) {
+	var key models.OperatorAPIKey
+	if err := s.db.Where("key_hash = ? AND revoked_at IS NULL", hashAPIKey(apiKey)).First(&key).Error; err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		s.db.Model(&key).Update("last_used_at", now)
+	}
+	return &key, nil
+}
+
+// List returns every operator, the default operator first
+func (s *OperatorService) List() ([]models.Operator, error) {
+	var operators []models.Operator
+	err := s.db.Order("id").Find(&operators).Error
+	return operators, err
+}
+
+// Get returns one operator
+func (s *OperatorService) Get(operatorID uint) (*models.Operator, error) {
+	var operator models.Operator
+	if err := s.db.First(&operator, operatorID).Error; err != nil {
+		return nil, ErrOperatorNotFound
+	}
+	return &operator, nil
+}
+
+// Create validates and stores a new operator
+func (s *OperatorService) Create(input OperatorInput) (*models.Operator, error) {
+	input.Code = strings.ToLower(strings.TrimSpace(input.Code))
+	if !operatorCodePattern.MatchString(input.Code) {
+		return nil, fmt.Errorf("%w: code must be 2 to 32 lowercase letters, digits or dashes", ErrInvalidOperator)
+	}
+
+	operator := &models.Operator{Code: input.Code}
+	if err := s.apply(operator, input); err != nil {
+		return nil, err
+	}
+	active := operator.Active
+	if err := s.db.Create(operator).Error; err != nil {
+		return nil, fmt.Errorf("%w: code %s already exists", ErrInvalidOperator, input.Code)
+	}
+	// Create leaves a false Active to the column default, which is true
+	if !active {
+		if err := s.db.Model(operator).Update("active", false).Error; err != nil {
+			return nil, err
+		}
+	}
+	return operator, nil
+}
+
+// Update replaces the configuration of an operator. The default operator
+// cannot be deactivated.
+func (s *OperatorService) Update(operatorID uint, input OperatorInput) (*models.Operator, error) {
+	operator, err := s.Get(operatorID)
+	if err != nil {
+		return nil, err
+	}
+	if operator.Code == models.DefaultOperatorCode && !input.Active {
+		return nil, fmt.Errorf("%w: the default operator cannot be deactivated", ErrInvalidOperator)
+	}
+
+	if err := s.apply(operator, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(operator).Error; err != nil {
+		return nil, err
+	}
+	return operator, nil
+}
+
+// apply validates the configuration and copies it onto the operator
+func (s *OperatorService) apply(operator *models.Operator, input OperatorInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Host = normalizeHost(input.Host)
+	input.Currency = strings.ToUpper(strings.TrimSpace(input.Currency))
+	if input.Currency == "" {
+		input.Currency = "USD"
+	}
+	input.WalletURL = strings.TrimSpace(input.WalletURL)
+	if input.WalletSecret == "" {
+		input.WalletSecret = operator.WalletSecret
+	}
+	if err := validateOperator(input); err != nil {
+		return err
+	}
+
+	if input.Host != "" {
+		var taken int64
+		s.db.Model(&models.Operator{}).Where("host = ? AND id <> ?", input.Host, operator.ID).Count(&taken)
+		if taken > 0 {
+			return fmt.Errorf("%w: host %s is already served by another operator", ErrInvalidOperator, input.Host)
+		}
+	}
+
+	operator.Name = input.Name
+	operator.Host = input.Host
+	operator.Currency = input.Currency
+	operator.Games = input.Games
+	operator.FortuneMinBet = input.FortuneMinBet
+	operator.FortuneMaxBet = input.FortuneMaxBet
+	operator.MythicMinBet = input.MythicMinBet
+	operator.MythicMaxBet = input.MythicMaxBet
+	operator.Branding = input.Branding
+	operator.WalletURL = input.WalletURL
+	operator.WalletSecret = input.WalletSecret
+	operator.FeatureBuy = input.FeatureBuy
+	operator.FeatureBuyCost = input.FeatureBuyCost
+	operator.Active = input.Active
+	return nil
+}
+
+func validateOperator(input OperatorInput) error {
+	switch {
+	case input.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidOperator)
+	case !currencyPattern.MatchString(input.Currency):
+		return fmt.Errorf("%w: currency must be a three-letter ISO 4217 code", ErrInvalidOperator)
+	case input.FortuneMinBet < 0 || input.FortuneMaxBet < 0 || input.MythicMinBet < 0 || input.MythicMaxBet < 0:
+		return fmt.Errorf("%w: bet limits cannot be negative", ErrInvalidOperator)
+	case input.FeatureBuyCost < 0:
+		return fmt.Errorf("%w: feature buy cost cannot be negative", ErrInvalidOperator)
+	case input.FortuneMinBet != 0 && (input.FortuneMinBet < fortuneMinBet || input.FortuneMinBet > fortuneMaxBet),
+		input.FortuneMaxBet != 0 && (input.FortuneMaxBet < fortuneMinBet || input.FortuneMaxBet > fortuneMaxBet):
+		return fmt.Errorf("%w: Fortune Gems bet limits must be between %d and %d", ErrInvalidOperator, fortuneMinBet, fortuneMaxBet)
+	case input.FortuneMaxBet != 0 && input.FortuneMinBet > input.FortuneMaxBet,
+		input.MythicMaxBet != 0 && input.MythicMinBet > input.MythicMaxBet:
+		return fmt.Errorf("%w: minimum bet cannot exceed maximum bet", ErrInvalidOperator)
+	}
+	if input.WalletURL != "" {
+		u, err := url.Parse(input.WalletURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: wallet URL must be an absolute http or https URL", ErrInvalidOperator)
+		}
+		if input.WalletSecret == "" {
+			return fmt.Errorf("%w: a wallet secret is required with a wallet URL", ErrInvalidOperator)
+		}
+	}
+	for _, game := range input.Games {
+		if game != GameFortuneGems && game != GameMythic {
+			return fmt.Errorf("%w: unknown game %q", ErrInvalidOperator, game)
+		}
+	}
+	return nil
+}
+
+// normalizeHost lowercases a host name and drops any port
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// CreateAPIKey issues a new key for an operator. The key itself is only
+// returned here; afterwards just its prefix is known.
+func (s *OperatorService) CreateAPIKey(operatorID uint, name string) (*models.OperatorAPIKey, string, error) {
+	if _, err := s.Get(operatorID); err != nil {
+		return nil, "", err
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := "sk_" + hex.EncodeToString(b)
+
+	key := &models.OperatorAPIKey{
+		OperatorID: operatorID,
+		Name:       strings.TrimSpace(name),
+		Prefix:     secret[:10],
+		KeyHash:    hashAPIKey(secret),
+	}
+	if err := s.db.Create(key).Error; err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// APIKeys lists an operator's keys, revoked ones included
+func (s *OperatorService) APIKeys(operatorID uint) ([]models.OperatorAPIKey, error) {
+	if _, err := s.Get(operatorID); err != nil {
+		return nil, err
+	}
+	var keys []models.OperatorAPIKey
+	err := s.db.Where("operator_id = ?", operatorID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey stops a key from being accepted
+func (s *OperatorService) RevokeAPIKey(operatorID, keyID uint) (*models.OperatorAPIKey, error) {
+	var key models.OperatorAPIKey
+	if err := s.db.Where("id = ? AND operator_id = ?", keyID, operatorID).First(&key).Error; err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&key).Update("revoked_at", now).Error; err != nil {
+			return nil, err
+		}
+		key.RevokedAt = &now
+	}
+	return &key, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckOperatorSpin reports whether an operator offers a game at a bet. A
+// zero bet, as on a free spin, only checks the game.
+func CheckOperatorSpin(operator *models.Operator, game string, bet float64) error {
+	if len(operator.Games) > 0 && !slices.Contains(operator.Games, game) {
+		return ErrGameUnavailable
+	}
+	if bet == 0 {
+		return nil
+	}
+
+	min, max := operator.MythicMinBet, operator.MythicMaxBet
+	if game == GameFortuneGems {
+		min, max = float64(operator.FortuneMinBet), float64(operator.FortuneMaxBet)
+	}
+	switch {
+	case min > 0 && bet < min:
+		return &BetLimitError{Limit: fmt.Sprintf("must be at least %g", min)}
+	case max > 0 && bet > max:
+		return &BetLimitError{Limit: fmt.Sprintf("must be at most %g", max)}
+	}
+	return nil
+}
+
+// CheckOperatorFeatureBuy reports whether an operator offers the Mythic
+// Lightning bonus buy
+func CheckOperatorFeatureBuy(operator *models.Operator) error {
+	if !operator.FeatureBuy {
+		return ErrFeatureBuyDisabled
+	}
+	return nil
+}
+
+// BetLimit is the range of bets an operator accepts on a game. A zero
+// maximum means no limit.
+type BetLimit struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max,omitempty"`
+}
+
+// OperatorConfig is what players and the frontend see of an operator
+type OperatorConfig struct {
+	Code           string              `json:"code"`
+	Name           string              `json:"name"`
+	Currency       string              `json:"currency"`
+	Games          []string            `json:"games"`
+	BetLimits      map[string]BetLimit `json:"bet_limits"`
+	Branding       map[string]string   `json:"branding"`
+	SeamlessWallet bool                `json:"seamless_wallet"` // deposits and withdrawals are made with the operator
+}
+
+// PublicConfig returns the operator's configuration in force, with the
+// games' own limits where the operator sets none
+func PublicConfig(operator *models.Operator) OperatorConfig {
+	games := operator.Games
+	if len(games) == 0 {
+		games = []string{GameFortuneGems, GameMythic}
+	}
+
+	fortune := BetLimit{Min: fortuneMinBet, Max: fortuneMaxBet}
+	if operator.FortuneMinBet > 0 {
+		fortune.Min = float64(operator.FortuneMinBet)
+	}
+	if operator.FortuneMaxBet > 0 {
+		fortune.Max = float64(operator.FortuneMaxBet)
+	}
+
+	branding := operator.Branding
+	if branding == nil {
+		branding = map[string]string{}
+	}
+
+	return OperatorConfig{
+		Code:     operator.Code,
+		Name:     operator.Name,
+		Currency: operator.Currency,
+		Games:    games,
+		BetLimits: map[string]BetLimit{
+			GameFortuneGems: fortune,
+			GameMythic:      {Min: operator.MythicMinBet, Max: operator.MythicMaxBet},
+		},
+		Branding:       branding,
+		SeamlessWallet: operator.SeamlessWallet(),
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"slot-sim/config"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const CodeRealityCheckRequired = "REALITY_CHECK_REQUIRED"
+
+var (
+	ErrNoActivePlaySession   = errors.New("no active play session")
+	ErrNoRealityCheckPending = errors.New("no reality check is pending")
+)
+
+// RealityCheck is attached to a spin response once the reality-check
+// interval has passed. Further spins are refused until it is acknowledged.
+type RealityCheck struct {
+	SessionID      uint      `json:"session_id"`
+	StartedAt      time.Time `json:"started_at"`
+	ElapsedMinutes int       `json:"elapsed_minutes"`
+	SpinCount      int       `json:"spin_count"`
+	TotalWagered   float64   `json:"total_wagered"`
+	TotalWon       float64   `json:"total_won"`
+	NetResult      float64   `json:"net_result"`
+}
+
+// RealityCheckRequiredError is returned when a spin arrives while a reality
+// check is still waiting for acknowledgement
+type RealityCheckRequiredError struct {
+	Check *RealityCheck
+}
+
+func (e *RealityCheckRequiredError) Error() string {
+	return "reality check must be acknowledged before playing on"
+}
+
+// Body is the JSON response sent to the client
+func (e *RealityCheckRequiredError) Body() map[string]interface{} {
+	return map[string]interface{}{
+		"error":         "Please acknowledge the reality check before playing on",
+		"code":          CodeRealityCheckRequired,
+		"reality_check": e.Check,
+	}
+}
+
+type PlaySessionService struct {
+	db       *gorm.DB
+	interval time.Duration
+	idle     time.Duration
+}
+
+func NewPlaySessionService(db *gorm.DB) *PlaySessionService {
+	return &PlaySessionService{
+		db:       db,
+		interval: config.RealityCheckInterval(),
+		idle:     config.PlaySessionIdleTimeout(),
+	}
+}
+
+// BeforeSpin returns the player's active play session, starting a new one
+// when there is none, and refuses the spin while a reality check is pending
+func (s *PlaySessionService) BeforeSpin(userID uint) (*models.PlaySession, error) {
+	now := time.Now()
+
+	session, err := s.Current(userID)
+	if errors.Is(err, ErrNoActivePlaySession) {
+		session = &models.PlaySession{
+			UserID:             userID,
+			StartedAt:          now,
+			LastActivityAt:     now,
+			LastRealityCheckAt: now,
+		}
+		if err := s.db.Create(session).Error; err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if session.RealityCheckPending {
+		return nil, &RealityCheckRequiredError{Check: realityCheck(session, now)}
+	}
+	return session, nil
+}
+
+// RecordSpin adds a settled spin to the session and returns a reality check
+// when the interval has passed since the last one. The totals are updated
+// relative to what is stored, so overlapping spins, e.g. autoplay and manual
+// play, all count, and only one of them raises the reality check.
+func (s *PlaySessionService) RecordSpin(session *models.PlaySession, bet, win float64) (*RealityCheck, error) {
+	now := time.Now()
+
+	err := s.db.Model(&models.PlaySession{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"spin_count":       gorm.Expr("spin_count + 1"),
+		"total_wagered":    gorm.Expr("total_wagered + ?", bet),
+		"total_won":        gorm.Expr("total_won + ?", win),
+		"net_result":       gorm.Expr("(total_won + ?) - (total_wagered + ?)", win, bet),
+		"last_activity_at": now,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.db.Model(&models.PlaySession{}).
+		Where("id = ? AND reality_check_pending = ? AND last_reality_check_at <= ?", session.ID, false, now.Add(-s.interval)).
+		Update("reality_check_pending", true)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := s.db.First(session, session.ID).Error; err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return realityCheck(session, now), nil
+}
+
+// Acknowledge clears a pending reality check. When stop is set the play
+// session is ended so the next spin starts a fresh one; only a pending check
+// can be answered that way. The session time limit is not measured over
+// play sessions, so stopping does not reset it.
+func (s *PlaySessionService) Acknowledge(userID uint, stop bool) (*models.PlaySession, error) {
+	session, err := s.Current(userID)
+	if err != nil {
+		return nil, err
+	}
+	if stop && !session.RealityCheckPending {
+		return nil, ErrNoRealityCheckPending
+	}
+
+	now := time.Now()
+	session.RealityCheckPending = false
+	session.LastRealityCheckAt = now
+	updates := map[string]interface{}{
+		"reality_check_pending": false,
+		"last_reality_check_at": now,
+	}
+	if stop {
+		session.EndedAt = &now
+		updates["ended_at"] = now
+	}
+
+	if err := s.db.Model(session).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Current returns the player's active play session. Sessions idle for longer
+// than the idle timeout are closed here.
+func (s *PlaySessionService) Current(userID uint) (*models.PlaySession, error) {
+	var session models.PlaySession
+	result := s.db.Where("user_id = ? AND ended_at IS NULL", userID).
+		Order("started_at DESC").
+		Limit(1).
+		Find(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNoActivePlaySession
+	}
+
+	if time.Since(session.LastActivityAt) > s.idle {
+		endedAt := session.LastActivityAt
+		if err := s.db.Model(&session).Update("ended_at", endedAt).Error; err != nil {
+			return nil, err
+		}
+		return nil, ErrNoActivePlaySession
+	}
+	return &session, nil
+}
+
+// List returns play sessions, newest first. userID 0 lists all players;
+// scopes narrow the list further, such as to one operator's players.
+func (s *PlaySessionService) List(userID uint, limit int, scopes ...func(*gorm.DB) *gorm.DB) ([]models.PlaySession, error) {
+	query := s.db.Scopes(scopes...).Order("started_at DESC").Limit(limit)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var sessions []models.PlaySession
+	err := query.Find(&sessions).Error
+	return sessions, err
+}
+
+func realityCheck(session *models.PlaySession, now time.Time) *RealityCheck {
+	return &RealityCheck{
+		SessionID:      session.ID,
+		StartedAt:      session.StartedAt,
+		ElapsedMinutes: int(now.Sub(session.StartedAt).Minutes()),
+		SpinCount:      session.SpinCount,
+		TotalWagered:   session.TotalWagered,
+		TotalWon:       session.TotalWon,
+		NetResult:      session.NetResult,
+	}
+}
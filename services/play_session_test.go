@@ -0,0 +1,52 @@
+package services_test
+
+import (
+	"path/filepath"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
+	"testing"
+	"time"
+)
+
+// Overlapping spins hold the same copy of the session and must all count
+func TestRecordSpinOverlapping(t *testing.T) {
+	db, err := config.OpenDB(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sessions := services.NewPlaySessionService(db)
+
+	session, err := sessions.BeforeSpin(1)
+	if err != nil {
+		t.Fatalf("BeforeSpin: %v", err)
+	}
+	first, second := *session, *session
+
+	if check, err := sessions.RecordSpin(&first, 10, 4); err != nil || check != nil {
+		t.Fatalf("RecordSpin = %v, %v, want no reality check yet", check, err)
+	}
+	if _, err := sessions.RecordSpin(&second, 5, 20); err != nil {
+		t.Fatalf("RecordSpin: %v", err)
+	}
+	if second.SpinCount != 2 || second.TotalWagered != 15 || second.TotalWon != 24 || second.NetResult != 9 {
+		t.Errorf("session has %d spins, wagered %v, won %v, net %v, want both spins counted",
+			second.SpinCount, second.TotalWagered, second.TotalWon, second.NetResult)
+	}
+
+	// Once the interval has passed, one of two overlapping spins raises the check
+	due := time.Now().Add(-config.RealityCheckInterval() - time.Minute)
+	if err := db.Model(&models.PlaySession{}).Where("id = ?", session.ID).Update("last_reality_check_at", due).Error; err != nil {
+		t.Fatalf("failed to age the session: %v", err)
+	}
+	check, err := sessions.RecordSpin(&first, 10, 0)
+	if err != nil || check == nil {
+		t.Fatalf("RecordSpin = %v, %v, want a reality check", check, err)
+	}
+	if check.SpinCount != 3 {
+		t.Errorf("reality check counts %d spins, want 3", check.SpinCount)
+	}
+	if check, err := sessions.RecordSpin(&second, 10, 0); err != nil || check != nil {
+		t.Errorf("RecordSpin = %v, %v, want the check raised only once", check, err)
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromoNotFound        = errors.New("promo code not found")
+	ErrPromoExpired         = errors.New("promo code has expired")
+	ErrPromoExhausted       = errors.New("promo code has reached its usage cap")
+	ErrPromoAlreadyRedeemed = errors.New("promo code already redeemed")
+	ErrPromoNotEligible     = errors.New("not eligible for this promo code")
+	ErrInvalidPromo         = errors.New("invalid promo code")
+)
+
+// PromoCodeInput is what an admin configures for a new promo code
+type PromoCodeInput struct {
+	Code              string
+	Description       string
+	RewardType        string
+	BonusAmount       int
+	FreeSpins         int
+	FreeSpinBet       float64
+	FreeSpinDays      int
+	MaxRedemptions    int
+	ExpiresAt         *time.Time
+	MinTier           string
+	MaxAccountAgeDays int
+	RequiresDeposit   bool
+}
+
+// PromoResult is what a redemption granted
+type PromoResult struct {
+	Code         string               `json:"code"`
+	RewardType   string               `json:"reward_type"`
+	BonusAmount  int                  `json:"bonus_amount,omitempty"`
+	FreeSpinPack *models.FreeSpinPack `json:"free_spin_pack,omitempty"`
+}
+
+type PromoService struct {
+	db *gorm.DB
+}
+
+func NewPromoService(db *gorm.DB) *PromoService {
+	return &PromoService{db: db}
+}
+
+// Create validates and stores a new promo code
+func (s *PromoService) Create(input PromoCodeInput, actor string) (*models.PromoCode, error) {
+	input.Code = normalizePromoCode(input.Code)
+	if err := validatePromo(input); err != nil {
+		return nil, err
+	}
+
+	promo := &models.PromoCode{
+		Code:              input.Code,
+		Description:       input.Description,
+		RewardType:        input.RewardType,
+		BonusAmount:       input.BonusAmount,
+		FreeSpins:         input.FreeSpins,
+		FreeSpinBet:       input.FreeSpinBet,
+		FreeSpinDays:      input.FreeSpinDays,
+		MaxRedemptions:    input.MaxRedemptions,
+		ExpiresAt:         input.ExpiresAt,
+		MinTier:           input.MinTier,
+		MaxAccountAgeDays: input.MaxAccountAgeDays,
+		RequiresDeposit:   input.RequiresDeposit,
+		Active:            true,
+		CreatedBy:         actor,
+	}
+	if err := s.db.Create(promo).Error; err != nil {
+		return nil, fmt.Errorf("%w: code %s already exists", ErrInvalidPromo, input.Code)
+	}
+	return promo, nil
+}
+
+// List returns every promo code, newest first
+func (s *PromoService) List() ([]models.PromoCode, error) {
+	var promos []models.PromoCode
+	err := s.db.Order("created_at DESC").Find(&promos).Error
+	return promos, err
+}
+
+// SetActive switches a promo code on or off
+func (s *PromoService) SetActive(promoID uint, active bool) error {
+	result := s.db.Model(&models.PromoCode{}).Where("id = ?", promoID).Update("active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromoNotFound
+	}
+	return nil
+}
+
+// Redeem checks the code and the player's eligibility and grants the reward.
+// The usage cap and single use per player are enforced by the database, so
+// concurrent redemptions cannot exceed them.
+func (s *PromoService) Redeem(userID uint, code string) (*PromoResult, error) {
+	var promo models.PromoCode
+	if err := s.db.Where("code = ? AND active = ?", normalizePromoCode(code), true).First(&promo).Error; err != nil {
+		return nil, ErrPromoNotFound
+	}
+	if promo.ExpiresAt != nil && time.Now().After(*promo.ExpiresAt) {
+		return nil, ErrPromoExpired
+	}
+	if err := s.checkEligibility(userID, promo); err != nil {
+		return nil, err
+	}
+
+	result := &PromoResult{Code: promo.Code, RewardType: promo.RewardType}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		redemption := models.PromoRedemption{PromoCodeID: promo.ID, UserID: userID}
+		var existing int64
+		if err := tx.Model(&redemption).Where("promo_code_id = ? AND user_id = ?", promo.ID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrPromoAlreadyRedeemed
+		}
+
+		capped := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND (max_redemptions = 0 OR redemptions < max_redemptions)", promo.ID).
+			Update("redemptions", gorm.Expr("redemptions + 1"))
+		if capped.Error != nil {
+			return capped.Error
+		}
+		if capped.RowsAffected == 0 {
+			return ErrPromoExhausted
+		}
+
+		source := "promo:" + promo.Code
+		switch promo.RewardType {
+		case models.RewardBalance:
+			adjustment, err := AdjustBalance(tx, userID, promo.BonusAmount, "Promo code "+promo.Code, source)
+			if err != nil {
+				return err
+			}
+			redemption.AdjustmentID = &adjustment.ID
+			result.BonusAmount = promo.BonusAmount
+		case models.RewardFreeSpins:
+			var expiresAt *time.Time
+			if promo.FreeSpinDays > 0 {
+				t := time.Now().AddDate(0, 0, promo.FreeSpinDays)
+				expiresAt = &t
+			}
+			pack, err := GrantFreeSpins(tx, userID, promo.FreeSpins, promo.FreeSpinBet, source, expiresAt)
+			if err != nil {
+				return err
+			}
+			redemption.FreeSpinPackID = &pack.ID
+			result.FreeSpinPack = pack
+		}
+
+		// The unique index rejects a second redemption racing this one
+		if err := tx.Create(&redemption).Error; err != nil {
+			return ErrPromoAlreadyRedeemed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.FreeSpinPack != nil {
+		PublishFreeSpins(result.FreeSpinPack)
+	} else {
+		PublishBalance(s.db, userID, "promo_code")
+	}
+	return result, nil
+}
+
+// checkEligibility applies the code's eligibility rules to the player
+func (s *PromoService) checkEligibility(userID uint, promo models.PromoCode) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+
+	if promo.MaxAccountAgeDays > 0 && time.Since(user.CreatedAt) > time.Duration(promo.MaxAccountAgeDays)*24*time.Hour {
+		return fmt.Errorf("%w: only for accounts younger than %d days", ErrPromoNotEligible, promo.MaxAccountAgeDays)
+	}
+
+	if promo.MinTier != "" {
+		status, err := NewLoyaltyService(s.db).Status(userID)
+		if err != nil {
+			return err
+		}
+		if tierIndex(status.Tier.Name) < tierIndex(promo.MinTier) {
+			return fmt.Errorf("%w: requires %s tier or higher", ErrPromoNotEligible, promo.MinTier)
+		}
+	}
+
+	if promo.RequiresDeposit {
+		var deposits int64
+		err := s.db.Model(&models.Transaction{}).
+			Where("user_id = ? AND type = ? AND status = ?", userID, models.TypeDeposit, models.StatusApproved).
+			Count(&deposits).Error
+		if err != nil {
+			return err
+		}
+		if deposits == 0 {
+			return fmt.Errorf("%w: requires an approved deposit", ErrPromoNotEligible)
+		}
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromo(input PromoCodeInput) error {
+	switch {
+	case input.Code == "":
+		return fmt.Errorf("%w: code is required", ErrInvalidPromo)
+	case input.MaxRedemptions < 0 || input.MaxAccountAgeDays < 0 || input.FreeSpinDays < 0:
+		return fmt.Errorf("%w: caps and day counts cannot be negative", ErrInvalidPromo)
+	case input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()):
+		return fmt.Errorf("%w: expiry must be in the future", ErrInvalidPromo)
+	case input.MinTier != "" && vipTiers[tierIndex(input.MinTier)].Name != input.MinTier:
+		return fmt.Errorf("%w: unknown tier %q", ErrInvalidPromo, input.MinTier)
+	}
+
+	switch input.RewardType {
+	case models.RewardBalance:
+		if input.BonusAmount <= 0 {
+			return fmt.Errorf("%w: bonus amount must be greater than 0", ErrInvalidPromo)
+		}
+	case models.RewardFreeSpins:
+		if input.FreeSpins <= 0 || input.FreeSpinBet <= 0 {
+			return fmt.Errorf("%w: free spins and bet must be greater than 0", ErrInvalidPromo)
+		}
+	default:
+		return fmt.Errorf("%w: reward must be balance or free_spins", ErrInvalidPromo)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slot-sim/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWalletUnavailable = errors.New("wallet is unavailable")
+	ErrWalletRejected    = errors.New("wallet rejected the transaction")
+)
+
+// Headers that sign a call to a remote wallet, see SignWalletRequest
+const (
+	WalletTimestampHeader = "X-Wallet-Timestamp"
+	WalletSignatureHeader = "X-Wallet-Signature"
+)
+
+// Retry policy of remote wallet calls. The wait doubles after each attempt.
+const (
+	walletAttempts = 3
+	walletBackoff  = 100 * time.Millisecond
+	walletTimeout  = 5 * time.Second
+)
+
+// Wait before the WalletReconciler resends a credit or rollback the wallet
+// did not answer. It doubles after each pass, up to walletRetryMaxDelay.
+const (
+	walletRetryDelay    = time.Minute
+	walletRetryMaxDelay = time.Hour
+)
+
+// RemoteWalletRequest is the body of a debit, credit or rollback call
+type RemoteWalletRequest struct {
+	TransactionID          string `json:"transaction_id"`
+	RoundID                string `json:"round_id"`
+	PlayerID               string `json:"player_id"`
+	Game                   string `json:"game"`
+	Amount                 int    `json:"amount"`
+	Currency               string `json:"currency"`
+	ReferenceTransactionID string `json:"reference_transaction_id,omitempty"` // rollbacks only
+}
+
+// RemoteWalletResponse is what the wallet answers with 200 OK
+type RemoteWalletResponse struct {
+	Balance float64 `json:"balance"`
+}
+
+// SignWalletRequest returns the signature of a wallet call: the hex
+// encoded HMAC-SHA256, under the operator's wallet secret, of the Unix
+// timestamp, a dot and the body
+func SignWalletRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RemoteWallet settles rounds against the operator's own wallet with signed
+// POST calls to its debit, credit and rollback endpoints. Calls that get no
+// answer, or a 5xx or 429, are retried with the same transaction ID; 402
+// means insufficient funds and any other status a refusal. The calls are
+// made outside the round's database transaction and each is recorded in the
+// ledger on its own, before and after it is sent. The balance the wallet
+// reports is mirrored in the users table for display.
+type RemoteWallet struct {
+	db       *gorm.DB
+	operator *models.Operator
+	client   *http.Client
+}
+
+func NewRemoteWallet(db *gorm.DB, operator *models.Operator) *RemoteWallet {
+	return &RemoteWallet{db: db, operator: operator, client: &http.Client{Timeout: walletTimeout}}
+}
+
+func (w *RemoteWallet) Transactional() bool {
+	return false
+}
+
+func (w *RemoteWallet) Debit(_ *gorm.DB, req WalletRequest) (int, error) {
+	return w.call(models.WalletDebit, req, "")
+}
+
+func (w *RemoteWallet) Credit(_ *gorm.DB, req WalletRequest) (int, error) {
+	return w.call(models.WalletCredit, req, "")
+}
+
+// Rollback asks the wallet to void a debit or credit
+func (w *RemoteWallet) Rollback(req WalletRequest) error {
+	reference := req.TransactionID
+	req.TransactionID = reference + "-" + models.WalletRollback
+	_, err := w.call(models.WalletRollback, req, reference)
+	return err
+}
+
+// QueueCredit records a credit made outside a round, such as a prize or a
+// bonus, in the ledger as part of tx. It is sent by the WalletReconciler
+// once tx has committed, so it is paid if and only if what it pays for is.
+func (w *RemoteWallet) QueueCredit(tx *gorm.DB, req WalletRequest) error {
+	now := time.Now()
+	return tx.Create(&models.WalletTransaction{
+		OperatorID:    req.OperatorID,
+		UserID:        req.UserID,
+		RoundID:       req.RoundID,
+		TransactionID: req.TransactionID,
+		Type:          models.WalletCredit,
+		Game:          req.Game,
+		Amount:        req.Amount,
+		Status:        models.WalletStatusPending,
+		NextAttemptAt: &now,
+	}).Error
+}
+
+// call records one movement as pending in the wallet transaction ledger and
+// delivers it
+func (w *RemoteWallet) call(kind string, req WalletRequest, reference string) (int, error) {
+	entry := models.WalletTransaction{
+		OperatorID:    req.OperatorID,
+		UserID:        req.UserID,
+		RoundID:       req.RoundID,
+		TransactionID: req.TransactionID,
+		ReferenceID:   reference,
+		Type:          kind,
+		Game:          req.Game,
+		Amount:        req.Amount,
+		Status:        models.WalletStatusPending,
+	}
+	if err := w.db.Create(&entry).Error; err != nil {
+		return 0, err
+	}
+	return w.deliver(&entry, req.Player)
+}
+
+// deliver sends a recorded movement, retrying as needed, and saves its
+// outcome. A credit or rollback that gets no answer is queued for the
+// WalletReconciler.
+func (w *RemoteWallet) deliver(entry *models.WalletTransaction, player string) (int, error) {
+	body, _ := json.Marshal(RemoteWalletRequest{
+		TransactionID:          entry.TransactionID,
+		RoundID:                entry.RoundID,
+		PlayerID:               player,
+		Game:                   entry.Game,
+		Amount:                 entry.Amount,
+		Currency:               w.operator.Currency,
+		ReferenceTransactionID: entry.ReferenceID,
+	})
+
+	var balance int
+	var err error
+	for attempt := 1; ; attempt++ {
+		entry.Attempts++
+		var retry bool
+		balance, retry, err = w.send(entry.Type, body)
+		if !retry || attempt == walletAttempts {
+			break
+		}
+		time.Sleep(walletBackoff << (attempt - 1))
+	}
+
+	entry.NextAttemptAt = nil
+	switch {
+	case err == nil:
+		entry.Status = models.WalletStatusOK
+		entry.Balance = balance
+		entry.Error = ""
+	case errors.Is(err, ErrWalletUnavailable):
+		entry.Status = models.WalletStatusFailed
+		entry.Error = err.Error()
+		if entry.Type != models.WalletDebit {
+			next := time.Now().Add(walletRetryAfter(entry.Attempts))
+			entry.NextAttemptAt = &next
+		}
+	default:
+		entry.Status = models.WalletStatusRejected
+		entry.Error = err.Error()
+	}
+	if saveErr := w.db.Save(entry).Error; saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = w.db.Model(&models.User{}).Where("id = ?", entry.UserID).Update("balance", balance).Error
+	return balance, err
+}
+
+// walletRetryAfter is how long a queued movement waits after attempts
+// calls, walletAttempts of them per pass
+func walletRetryAfter(attempts int) time.Duration {
+	delay := walletRetryDelay
+	for pass := attempts / walletAttempts; pass > 1 && delay < walletRetryMaxDelay; pass-- {
+		delay *= 2
+	}
+	return min(delay, walletRetryMaxDelay)
+}
+
+// send makes one attempt and reports whether it is worth another
+func (w *RemoteWallet) send(kind string, body []byte) (int, bool, error) {
+	url := strings.TrimRight(w.operator.WalletURL, "/") + "/" + kind
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %v", ErrWalletRejected, err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(WalletTimestampHeader, timestamp)
+	httpReq.Header.Set(WalletSignatureHeader, SignWalletRequest(w.operator.WalletSecret, timestamp, body))
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return 0, true, fmt.Errorf("%w: %v", ErrWalletUnavailable, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var result RemoteWalletResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			return 0, false, fmt.Errorf("%w: invalid response: %v", ErrWalletRejected, err)
+		}
+		return int(math.Round(result.Balance)), false, nil
+	case resp.StatusCode == http.StatusPaymentRequired:
+		return 0, false, ErrInsufficientBalance
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return 0, true, fmt.Errorf("%w: status %d", ErrWalletUnavailable, resp.StatusCode)
+	default:
+		return 0, false, fmt.Errorf("%w: status %d: %s", ErrWalletRejected, resp.StatusCode, bytes.TrimSpace(data))
+	}
+}
+
+// WalletReconciler sends queued credits and resends the credits and
+// rollbacks remote wallets did not answer, with their original transaction
+// IDs, until the wallet answers.
+// Those the wallet refuses are left rejected for an admin to reconcile.
+type WalletReconciler struct {
+	db *gorm.DB
+}
+
+func NewWalletReconciler(db *gorm.DB) *WalletReconciler {
+	return &WalletReconciler{db: db}
+}
+
+// Reconcile sends the queued movements due at now and returns how many
+// the wallets accepted
+func (r *WalletReconciler) Reconcile(now time.Time) (int, error) {
+	var due []models.WalletTransaction
+	err := r.db.Where("status IN ? AND next_attempt_at <= ?", []string{models.WalletStatusPending, models.WalletStatusFailed}, now).
+		Order("id").Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range due {
+		entry := &due[i]
+		var operator models.Operator
+		if err := r.db.First(&operator, entry.OperatorID).Error; err != nil {
+			return delivered, err
+		}
+		var user models.User
+		if err := r.db.First(&user, entry.UserID).Error; err != nil {
+			return delivered, err
+		}
+
+		_, err := NewRemoteWallet(r.db, &operator).deliver(entry, user.Username)
+		switch {
+		case err == nil:
+			delivered++
+		case !errors.Is(err, ErrWalletUnavailable):
+			println("Failed to reconcile wallet transaction", entry.TransactionID, err.Error())
+		}
+	}
+	return delivered, nil
+}
+
+// RunScheduler resends queued movements as they fall due. It blocks, run it
+// in its own goroutine.
+func (r *WalletReconciler) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := r.Reconcile(time.Now()); err != nil {
+			println("Failed to reconcile wallet transactions:", err.Error())
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package services_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
+	"slot-sim/walletstub"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	testSecret = "stub-secret"
+	testPlayer = "wallet-player"
+)
+
+// walletFixture is a remote wallet pointed at a stub served over HTTP, with
+// a scratch database for its ledger
+type walletFixture struct {
+	db       *gorm.DB
+	stub     *walletstub.Server
+	operator *models.Operator
+	user     *models.User
+}
+
+func newWalletFixture(t *testing.T) *walletFixture {
+	db, err := config.OpenDB(filepath.Join(t.TempDir(), "wallet.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	stub := walletstub.New(testSecret, 100)
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	operator := &models.Operator{Code: "seamless", Name: "Seamless Casino", Currency: "EUR", WalletURL: server.URL, WalletSecret: testSecret}
+	if err := db.Create(operator).Error; err != nil {
+		t.Fatalf("failed to create operator: %v", err)
+	}
+	user := &models.User{OperatorID: operator.ID, Username: testPlayer, Password: "-", Role: "user"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return &walletFixture{db: db, stub: stub, operator: operator, user: user}
+}
+
+func (f *walletFixture) wallet() *services.RemoteWallet {
+	return services.NewRemoteWallet(f.db, f.operator)
+}
+
+func (f *walletFixture) request(transactionID string, amount int) services.WalletRequest {
+	return services.WalletRequest{
+		TransactionID: transactionID,
+		RoundID:       "rnd_test",
+		OperatorID:    f.operator.ID,
+		UserID:        f.user.ID,
+		Player:        testPlayer,
+		Game:          services.GameMythic,
+		Amount:        amount,
+	}
+}
+
+// entry returns the ledger row of a transaction
+func (f *walletFixture) entry(t *testing.T, transactionID string) models.WalletTransaction {
+	t.Helper()
+	var entry models.WalletTransaction
+	if err := f.db.Where("transaction_id = ?", transactionID).First(&entry).Error; err != nil {
+		t.Fatalf("ledger entry %s: %v", transactionID, err)
+	}
+	return entry
+}
+
+func (f *walletFixture) mirroredBalance(t *testing.T) int {
+	t.Helper()
+	var user models.User
+	if err := f.db.First(&user, f.user.ID).Error; err != nil {
+		t.Fatalf("user: %v", err)
+	}
+	return user.Balance
+}
+
+func TestRemoteWalletDebitAndCredit(t *testing.T) {
+	f := newWalletFixture(t)
+	wallet := f.wallet()
+
+	balance, err := wallet.Debit(nil, f.request("t1-debit", 10))
+	if err != nil || balance != 90 {
+		t.Fatalf("Debit = %d, %v, want 90", balance, err)
+	}
+	balance, err = wallet.Credit(nil, f.request("t1-credit", 25))
+	if err != nil || balance != 115 {
+		t.Fatalf("Credit = %d, %v, want 115", balance, err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 115 {
+		t.Errorf("stub holds %v, want 115", got)
+	}
+	if got := f.mirroredBalance(t); got != 115 {
+		t.Errorf("users table mirrors %d, want 115", got)
+	}
+
+	entry := f.entry(t, "t1-credit")
+	if entry.Status != models.WalletStatusOK || entry.Type != models.WalletCredit || entry.Attempts != 1 || entry.Balance != 115 {
+		t.Errorf("credit recorded as %s %s after %d attempts at %d", entry.Status, entry.Type, entry.Attempts, entry.Balance)
+	}
+
+	_, err = wallet.Debit(nil, f.request("t2-debit", 500))
+	if !errors.Is(err, services.ErrInsufficientBalance) {
+		t.Errorf("Debit beyond the balance = %v, want ErrInsufficientBalance", err)
+	}
+	if entry := f.entry(t, "t2-debit"); entry.Status != models.WalletStatusRejected || entry.NextAttemptAt != nil {
+		t.Errorf("refused debit recorded as %s, queued %v", entry.Status, entry.NextAttemptAt != nil)
+	}
+}
+
+func TestRemoteWalletRollback(t *testing.T) {
+	f := newWalletFixture(t)
+	wallet := f.wallet()
+
+	if _, err := wallet.Debit(nil, f.request("t1-debit", 30)); err != nil {
+		t.Fatalf("Debit: %v", err)
+	}
+	if err := wallet.Rollback(f.request("t1-debit", 30)); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 100 {
+		t.Errorf("stub holds %v after the rollback, want 100", got)
+	}
+
+	entry := f.entry(t, "t1-debit-rollback")
+	if entry.Type != models.WalletRollback || entry.ReferenceID != "t1-debit" || entry.Status != models.WalletStatusOK {
+		t.Errorf("rollback recorded as %s of %q, %s", entry.Type, entry.ReferenceID, entry.Status)
+	}
+
+	// A debit arriving after its rollback is refused
+	if _, err := wallet.Debit(nil, f.request("t2-debit", 30)); err != nil {
+		t.Fatalf("Debit: %v", err)
+	}
+	if err := wallet.Rollback(f.request("t3-debit", 30)); err != nil {
+		t.Fatalf("Rollback of an unseen debit: %v", err)
+	}
+	_, err := wallet.Debit(nil, f.request("t3-debit", 30))
+	if !errors.Is(err, services.ErrWalletRejected) {
+		t.Errorf("Debit after its rollback = %v, want ErrWalletRejected", err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 70 {
+		t.Errorf("stub holds %v, want 70", got)
+	}
+}
+
+func TestRemoteWalletSignatureRejected(t *testing.T) {
+	f := newWalletFixture(t)
+	f.operator.WalletSecret = "wrong-secret"
+
+	_, err := f.wallet().Debit(nil, f.request("t1-debit", 10))
+	if !errors.Is(err, services.ErrWalletRejected) {
+		t.Fatalf("Debit with a wrong secret = %v, want ErrWalletRejected", err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 100 {
+		t.Errorf("stub holds %v, want 100", got)
+	}
+	entry := f.entry(t, "t1-debit")
+	if entry.Status != models.WalletStatusRejected || entry.Attempts != 1 {
+		t.Errorf("unsigned debit recorded as %s after %d attempts, want rejected after 1", entry.Status, entry.Attempts)
+	}
+}
+
+func TestRemoteWalletRetry(t *testing.T) {
+	f := newWalletFixture(t)
+	wallet := f.wallet()
+
+	// Two unanswered attempts, the third gets through
+	f.stub.FailNext(2)
+	balance, err := wallet.Debit(nil, f.request("t1-debit", 10))
+	if err != nil || balance != 90 {
+		t.Fatalf("Debit = %d, %v, want 90", balance, err)
+	}
+	if entry := f.entry(t, "t1-debit"); entry.Status != models.WalletStatusOK || entry.Attempts != 3 {
+		t.Errorf("debit recorded as %s after %d attempts, want ok after 3", entry.Status, entry.Attempts)
+	}
+
+	// A debit with no answer at all fails and is not queued: its round is
+	// voided instead
+	f.stub.FailNext(3)
+	_, err = wallet.Debit(nil, f.request("t2-debit", 10))
+	if !errors.Is(err, services.ErrWalletUnavailable) {
+		t.Fatalf("Debit = %v, want ErrWalletUnavailable", err)
+	}
+	if entry := f.entry(t, "t2-debit"); entry.Status != models.WalletStatusFailed || entry.NextAttemptAt != nil {
+		t.Errorf("unanswered debit recorded as %s, queued %v", entry.Status, entry.NextAttemptAt != nil)
+	}
+	if got := f.stub.Balance(testPlayer); got != 90 {
+		t.Errorf("stub holds %v, want 90", got)
+	}
+}
+
+func TestWalletReconcilerResendsCredits(t *testing.T) {
+	f := newWalletFixture(t)
+	wallet := f.wallet()
+	reconciler := services.NewWalletReconciler(f.db)
+
+	f.stub.FailCredits(true)
+	_, err := wallet.Credit(nil, f.request("t1-credit", 40))
+	if !errors.Is(err, services.ErrWalletUnavailable) {
+		t.Fatalf("Credit = %v, want ErrWalletUnavailable", err)
+	}
+	queued := f.entry(t, "t1-credit")
+	if queued.Status != models.WalletStatusFailed || queued.NextAttemptAt == nil {
+		t.Fatalf("unanswered credit recorded as %s and not queued", queued.Status)
+	}
+
+	// Not due yet
+	if delivered, err := reconciler.Reconcile(time.Now()); err != nil || delivered != 0 {
+		t.Errorf("Reconcile before it is due = %d, %v, want nothing sent", delivered, err)
+	}
+
+	// Due, but the wallet still does not answer: queued again, further out
+	if delivered, err := reconciler.Reconcile(*queued.NextAttemptAt); err != nil || delivered != 0 {
+		t.Errorf("Reconcile = %d, %v, want nothing delivered", delivered, err)
+	}
+	requeued := f.entry(t, "t1-credit")
+	if requeued.Status != models.WalletStatusFailed || requeued.Attempts != 6 || requeued.NextAttemptAt == nil ||
+		requeued.NextAttemptAt.Sub(requeued.UpdatedAt) <= queued.NextAttemptAt.Sub(queued.UpdatedAt) {
+		t.Errorf("credit recorded as %s after %d attempts, next at %v", requeued.Status, requeued.Attempts, requeued.NextAttemptAt)
+	}
+
+	f.stub.FailCredits(false)
+	if delivered, err := reconciler.Reconcile(time.Now().Add(time.Hour)); err != nil || delivered != 1 {
+		t.Fatalf("Reconcile = %d, %v, want the credit delivered", delivered, err)
+	}
+	entry := f.entry(t, "t1-credit")
+	if entry.Status != models.WalletStatusOK || entry.NextAttemptAt != nil || entry.Error != "" || entry.Balance != 140 {
+		t.Errorf("credit recorded as %s at %d, queued %v", entry.Status, entry.Balance, entry.NextAttemptAt != nil)
+	}
+	if got := f.mirroredBalance(t); got != 140 {
+		t.Errorf("users table mirrors %d, want 140", got)
+	}
+
+	// Delivered once only
+	if delivered, _ := reconciler.Reconcile(time.Now().Add(time.Hour)); delivered != 0 {
+		t.Errorf("Reconcile resent %d delivered credits", delivered)
+	}
+	if got := f.stub.Balance(testPlayer); got != 140 {
+		t.Errorf("stub holds %v, want 140", got)
+	}
+}
+
+func TestWalletReconcilerResendsRollbacks(t *testing.T) {
+	f := newWalletFixture(t)
+	wallet := f.wallet()
+
+	if _, err := wallet.Debit(nil, f.request("t1-debit", 25)); err != nil {
+		t.Fatalf("Debit: %v", err)
+	}
+	f.stub.FailNext(3)
+	if err := wallet.Rollback(f.request("t1-debit", 25)); !errors.Is(err, services.ErrWalletUnavailable) {
+		t.Fatalf("Rollback = %v, want ErrWalletUnavailable", err)
+	}
+	if entry := f.entry(t, "t1-debit-rollback"); entry.NextAttemptAt == nil {
+		t.Fatal("unanswered rollback not queued")
+	}
+
+	delivered, err := services.NewWalletReconciler(f.db).Reconcile(time.Now().Add(time.Hour))
+	if err != nil || delivered != 1 {
+		t.Fatalf("Reconcile = %d, %v, want the rollback delivered", delivered, err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 100 {
+		t.Errorf("stub holds %v after the rollback, want 100", got)
+	}
+}
+
+func TestAdjustBalanceCreditsSeamlessWallet(t *testing.T) {
+	f := newWalletFixture(t)
+
+	adjustment, err := services.AdjustBalance(f.db, f.user.ID, 50, "Tournament prize", "tournament")
+	if err != nil {
+		t.Fatalf("AdjustBalance: %v", err)
+	}
+	if adjustment.WalletCredit == "" {
+		t.Fatal("adjustment names no wallet credit")
+	}
+	queued := f.entry(t, adjustment.WalletCredit)
+	if queued.Type != models.WalletCredit || queued.Status != models.WalletStatusPending || queued.Amount != 50 || queued.NextAttemptAt == nil {
+		t.Errorf("credit recorded as %s %s of %d, queued %v", queued.Status, queued.Type, queued.Amount, queued.NextAttemptAt != nil)
+	}
+	if got := f.stub.Balance(testPlayer); got != 100 {
+		t.Errorf("stub holds %v before the credit is sent, want 100", got)
+	}
+
+	delivered, err := services.NewWalletReconciler(f.db).Reconcile(time.Now())
+	if err != nil || delivered != 1 {
+		t.Fatalf("Reconcile = %d, %v, want the credit delivered", delivered, err)
+	}
+	if got := f.stub.Balance(testPlayer); got != 150 {
+		t.Errorf("stub holds %v, want 150", got)
+	}
+	if got := f.mirroredBalance(t); got != 150 {
+		t.Errorf("users table mirrors %d, want 150", got)
+	}
+
+	_, err = services.AdjustBalance(f.db, f.user.ID, -20, "Correction", "admin")
+	if !errors.Is(err, services.ErrSeamlessWallet) {
+		t.Errorf("AdjustBalance debit = %v, want ErrSeamlessWallet", err)
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"slot-sim/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoundNotFound   = errors.New("round not found")
+	ErrInvalidRoundRef = errors.New("invalid round reference")
+)
+
+// Round references name a round of either game: the game prefix and the
+// round's ID in that game's table
+const (
+	fortuneRoundPrefix = "fg-"
+	mythicRoundPrefix  = "ml-"
+)
+
+func FortuneRoundRef(id uint) string { return fortuneRoundPrefix + strconv.FormatUint(uint64(id), 10) }
+func MythicRoundRef(id uint) string  { return mythicRoundPrefix + strconv.FormatUint(uint64(id), 10) }
+
+// RoundReplay is everything a client needs to play a recorded round back.
+// Complete is false for Mythic rounds recorded before the initial grid was
+// kept: their first tumble has no removed cells, moves or refills.
+type RoundReplay struct {
+	Ref        string         `json:"ref"`
+	Game       string         `json:"game"`
+	Bet        float64        `json:"bet"`
+	Stake      float64        `json:"stake"` // amount debited
+	Win        float64        `json:"win"`   // game win, without any jackpot
+	JackpotWin float64        `json:"jackpot_win"`
+	Complete   bool           `json:"complete"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Fortune    *FortuneReplay `json:"fortune,omitempty"`
+	Mythic     *MythicReplay  `json:"mythic,omitempty"`
+}
+
+// FortuneReplay is a Fortune Gems round with the payout of every line
+type FortuneReplay struct {
+	Grid          [3][3]string `json:"grid"`
+	Lines         []LinePayout `json:"lines"`
+	SpecialSymbol string       `json:"special_symbol"`
+	Multiplier    int          `json:"multiplier"`
+	IsFortuneSpin bool         `json:"is_fortune_spin"`
+	WheelPrize    int          `json:"wheel_prize,omitempty"`
+	BaseWin       int          `json:"base_win"`
+	BonusWin      int          `json:"bonus_win"`
+	FinalWin      int          `json:"final_win"`
+	RNGSeed       int64        `json:"rng_seed"`
+}
+
+// LinePayout is a winning Fortune Gems payline
+type LinePayout struct {
+	Line      int              `json:"line"`
+	Symbol    string           `json:"symbol"`
+	Positions []utils.Position `json:"positions"`
+	Win       int              `json:"win"`
+}
+
+// MythicReplay is a Mythic Lightning spin broken down tumble by tumble
+type MythicReplay struct {
+	InitialGrid      [][]string       `json:"initial_grid"`
+	Tumbles          []ReplayTumble   `json:"tumbles"`
+	FinalGrid        [][]string       `json:"final_grid"`
+	ScatterPositions []utils.Position `json:"scatter_positions"`
+	ScatterWin       float64          `json:"scatter_win"`
+	FreeSpinsAwarded int              `json:"free_spins_awarded"`
+	BaseWin          float64          `json:"base_win"`
+	TotalMultiplier  float64          `json:"total_multiplier"`
+	TotalWin         float64          `json:"total_win"`
+	FreeSpins        []MythicReplay   `json:"free_spins,omitempty"` // a feature buy's free spins
+}
+
+// ReplayTumble is one tumble: the winning clusters are removed, the symbols
+// above fall down, new symbols fill the top and lightning may strike
+type ReplayTumble struct {
+	Index      int              `json:"index"`
+	Payouts    []ClusterPayout  `json:"payouts"`
+	Win        float64          `json:"win"`
+	Removed    []utils.Position `json:"removed"`
+	Moves      []GravityMove    `json:"moves"`
+	Refilled   []RefilledCell   `json:"refilled"`
+	Strikes    []float64        `json:"strikes"`
+	Multiplier float64          `json:"multiplier"` // accumulated multiplier after this tumble
+	Grid       [][]string       `json:"grid"`
+}
+
+// ClusterPayout is the win of one cluster
+type ClusterPayout struct {
+	Symbol    string           `json:"symbol"`
+	Size      int              `json:"size"`
+	Positions []utils.Position `json:"positions"`
+	Win       float64          `json:"win"`
+}
+
+// GravityMove is a symbol falling to fill the cells removed below it
+type GravityMove struct {
+	Symbol string         `json:"symbol"`
+	From   utils.Position `json:"from"`
+	To     utils.Position `json:"to"`
+}
+
+// RefilledCell is a new symbol dropped in at the top of a column
+type RefilledCell struct {
+	utils.Position
+	Symbol string `json:"symbol"`
+}
+
+type ReplayService struct {
+	db     *gorm.DB
+	mythic *MythicEngine
+}
+
+func NewReplayService(db *gorm.DB) *ReplayService {
+	return &ReplayService{db: db, mythic: NewMythicEngine()}
+}
+
+// Round returns the replay of one of the player's rounds
+func (s *ReplayService) Round(userID uint, ref string) (*RoundReplay, error) {
+	switch {
+	case strings.HasPrefix(ref, fortuneRoundPrefix):
+		id, err := parseRoundID(ref, fortuneRoundPrefix)
+		if err != nil {
+			return nil, err
+		}
+		var round models.FortuneRound
+		if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&round).Error; err != nil {
+			return nil, ErrRoundNotFound
+		}
+		return s.fortuneReplay(round)
+
+	case strings.HasPrefix(ref, mythicRoundPrefix):
+		id, err := parseRoundID(ref, mythicRoundPrefix)
+		if err != nil {
+			return nil, err
+		}
+		var session models.MythicSession
+		if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+			return nil, ErrRoundNotFound
+		}
+		return s.mythicReplay(session)
+	}
+	return nil, fmt.Errorf("%w: expected %s<id> or %s<id>", ErrInvalidRoundRef, fortuneRoundPrefix, mythicRoundPrefix)
+}
+
+func (s *ReplayService) fortuneReplay(round models.FortuneRound) (*RoundReplay, error) {
+	replay := &FortuneReplay{
+		Lines:         []LinePayout{},
+		SpecialSymbol: round.SpecialSymbol,
+		Multiplier:    round.Multiplier,
+		IsFortuneSpin: round.IsFortuneSpin,
+		WheelPrize:    round.WheelPrize,
+		BaseWin:       round.BaseWin,
+		BonusWin:      round.BonusWin,
+		FinalWin:      round.FinalWin,
+		RNGSeed:       round.RNGSeed,
+	}
+	if err := json.Unmarshal([]byte(round.Grid), &replay.Grid); err != nil {
+		return nil, err
+	}
+
+	for i, line := range fortuneLines {
+		symbol, win, ok := lineWin(replay.Grid, line, round.Bet)
+		if !ok {
+			continue
+		}
+		positions := make([]utils.Position, len(line))
+		for j, cell := range line {
+			positions[j] = utils.Position{Row: cell[0], Col: cell[1]}
+		}
+		replay.Lines = append(replay.Lines, LinePayout{Line: i, Symbol: symbol, Positions: positions, Win: win})
+	}
+
+	return &RoundReplay{
+		Ref:        FortuneRoundRef(round.ID),
+		Game:       GameFortuneGems,
+		Bet:        float64(round.Bet),
+		Stake:      float64(round.Bet),
+		Win:        float64(round.FinalWin),
+		JackpotWin: float64(round.JackpotWin),
+		Complete:   true,
+		CreatedAt:  round.CreatedAt,
+		Fortune:    replay,
+	}, nil
+}
+
+func (s *ReplayService) mythicReplay(session models.MythicSession) (*RoundReplay, error) {
+	bet := session.BaseBet
+	if bet == 0 {
+		bet = session.BetAmount // recorded before the base bet was kept
+	}
+
+	outcome := MythicOutcome{
+		BaseWin:         session.BaseWin,
+		TotalMultiplier: session.GlobalMultiplier,
+		FeatureBuy:      session.FeatureBuy,
+		TotalWin:        session.TotalWin,
+	}
+	if err := json.Unmarshal([]byte(session.Grid), &outcome.Grid); err != nil {
+		return nil, err
+	}
+	tumbles := session.Tumbles
+	if tumbles == "" {
+		tumbles = session.Multipliers // older rounds kept the tumbles there
+	}
+	if tumbles != "" {
+		if err := json.Unmarshal([]byte(tumbles), &outcome.Tumbles); err != nil {
+			return nil, err
+		}
+	}
+	if session.InitialGrid != "" {
+		if err := json.Unmarshal([]byte(session.InitialGrid), &outcome.InitialGrid); err != nil {
+			return nil, err
+		}
+	}
+	if session.FreeSpinRounds != "" {
+		if err := json.Unmarshal([]byte(session.FreeSpinRounds), &outcome.FreeSpins); err != nil {
+			return nil, err
+		}
+	}
+
+	replay := s.replayMythic(outcome, bet, false)
+	return &RoundReplay{
+		Ref:        MythicRoundRef(session.ID),
+		Game:       GameMythic,
+		Bet:        bet,
+		Stake:      session.BetAmount,
+		Win:        session.TotalWin,
+		JackpotWin: session.JackpotWin,
+		Complete:   outcome.InitialGrid != nil,
+		CreatedAt:  session.CreatedAt,
+		Mythic:     &replay,
+	}, nil
+}
+
+// replayMythic breaks a recorded spin down into the steps of every tumble.
+// Everything but the recorded grids is derived again from the game rules.
+func (s *ReplayService) replayMythic(outcome MythicOutcome, bet float64, isFreeSpin bool) MythicReplay {
+	replay := MythicReplay{
+		InitialGrid:      outcome.InitialGrid,
+		Tumbles:          []ReplayTumble{},
+		FinalGrid:        outcome.Grid,
+		ScatterPositions: symbolPositions(outcome.Grid, SCATTER),
+		BaseWin:          outcome.BaseWin,
+		TotalMultiplier:  outcome.TotalMultiplier,
+		TotalWin:         outcome.TotalWin,
+	}
+	if !isFreeSpin {
+		// A bought feature is triggered by the scatters it started with
+		scatters := len(replay.ScatterPositions)
+		if outcome.FeatureBuy && outcome.InitialGrid != nil {
+			scatters = max(scatters, len(symbolPositions(outcome.InitialGrid, SCATTER)))
+		}
+		replay.FreeSpinsAwarded, replay.ScatterWin = scatterAward(scatters, bet)
+	}
+
+	before := outcome.InitialGrid
+	multiplier := 1.0
+	for i, tumble := range outcome.Tumbles {
+		multiplier += tumble.Multiplier - 1
+		step := ReplayTumble{
+			Index:      i,
+			Payouts:    []ClusterPayout{},
+			Win:        tumble.Win,
+			Removed:    []utils.Position{},
+			Moves:      []GravityMove{},
+			Refilled:   []RefilledCell{},
+			Strikes:    tumble.Strikes,
+			Multiplier: multiplier,
+			Grid:       tumble.Grid,
+		}
+		if step.Strikes == nil {
+			step.Strikes = []float64{}
+		}
+
+		for _, cluster := range tumble.Clusters {
+			step.Payouts = append(step.Payouts, ClusterPayout{
+				Symbol:    cluster.Symbol,
+				Size:      cluster.Size,
+				Positions: cluster.Positions,
+				Win:       s.mythic.CalculateClusterWin(cluster, bet),
+			})
+			step.Removed = append(step.Removed, cluster.Positions...)
+		}
+		if before != nil {
+			step.Moves, step.Refilled = gravitySteps(before, step.Removed, tumble.Grid)
+		}
+
+		replay.Tumbles = append(replay.Tumbles, step)
+		before = tumble.Grid
+	}
+
+	for _, freeSpin := range outcome.FreeSpins {
+		replay.FreeSpins = append(replay.FreeSpins, s.replayMythic(freeSpin, bet, true))
+	}
+	return replay
+}
+
+// gravitySteps works out how the symbols left after removing cells fall
+// down each column, and which cells at the top are filled from after
+func gravitySteps(before [][]string, removed []utils.Position, after [][]string) ([]GravityMove, []RefilledCell) {
+	gone := map[utils.Position]bool{}
+	for _, pos := range removed {
+		gone[pos] = true
+	}
+
+	moves := []GravityMove{}
+	refilled := []RefilledCell{}
+	rows, cols := len(before), len(before[0])
+	for col := 0; col < cols; col++ {
+		writeRow := rows - 1
+		for row := rows - 1; row >= 0; row-- {
+			if gone[utils.Position{Row: row, Col: col}] {
+				continue
+			}
+			if row != writeRow {
+				moves = append(moves, GravityMove{
+					Symbol: before[row][col],
+					From:   utils.Position{Row: row, Col: col},
+					To:     utils.Position{Row: writeRow, Col: col},
+				})
+			}
+			writeRow--
+		}
+		for row := writeRow; row >= 0; row-- {
+			refilled = append(refilled, RefilledCell{Position: utils.Position{Row: row, Col: col}, Symbol: after[row][col]})
+		}
+	}
+	return moves, refilled
+}
+
+func symbolPositions(grid [][]string, symbol string) []utils.Position {
+	positions := []utils.Position{}
+	for row := range grid {
+		for col := range grid[row] {
+			if grid[row][col] == symbol {
+				positions = append(positions, utils.Position{Row: row, Col: col})
+			}
+		}
+	}
+	return positions
+}
+
+func parseRoundID(ref, prefix string) (uint, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(ref, prefix), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRoundRef, ref)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LimitIncreaseDelay is how long a player has to wait before a raised or
+// removed limit takes effect. Lowering a limit always applies immediately.
+const LimitIncreaseDelay = 24 * time.Hour
+
+// SessionLimitBreak is how long a player has to stop playing before the
+// session time limit counts from zero again. Ending a play session does not
+// reset it.
+const SessionLimitBreak = time.Hour
+
+// Error codes returned to the client when play or deposits are blocked
+const (
+	CodeSelfExcluded       = "SELF_EXCLUDED"
+	CodeCoolingOff         = "COOLING_OFF"
+	CodeSessionTimeLimit   = "SESSION_TIME_LIMIT_REACHED"
+	CodeDepositLimit       = "DEPOSIT_LIMIT_EXCEEDED"
+	CodeLossLimit          = "LOSS_LIMIT_EXCEEDED"
+	CodeWagerLimit         = "WAGER_LIMIT_EXCEEDED"
+	CodeRestrictionActive  = "RESTRICTION_ALREADY_ACTIVE"
+	CodeInvalidLimitChange = "INVALID_LIMIT"
+)
+
+// ResponsibleGamingError explains why an action was blocked. Details carries
+// the numbers the frontend needs to explain the block (limit, used, resets_at...).
+type ResponsibleGamingError struct {
+	Code    string
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ResponsibleGamingError) Error() string {
+	return e.Message
+}
+
+// Body is the JSON response sent to the client
+func (e *ResponsibleGamingError) Body() map[string]interface{} {
+	return map[string]interface{}{
+		"error":   e.Message,
+		"code":    e.Code,
+		"details": e.Details,
+	}
+}
+
+type ResponsibleGamingService struct {
+	db *gorm.DB
+}
+
+func NewResponsibleGamingService(db *gorm.DB) *ResponsibleGamingService {
+	return &ResponsibleGamingService{db: db}
+}
+
+// PeriodStart returns the start of the period containing now. Weeks start on Monday.
+func PeriodStart(period models.LimitPeriod, now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case models.PeriodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case models.PeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	default:
+		return day
+	}
+}
+
+// PeriodEnd returns when the period containing now resets
+func PeriodEnd(period models.LimitPeriod, now time.Time) time.Time {
+	start := PeriodStart(period, now)
+	switch period {
+	case models.PeriodWeekly:
+		return start.AddDate(0, 0, 7)
+	case models.PeriodMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Limits returns the player's limits and restrictions, applying pending
+// increases whose cooling delay has passed
+func (s *ResponsibleGamingService) Limits(userID uint) ([]models.GamingLimit, *models.PlayerRestriction, error) {
+	now := time.Now()
+
+	var limits []models.GamingLimit
+	if err := s.db.Where("user_id = ?", userID).Order("type, period").Find(&limits).Error; err != nil {
+		return nil, nil, err
+	}
+	for i := range limits {
+		if err := s.applyPendingLimit(&limits[i], now); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return limits, restriction, nil
+}
+
+// SetLimit changes a deposit, loss or wager limit. amount 0 removes the limit.
+func (s *ResponsibleGamingService) SetLimit(userID uint, limitType models.LimitType, period models.LimitPeriod, amount float64) (*models.GamingLimit, error) {
+	if amount < 0 {
+		return nil, &ResponsibleGamingError{Code: CodeInvalidLimitChange, Message: "Limit cannot be negative"}
+	}
+
+	now := time.Now()
+	limit := models.GamingLimit{UserID: userID, Type: limitType, Period: period}
+	if err := s.db.Where(&limit).FirstOrInit(&limit).Error; err != nil {
+		return nil, err
+	}
+	if err := s.applyPendingLimit(&limit, now); err != nil {
+		return nil, err
+	}
+
+	if isIncrease(limit.Amount, amount) {
+		effectiveAt := now.Add(LimitIncreaseDelay)
+		limit.PendingAmount = &amount
+		limit.PendingEffectiveAt = &effectiveAt
+	} else {
+		limit.Amount = amount
+		limit.PendingAmount = nil
+		limit.PendingEffectiveAt = nil
+	}
+
+	if err := s.db.Save(&limit).Error; err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// SetSessionLimit changes the maximum length of a play session in minutes. 0 removes it.
+func (s *ResponsibleGamingService) SetSessionLimit(userID uint, minutes int) (*models.PlayerRestriction, error) {
+	if minutes < 0 {
+		return nil, &ResponsibleGamingError{Code: CodeInvalidLimitChange, Message: "Session limit cannot be negative"}
+	}
+
+	now := time.Now()
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if isIncrease(float64(restriction.SessionLimitMinutes), float64(minutes)) {
+		effectiveAt := now.Add(LimitIncreaseDelay)
+		restriction.PendingSessionLimit = &minutes
+		restriction.PendingSessionEffectiveAt = &effectiveAt
+	} else {
+		restriction.SessionLimitMinutes = minutes
+		restriction.PendingSessionLimit = nil
+		restriction.PendingSessionEffectiveAt = nil
+	}
+
+	if err := s.db.Save(restriction).Error; err != nil {
+		return nil, err
+	}
+	return restriction, nil
+}
+
+// CoolOff blocks play and deposits for the given duration. An active cool-off
+// can be extended but never shortened.
+func (s *ResponsibleGamingService) CoolOff(userID uint, duration time.Duration) (*models.PlayerRestriction, error) {
+	now := time.Now()
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	until := now.Add(duration)
+	if restriction.CoolOffUntil != nil && restriction.CoolOffUntil.After(until) {
+		return nil, &ResponsibleGamingError{
+			Code:    CodeRestrictionActive,
+			Message: "A longer cool-off period is already active",
+			Details: map[string]interface{}{"cool_off_until": restriction.CoolOffUntil},
+		}
+	}
+
+	restriction.CoolOffUntil = &until
+	if err := s.db.Save(restriction).Error; err != nil {
+		return nil, err
+	}
+	return restriction, nil
+}
+
+// SelfExclude blocks play and deposits for the given duration, or permanently
+// when duration is 0. Self-exclusion cannot be lifted by the player.
+func (s *ResponsibleGamingService) SelfExclude(userID uint, duration time.Duration) (*models.PlayerRestriction, error) {
+	now := time.Now()
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if duration == 0 {
+		restriction.SelfExcludedPermanently = true
+	} else {
+		until := now.Add(duration)
+		if restriction.SelfExcludedUntil == nil || restriction.SelfExcludedUntil.Before(until) {
+			restriction.SelfExcludedUntil = &until
+		}
+	}
+
+	if err := s.db.Save(restriction).Error; err != nil {
+		return nil, err
+	}
+	return restriction, nil
+}
+
+// CheckPlay verifies the player may place a bet of the given size and counts
+// the bet in the window the session time limit is measured over
+func (s *ResponsibleGamingService) CheckPlay(userID uint, bet float64) error {
+	now := time.Now()
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return err
+	}
+	if err := checkExclusion(restriction, now); err != nil {
+		return err
+	}
+
+	// The window starts with the first spin after a break of SessionLimitBreak
+	windowStart := now
+	if last := restriction.SessionWindowLastPlayAt; last != nil && now.Sub(*last) < SessionLimitBreak {
+		windowStart = *restriction.SessionWindowStartedAt
+	}
+	if restriction.SessionLimitMinutes > 0 {
+		limit := time.Duration(restriction.SessionLimitMinutes) * time.Minute
+		if now.Sub(windowStart) >= limit {
+			return &ResponsibleGamingError{
+				Code:    CodeSessionTimeLimit,
+				Message: fmt.Sprintf("You have reached your session limit of %d minutes", restriction.SessionLimitMinutes),
+				Details: map[string]interface{}{
+					"limit_minutes": restriction.SessionLimitMinutes,
+					"session_start": windowStart,
+					"resets_at":     restriction.SessionWindowLastPlayAt.Add(SessionLimitBreak),
+				},
+			}
+		}
+	}
+
+	limits, err := s.activeLimits(userID, now, models.LimitWager, models.LimitLoss)
+	if err != nil {
+		return err
+	}
+	for _, limit := range limits {
+		wagered, won, err := s.playTotals(userID, PeriodStart(limit.Period, now))
+		if err != nil {
+			return err
+		}
+
+		used, code := wagered, CodeWagerLimit
+		if limit.Type == models.LimitLoss {
+			used, code = wagered-won, CodeLossLimit
+		}
+		// The worst case for a loss limit is losing the whole bet
+		if used+bet > limit.Amount {
+			return limitError(code, limit, used, now)
+		}
+	}
+
+	// Only the window columns are written, the rest of the row may be
+	// changing in another request
+	window := models.PlayerRestriction{UserID: userID, SessionWindowStartedAt: &windowStart, SessionWindowLastPlayAt: &now}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"session_window_started_at", "session_window_last_play_at"}),
+	}).Create(&window).Error
+}
+
+// CheckDeposit verifies the player may request a deposit of the given amount
+func (s *ResponsibleGamingService) CheckDeposit(userID uint, amount float64) error {
+	now := time.Now()
+	restriction, err := s.restriction(userID, now)
+	if err != nil {
+		return err
+	}
+	if err := checkExclusion(restriction, now); err != nil {
+		return err
+	}
+
+	limits, err := s.activeLimits(userID, now, models.LimitDeposit)
+	if err != nil {
+		return err
+	}
+	for _, limit := range limits {
+		var deposited float64
+		err := s.db.Model(&models.Transaction{}).
+			Where("user_id = ? AND type = ? AND status IN ? AND created_at >= ?",
+				userID, models.TypeDeposit, []models.TransactionStatus{models.StatusPending, models.StatusApproved}, PeriodStart(limit.Period, now)).
+			Select("COALESCE(SUM(amount), 0)").Scan(&deposited).Error
+		if err != nil {
+			return err
+		}
+
+		if deposited+amount > limit.Amount {
+			return limitError(CodeDepositLimit, limit, deposited, now)
+		}
+	}
+	return nil
+}
+
+// playTotals sums the stakes and wins of both games since the given time
+func (s *ResponsibleGamingService) playTotals(userID uint, since time.Time) (float64, float64, error) {
+	return s.playTotalsBetween(userID, since, time.Now())
+}
+
+// playTotalsBetween sums the stakes and wins of both games in [from, to)
+func (s *ResponsibleGamingService) playTotalsBetween(userID uint, from, to time.Time) (float64, float64, error) {
+	var slot, mythic struct {
+		Wagered float64
+		Won     float64
+	}
+
+	err := s.db.Model(&models.Gamelog{}).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Select("COALESCE(SUM(bet), 0) AS wagered, COALESCE(SUM(win + jackpot_win), 0) AS won").
+		Scan(&slot).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = s.db.Model(&models.MythicSession{}).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Select("COALESCE(SUM(bet_amount), 0) AS wagered, COALESCE(SUM(total_win + jackpot_win), 0) AS won").
+		Scan(&mythic).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return slot.Wagered + mythic.Wagered, slot.Won + mythic.Won, nil
+}
+
+func (s *ResponsibleGamingService) activeLimits(userID uint, now time.Time, types ...models.LimitType) ([]models.GamingLimit, error) {
+	var limits []models.GamingLimit
+	if err := s.db.Where("user_id = ? AND type IN ?", userID, types).Find(&limits).Error; err != nil {
+		return nil, err
+	}
+
+	active := limits[:0]
+	for i := range limits {
+		if err := s.applyPendingLimit(&limits[i], now); err != nil {
+			return nil, err
+		}
+		if limits[i].Amount > 0 {
+			active = append(active, limits[i])
+		}
+	}
+	return active, nil
+}
+
+func (s *ResponsibleGamingService) applyPendingLimit(limit *models.GamingLimit, now time.Time) error {
+	if limit.PendingAmount == nil || limit.PendingEffectiveAt == nil || now.Before(*limit.PendingEffectiveAt) {
+		return nil
+	}
+
+	limit.Amount = *limit.PendingAmount
+	limit.PendingAmount = nil
+	limit.PendingEffectiveAt = nil
+	return s.db.Save(limit).Error
+}
+
+// restriction loads the player's restriction row, creating it on first use
+func (s *ResponsibleGamingService) restriction(userID uint, now time.Time) (*models.PlayerRestriction, error) {
+	var restriction models.PlayerRestriction
+	result := s.db.Where("user_id = ?", userID).Limit(1).Find(&restriction)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &models.PlayerRestriction{UserID: userID}, nil
+	}
+
+	if restriction.PendingSessionLimit != nil && restriction.PendingSessionEffectiveAt != nil &&
+		!now.Before(*restriction.PendingSessionEffectiveAt) {
+		restriction.SessionLimitMinutes = *restriction.PendingSessionLimit
+		restriction.PendingSessionLimit = nil
+		restriction.PendingSessionEffectiveAt = nil
+		if err := s.db.Save(&restriction).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &restriction, nil
+}
+
+func checkExclusion(restriction *models.PlayerRestriction, now time.Time) error {
+	if restriction.SelfExcludedPermanently {
+		return &ResponsibleGamingError{
+			Code:    CodeSelfExcluded,
+			Message: "Your account is permanently self-excluded",
+			Details: map[string]interface{}{"permanent": true},
+		}
+	}
+	if restriction.SelfExcludedUntil != nil && now.Before(*restriction.SelfExcludedUntil) {
+		return &ResponsibleGamingError{
+			Code:    CodeSelfExcluded,
+			Message: "Your account is self-excluded",
+			Details: map[string]interface{}{"until": restriction.SelfExcludedUntil},
+		}
+	}
+	if restriction.CoolOffUntil != nil && now.Before(*restriction.CoolOffUntil) {
+		return &ResponsibleGamingError{
+			Code:    CodeCoolingOff,
+			Message: "Your account is in a cool-off period",
+			Details: map[string]interface{}{"until": restriction.CoolOffUntil},
+		}
+	}
+	return nil
+}
+
+func limitError(code string, limit models.GamingLimit, used float64, now time.Time) *ResponsibleGamingError {
+	return &ResponsibleGamingError{
+		Code:    code,
+		Message: fmt.Sprintf("This would exceed your %s %s limit", limit.Period, limit.Type),
+		Details: map[string]interface{}{
+			"type":      limit.Type,
+			"period":    limit.Period,
+			"limit":     limit.Amount,
+			"used":      used,
+			"remaining": math.Max(limit.Amount-used, 0),
+			"resets_at": PeriodEnd(limit.Period, now),
+		},
+	}
+}
+
+// isIncrease reports whether changing a limit from current to next loosens it.
+// 0 means "no limit", so removing a limit counts as an increase.
+func isIncrease(current, next float64) bool {
+	if current == 0 {
+		return false
+	}
+	return next == 0 || next > current
+}
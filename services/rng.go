@@ -0,0 +1,41 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+)
+
+// lockedSource makes a rand.Source safe for the concurrent spins served by
+// one shared engine
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+func newLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed)})
+}
+
+// NewRoundSeed draws the seed of one round from the system's secure source,
+// so publishing the seeds of played rounds reveals nothing about later ones
+func NewRoundSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("crypto/rand unavailable: " + err.Error())
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slot-sim/models"
+	"slot-sim/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LastSeenAt is only written when it is older than this, so that every
+// authenticated request does not turn into a database write
+const lastSeenResolution = time.Minute
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// DeviceInfo describes where a login came from
+type DeviceInfo struct {
+	Label     string
+	IP        string
+	UserAgent string
+}
+
+// Start stores a new session and returns the token bound to it
+func (s *SessionService) Start(userID uint, mfa bool, device DeviceInfo) (string, *models.UserSession, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	label := strings.TrimSpace(device.Label)
+	if label == "" {
+		label = DeviceLabel(device.UserAgent)
+	}
+
+	now := time.Now()
+	session := &models.UserSession{
+		UserID:      userID,
+		TokenID:     tokenID,
+		DeviceLabel: label,
+		IP:          device.IP,
+		UserAgent:   device.UserAgent,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(utils.TokenLifetime),
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return "", nil, err
+	}
+
+	token, err := utils.GenerateToken(userID, tokenID, mfa)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// Touch loads the active session for a token and refreshes its last-seen time
+func (s *SessionService) Touch(userID uint, tokenID string, ip string) (*models.UserSession, error) {
+	if tokenID == "" {
+		return nil, ErrSessionNotFound
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("token_id = ? AND user_id = ?", tokenID, userID).First(&session).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	now := time.Now()
+	if !session.Active(now) {
+		return nil, ErrSessionNotFound
+	}
+
+	if now.Sub(session.LastSeenAt) >= lastSeenResolution || session.IP != ip {
+		session.LastSeenAt = now
+		session.IP = ip
+		s.db.Model(&session).Updates(map[string]interface{}{"last_seen_at": now, "ip": ip})
+	}
+	return &session, nil
+}
+
+// List returns the active sessions of a user, most recently used first
+func (s *SessionService) List(userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke signs out one session of a user
+func (s *SessionService) Revoke(userID, sessionID uint) error {
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll signs out every session of a user and returns how many were active
+func (s *SessionService) RevokeAll(userID uint) (int64, error) {
+	result := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// DeviceLabel derives a readable label such as "Chrome on Windows" from a user agent
+func DeviceLabel(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "mac os"):
+		os = "macOS"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
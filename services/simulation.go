@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// MaxSimulationSpins caps the spins of one simulated batch
+const MaxSimulationSpins = 1000000
+
+// How many spins a simulation plays between checks for cancellation
+const simulationCheckEvery = 1024
+
+var ErrInvalidSimulation = errors.New("invalid simulation")
+
+// SimulationOptions describe a batch of spins played on the engines alone,
+// without a player, balance or recorded rounds
+type SimulationOptions struct {
+	Game           string
+	Bet            float64
+	Spins          int
+	AnteBet        bool    // Mythic Lightning only
+	FeatureBuy     bool    // Mythic Lightning only
+	FeatureBuyCost float64 // price of a bought feature as a multiple of the bet
+	Seed           int64   // the same seed and options give the same result
+}
+
+// SimulationResult sums up a simulated batch
+type SimulationResult struct {
+	Game            string  `json:"game"`
+	Spins           int     `json:"spins"`
+	TotalStake      float64 `json:"total_stake"`
+	TotalWin        float64 `json:"total_win"`
+	RTP             float64 `json:"rtp"`      // total win over total stake
+	HitRate         float64 `json:"hit_rate"` // share of spins that won anything
+	MaxWin          float64 `json:"max_win"`
+	FeatureTriggers int     `json:"feature_triggers"` // free spins or fortune spins
+	Seed            int64   `json:"seed"`
+}
+
+// Simulate plays a batch of spins and sums them up. It stops early with the
+// context's error when the context is done.
+func Simulate(ctx context.Context, opts SimulationOptions) (SimulationResult, error) {
+	if err := validateSimulation(opts); err != nil {
+		return SimulationResult{}, err
+	}
+
+	result := SimulationResult{Game: opts.Game, Spins: opts.Spins, Seed: opts.Seed}
+	stake, play := simulatedSpin(opts)
+
+	hits := 0
+	for i := 0; i < opts.Spins; i++ {
+		if i%simulationCheckEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return SimulationResult{}, err
+			}
+		}
+
+		win, triggered := play()
+		result.TotalStake += stake
+		result.TotalWin += win
+		result.MaxWin = math.Max(result.MaxWin, win)
+		if win > 0 {
+			hits++
+		}
+		if triggered {
+			result.FeatureTriggers++
+		}
+	}
+
+	if result.TotalStake > 0 {
+		result.RTP = result.TotalWin / result.TotalStake
+	}
+	result.HitRate = float64(hits) / float64(opts.Spins)
+	return result, nil
+}
+
+// simulatedSpin returns the stake of one spin and a function playing the
+// next spin of the batch
+func simulatedSpin(opts SimulationOptions) (float64, func() (float64, bool)) {
+	if opts.Game == GameFortuneGems {
+		engine := &FortuneEngine{}
+		seeds := rand.New(rand.NewSource(opts.Seed))
+		bet := int(opts.Bet)
+		return opts.Bet, func() (float64, bool) {
+			outcome := engine.PlaySeed(bet, seeds.Int63())
+			return float64(outcome.FinalWin), outcome.IsFortuneSpin
+		}
+	}
+
+	engine := NewSeededMythicEngine(opts.Seed)
+	switch {
+	case opts.FeatureBuy:
+		return opts.Bet * opts.FeatureBuyCost, func() (float64, bool) {
+			outcome := engine.BuyFeature(opts.Bet)
+			return outcome.TotalWin, outcome.FreeSpinsAwarded > 0
+		}
+	case opts.AnteBet:
+		return math.Ceil(opts.Bet * AnteBetMultiplier), func() (float64, bool) {
+			outcome := engine.Spin(opts.Bet, true)
+			return outcome.TotalWin, outcome.FreeSpinsAwarded > 0
+		}
+	default:
+		return opts.Bet, func() (float64, bool) {
+			outcome := engine.Spin(opts.Bet, false)
+			return outcome.TotalWin, outcome.FreeSpinsAwarded > 0
+		}
+	}
+}
+
+func validateSimulation(opts SimulationOptions) error {
+	if opts.Spins < 1 || opts.Spins > MaxSimulationSpins {
+		return fmt.Errorf("%w: spins must be between 1 and %d", ErrInvalidSimulation, MaxSimulationSpins)
+	}
+	if err := CheckBet(opts.Game, opts.Bet); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSimulation, err)
+	}
+	if opts.Game == GameFortuneGems && (opts.AnteBet || opts.FeatureBuy) {
+		return fmt.Errorf("%w: ante bet and feature buy are Mythic Lightning options", ErrInvalidSimulation)
+	}
+	if opts.AnteBet && opts.FeatureBuy {
+		return fmt.Errorf("%w: %w", ErrInvalidSimulation, ErrAnteWithFeatureBuy)
+	}
+	if opts.FeatureBuy && opts.FeatureBuyCost <= 0 {
+		return fmt.Errorf("%w: feature buy cost must be greater than 0", ErrInvalidSimulation)
+	}
+	return nil
+}
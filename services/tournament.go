@@ -0,0 +1,382 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slot-sim/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTournamentNotFound = errors.New("tournament not found")
+	ErrTournamentClosed   = errors.New("tournament is closed")
+	ErrInvalidTournament  = errors.New("invalid tournament")
+)
+
+// TournamentInput is what an admin configures for a new tournament
+type TournamentInput struct {
+	Name        string
+	Game        string // "" for both games
+	StartsAt    time.Time
+	EndsAt      time.Time
+	MinBet      float64
+	ScoringRule string
+	AutoEnroll  bool
+	Prizes      []int // rank 1 first
+}
+
+// TournamentView is a tournament with its decoded prize table
+type TournamentView struct {
+	models.Tournament
+	Prizes []int `json:"prizes"`
+}
+
+// LeaderboardEntry is one ranked player
+type LeaderboardEntry struct {
+	Rank     int     `json:"rank"`
+	UserID   uint    `json:"user_id"`
+	Username string  `json:"username"`
+	Score    float64 `json:"score"`
+	Spins    int     `json:"spins"`
+	Prize    int     `json:"prize,omitempty"` // prize for the rank, paid on close
+}
+
+type Leaderboard struct {
+	Tournament TournamentView     `json:"tournament"`
+	Entries    []LeaderboardEntry `json:"entries"`
+	You        *LeaderboardEntry  `json:"you,omitempty"` // the caller, when entered
+}
+
+type TournamentService struct {
+	db *gorm.DB
+}
+
+func NewTournamentService(db *gorm.DB) *TournamentService {
+	return &TournamentService{db: db}
+}
+
+// Create validates and stores a new tournament
+func (s *TournamentService) Create(input TournamentInput) (*TournamentView, error) {
+	if err := validateTournament(input); err != nil {
+		return nil, err
+	}
+
+	prizesJSON, _ := json.Marshal(input.Prizes)
+	tournament := models.Tournament{
+		Name:        input.Name,
+		Game:        input.Game,
+		StartsAt:    input.StartsAt,
+		EndsAt:      input.EndsAt,
+		MinBet:      input.MinBet,
+		ScoringRule: input.ScoringRule,
+		AutoEnroll:  input.AutoEnroll,
+		Prizes:      string(prizesJSON),
+		Status:      models.TournamentOpen,
+	}
+	if err := s.db.Create(&tournament).Error; err != nil {
+		return nil, err
+	}
+	return tournamentView(tournament), nil
+}
+
+// List returns open tournaments and those closed in the last week. With
+// all set every tournament is returned.
+func (s *TournamentService) List(all bool) ([]TournamentView, error) {
+	query := s.db.Order("starts_at DESC")
+	if !all {
+		query = query.Where("status = ? OR closed_at >= ?", models.TournamentOpen, time.Now().AddDate(0, 0, -7))
+	}
+
+	var tournaments []models.Tournament
+	if err := query.Find(&tournaments).Error; err != nil {
+		return nil, err
+	}
+
+	views := make([]TournamentView, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		views = append(views, *tournamentView(tournament))
+	}
+	return views, nil
+}
+
+// Join enters the player into a tournament that has not ended yet
+func (s *TournamentService) Join(userID, tournamentID uint) (*models.TournamentEntry, error) {
+	tournament, err := s.get(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if tournament.Status != models.TournamentOpen || !time.Now().Before(tournament.EndsAt) {
+		return nil, ErrTournamentClosed
+	}
+
+	entry := models.TournamentEntry{
+		TournamentID: tournament.ID,
+		UserID:       userID,
+		ScoredAt:     time.Now(),
+	}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	err = s.db.Where("tournament_id = ? AND user_id = ?", tournament.ID, userID).First(&entry).Error
+	return &entry, err
+}
+
+// RecordSpin runs inside the spin's settlement transaction and scores the
+// spin in every running tournament it qualifies for
+func (s *TournamentService) RecordSpin(tx *gorm.DB, userID uint, game string, stake, multiplier float64) error {
+	now := time.Now()
+
+	var running []models.Tournament
+	err := tx.Where("status = ? AND starts_at <= ? AND ends_at > ?", models.TournamentOpen, now, now).
+		Where("game = '' OR game = ?", game).
+		Where("min_bet <= ?", stake).
+		Find(&running).Error
+	if err != nil {
+		return err
+	}
+
+	for _, tournament := range running {
+		if err := s.score(tx, tournament, userID, stake, multiplier, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// score applies one spin to the player's entry with a single update, so
+// concurrent spins of the same player never lose a score
+func (s *TournamentService) score(tx *gorm.DB, tournament models.Tournament, userID uint, stake, multiplier float64, now time.Time) error {
+	updates := map[string]interface{}{"spins": gorm.Expr("spins + 1")}
+	switch tournament.ScoringRule {
+	case models.ScoreTotalWagered:
+		updates["score"] = gorm.Expr("score + ?", stake)
+		updates["scored_at"] = now
+	case models.ScoreHighestMultiplier:
+		updates["score"] = gorm.Expr("CASE WHEN ? > score THEN ? ELSE score END", multiplier, multiplier)
+		updates["scored_at"] = gorm.Expr("CASE WHEN ? > score THEN ? ELSE scored_at END", multiplier, now)
+	default:
+		return nil
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		result := tx.Model(&models.TournamentEntry{}).
+			Where("tournament_id = ? AND user_id = ?", tournament.ID, userID).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected > 0 || !tournament.AutoEnroll {
+			return result.Error
+		}
+
+		// First qualifying spin of an automatically enrolled player
+		entry := models.TournamentEntry{TournamentID: tournament.ID, UserID: userID, ScoredAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Leaderboard returns the top of a tournament and the caller's position
+func (s *TournamentService) Leaderboard(tournamentID, userID uint, limit int) (*Leaderboard, error) {
+	tournament, err := s.get(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	view := tournamentView(*tournament)
+
+	entries, err := s.ranked(s.db, tournament.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	board := &Leaderboard{Tournament: *view, Entries: entries}
+	for i := range board.Entries {
+		board.Entries[i].Prize = prizeFor(view.Prizes, board.Entries[i].Rank, board.Entries[i])
+	}
+
+	var mine models.TournamentEntry
+	result := s.db.Where("tournament_id = ? AND user_id = ?", tournament.ID, userID).Limit(1).Find(&mine)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		var ahead int64
+		err := s.db.Model(&models.TournamentEntry{}).
+			Where("tournament_id = ?", tournament.ID).
+			Where("score > ? OR (score = ? AND (scored_at < ? OR (scored_at = ? AND id < ?)))",
+				mine.Score, mine.Score, mine.ScoredAt, mine.ScoredAt, mine.ID).
+			Count(&ahead).Error
+		if err != nil {
+			return nil, err
+		}
+
+		you := LeaderboardEntry{
+			Rank:   int(ahead) + 1,
+			UserID: userID,
+			Score:  mine.Score,
+			Spins:  mine.Spins,
+		}
+		s.db.Model(&models.User{}).Where("id = ?", userID).Select("username").Scan(&you.Username)
+		you.Prize = prizeFor(view.Prizes, you.Rank, you)
+		board.You = &you
+	}
+
+	return board, nil
+}
+
+// Close ends a tournament, fixes the final ranks and pays the prizes
+func (s *TournamentService) Close(tournamentID uint) (*TournamentView, error) {
+	tournament, err := s.get(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	view := tournamentView(*tournament)
+
+	var winners []uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Only one closer wins, prizes can never be paid twice
+		result := tx.Model(&models.Tournament{}).
+			Where("id = ? AND status = ?", tournament.ID, models.TournamentOpen).
+			Updates(map[string]interface{}{"status": models.TournamentClosed, "closed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTournamentClosed
+		}
+
+		entries, err := s.ranked(tx, tournament.ID, 0)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			prize := prizeFor(view.Prizes, entry.Rank, entry)
+			if prize > 0 {
+				reason := fmt.Sprintf("Tournament prize: %s (rank %d)", tournament.Name, entry.Rank)
+				if _, err := AdjustBalance(tx, entry.UserID, prize, reason, "tournament"); err != nil {
+					return err
+				}
+				winners = append(winners, entry.UserID)
+			}
+
+			err := tx.Model(&models.TournamentEntry{}).
+				Where("tournament_id = ? AND user_id = ?", tournament.ID, entry.UserID).
+				Updates(map[string]interface{}{"final_rank": entry.Rank, "prize": prize}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		tournament.Status = models.TournamentClosed
+		tournament.ClosedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range winners {
+		PublishBalance(s.db, userID, "tournament_prize")
+	}
+	return tournamentView(*tournament), nil
+}
+
+// CloseDue closes every open tournament whose end time has passed
+func (s *TournamentService) CloseDue() {
+	var due []models.Tournament
+	if err := s.db.Where("status = ? AND ends_at <= ?", models.TournamentOpen, time.Now()).Find(&due).Error; err != nil {
+		println("Failed to load due tournaments:", err.Error())
+		return
+	}
+
+	for _, tournament := range due {
+		if _, err := s.Close(tournament.ID); err != nil && !errors.Is(err, ErrTournamentClosed) {
+			println("Failed to close tournament:", tournament.Name, err.Error())
+		}
+	}
+}
+
+// RunScheduler closes tournaments as they end. It blocks, run it in its own
+// goroutine.
+func (s *TournamentService) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.CloseDue()
+	}
+}
+
+func (s *TournamentService) get(tournamentID uint) (*models.Tournament, error) {
+	var tournament models.Tournament
+	if err := s.db.First(&tournament, tournamentID).Error; err != nil {
+		return nil, ErrTournamentNotFound
+	}
+	return &tournament, nil
+}
+
+// ranked returns entries in rank order: best score first, ties going to
+// whoever reached the score earlier. limit 0 returns every entry.
+func (s *TournamentService) ranked(db *gorm.DB, tournamentID uint, limit int) ([]LeaderboardEntry, error) {
+	query := db.Table("tournament_entries").
+		Select("tournament_entries.user_id, users.username, tournament_entries.score, tournament_entries.spins").
+		Joins("LEFT JOIN users ON users.id = tournament_entries.user_id").
+		Where("tournament_entries.tournament_id = ?", tournamentID).
+		Order("tournament_entries.score DESC, tournament_entries.scored_at ASC, tournament_entries.id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var entries []LeaderboardEntry
+	if err := query.Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries, nil
+}
+
+// prizeFor returns the prize of a rank. Entries that never played a
+// qualifying spin do not win anything.
+func prizeFor(prizes []int, rank int, entry LeaderboardEntry) int {
+	if entry.Spins == 0 || rank < 1 || rank > len(prizes) {
+		return 0
+	}
+	return prizes[rank-1]
+}
+
+func tournamentView(tournament models.Tournament) *TournamentView {
+	view := &TournamentView{Tournament: tournament, Prizes: []int{}}
+	json.Unmarshal([]byte(tournament.Prizes), &view.Prizes)
+	return view
+}
+
+func validateTournament(input TournamentInput) error {
+	switch {
+	case input.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidTournament)
+	case input.Game != "" && input.Game != GameFortuneGems && input.Game != GameMythic:
+		return fmt.Errorf("%w: unknown game %q", ErrInvalidTournament, input.Game)
+	case !input.EndsAt.After(input.StartsAt):
+		return fmt.Errorf("%w: end must be after start", ErrInvalidTournament)
+	case !input.EndsAt.After(time.Now()):
+		return fmt.Errorf("%w: end must be in the future", ErrInvalidTournament)
+	case input.MinBet < 0:
+		return fmt.Errorf("%w: minimum bet cannot be negative", ErrInvalidTournament)
+	case input.ScoringRule != models.ScoreHighestMultiplier && input.ScoringRule != models.ScoreTotalWagered:
+		return fmt.Errorf("%w: unknown scoring rule %q", ErrInvalidTournament, input.ScoringRule)
+	}
+	for _, prize := range input.Prizes {
+		if prize < 0 {
+			return fmt.Errorf("%w: prizes cannot be negative", ErrInvalidTournament)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"slot-sim/models"
+	"slot-sim/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TOTPIssuer        = "Slot Sim"
+	recoveryCodeCount = 10
+
+	// Times Verify reads the recovery codes again when another code is used
+	// at the same time
+	recoveryCodeAttempts = 3
+)
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrolment has not been started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+type TwoFactorService struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorService(db *gorm.DB) *TwoFactorService {
+	return &TwoFactorService{db: db}
+}
+
+// BeginEnrolment generates a new secret for the user and returns it together
+// with the otpauth URI. 2FA stays disabled until ConfirmEnrolment succeeds.
+func (s *TwoFactorService) BeginEnrolment(user *models.User) (string, string, error) {
+	if user.TOTPEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	user.TOTPSecret = secret
+	if err := s.db.Model(user).Update("totp_secret", secret).Error; err != nil {
+		return "", "", err
+	}
+
+	return secret, utils.TOTPURI(TOTPIssuer, user.Username, secret), nil
+}
+
+// ConfirmEnrolment enables 2FA once the user proves their authenticator
+// produces valid codes, and returns freshly generated recovery codes
+func (s *TwoFactorService) ConfirmEnrolment(user *models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashed := make([]string, len(codes))
+	for i, c := range codes {
+		hashed[i] = utils.HashRecoveryCode(c)
+	}
+	hashedJSON, _ := json.Marshal(hashed)
+
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	user.RecoveryCodes = string(hashedJSON)
+	err = s.db.Model(user).Updates(map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+		"recovery_codes": user.RecoveryCodes,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns 2FA off after verifying a current code or recovery code
+func (s *TwoFactorService) Disable(user *models.User, code string) error {
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = ""
+	return s.db.Model(user).Updates(map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+		"recovery_codes": "",
+	}).Error
+}
+
+// Verify accepts either a TOTP code or an unused recovery code. Accepted TOTP
+// steps and recovery codes cannot be used a second time: both are claimed
+// with a conditional update, so of two concurrent requests sending the same
+// code only one succeeds.
+func (s *TwoFactorService) Verify(user *models.User, code string) error {
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		result := s.db.Model(&models.User{}).
+			Where("id = ? AND totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		user.TOTPLastStep = step
+		return nil
+	}
+
+	candidate := utils.HashRecoveryCode(code)
+	stored := user.RecoveryCodes
+	// Another code used meanwhile changes the list, so it is read again
+	for attempt := 0; attempt < recoveryCodeAttempts; attempt++ {
+		var hashed []string
+		json.Unmarshal([]byte(stored), &hashed)
+
+		i := slices.Index(hashed, candidate)
+		if i < 0 {
+			return ErrInvalidTwoFactorCode
+		}
+		remaining, _ := json.Marshal(slices.Delete(hashed, i, i+1))
+		result := s.db.Model(&models.User{}).
+			Where("id = ? AND recovery_codes = ?", user.ID, stored).
+			Update("recovery_codes", string(remaining))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			user.RecoveryCodes = string(remaining)
+			return nil
+		}
+
+		err := s.db.Model(&models.User{}).Where("id = ?", user.ID).Select("recovery_codes").Scan(&stored).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return ErrInvalidTwoFactorCode
+}
+
+// RemainingRecoveryCodes returns how many recovery codes are still unused
+func RemainingRecoveryCodes(user *models.User) int {
+	var hashed []string
+	json.Unmarshal([]byte(user.RecoveryCodes), &hashed)
+	return len(hashed)
+}
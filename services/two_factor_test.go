@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slot-sim/config"
+	"slot-sim/models"
+	"slot-sim/services"
+	"slot-sim/utils"
+	"testing"
+	"time"
+)
+
+// newTwoFactorUser enrols a player in 2FA and returns its recovery codes
+func newTwoFactorUser(t *testing.T) (*services.TwoFactorService, *models.User, []string) {
+	db, err := config.OpenDB(filepath.Join(t.TempDir(), "2fa.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	user := &models.User{OperatorID: 1, Username: "player", Password: "-", Role: "user"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	twoFactor := services.NewTwoFactorService(db)
+	secret, _, err := twoFactor.BeginEnrolment(user)
+	if err != nil {
+		t.Fatalf("BeginEnrolment: %v", err)
+	}
+	codes, err := twoFactor.ConfirmEnrolment(user, totpCode(t, secret, 0))
+	if err != nil {
+		t.Fatalf("ConfirmEnrolment: %v", err)
+	}
+	return twoFactor, user, codes
+}
+
+// totpCode returns the code of the step offset steps from now
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatalf("TOTPCode: %v", err)
+	}
+	return code
+}
+
+// Two requests holding the same copy of the user, as concurrent logins do,
+// cannot both use one code
+func TestVerifyUsesCodesOnce(t *testing.T) {
+	twoFactor, user, codes := newTwoFactorUser(t)
+	first, second := *user, *user
+
+	code := totpCode(t, user.TOTPSecret, 1)
+	if err := twoFactor.Verify(&first, code); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := twoFactor.Verify(&second, code); !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		t.Errorf("Verify of a used TOTP step = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	if err := twoFactor.Verify(&first, codes[0]); err != nil {
+		t.Fatalf("Verify recovery code: %v", err)
+	}
+	if err := twoFactor.Verify(&second, codes[0]); !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		t.Errorf("Verify of a used recovery code = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	// Another code is still accepted from the stale copy
+	if err := twoFactor.Verify(user, codes[1]); err != nil {
+		t.Fatalf("Verify of an unused recovery code: %v", err)
+	}
+	if n := services.RemainingRecoveryCodes(user); n != len(codes)-2 {
+		t.Errorf("%d recovery codes left, want %d", n, len(codes)-2)
+	}
+}
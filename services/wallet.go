@@ -0,0 +1,188 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slot-sim/models"
+
+	"gorm.io/gorm"
+)
+
+// WalletRequest is one movement of money of a round
+type WalletRequest struct {
+	TransactionID string // idempotency key: a retry sends the same ID
+	RoundID       string
+	OperatorID    uint
+	UserID        uint
+	Player        string // the player's username, which the operator knows them by
+	Game          string
+	Amount        int
+}
+
+// Wallet holds the balances rounds are settled against. Debit and Credit
+// return the balance after the movement. A Transactional wallet moves money
+// inside the round's database transaction, which it is given as tx; any
+// other is called outside it with a nil tx and records its movements
+// itself. Rollback voids a movement of a round that failed.
+type Wallet interface {
+	Transactional() bool
+	Debit(tx *gorm.DB, req WalletRequest) (int, error)
+	Credit(tx *gorm.DB, req WalletRequest) (int, error)
+	Rollback(req WalletRequest) error
+}
+
+// WalletFor returns the wallet the operator's rounds are settled against
+func WalletFor(db *gorm.DB, operator *models.Operator) Wallet {
+	if operator.SeamlessWallet() {
+		return NewRemoteWallet(db, operator)
+	}
+	return LocalWallet{}
+}
+
+// LocalWallet keeps balances in the users table
+type LocalWallet struct{}
+
+func (LocalWallet) Transactional() bool {
+	return true
+}
+
+// Debit takes the amount in a single conditional update, so concurrent
+// spins can never take the balance below zero
+func (LocalWallet) Debit(tx *gorm.DB, req WalletRequest) (int, error) {
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND balance >= ?", req.UserID, req.Amount).
+		Update("balance", gorm.Expr("balance - ?", req.Amount))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrInsufficientBalance
+	}
+	return localBalance(tx, req.UserID)
+}
+
+func (LocalWallet) Credit(tx *gorm.DB, req WalletRequest) (int, error) {
+	err := tx.Model(&models.User{}).Where("id = ?", req.UserID).
+		Update("balance", gorm.Expr("balance + ?", req.Amount)).Error
+	if err != nil {
+		return 0, err
+	}
+	return localBalance(tx, req.UserID)
+}
+
+// Rollback has nothing to do: the movements were part of the database
+// transaction that failed
+func (LocalWallet) Rollback(req WalletRequest) error {
+	return nil
+}
+
+func localBalance(tx *gorm.DB, userID uint) (int, error) {
+	var balance int
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Select("balance").Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// walletRound settles one round through a wallet and remembers what it may
+// have moved, so the round can be voided when it fails afterwards. A round
+// is debited before its database transaction, settled inside it and
+// credited once it has committed; a transactional wallet moves all of its
+// money in settle.
+type walletRound struct {
+	wallet  Wallet
+	request WalletRequest
+	stake   int
+	win     int
+	balance int // after a debit made outside the transaction
+	moved   []WalletRequest
+}
+
+func newWalletRound(wallet Wallet, user *models.User, game string) *walletRound {
+	return &walletRound{
+		wallet: wallet,
+		request: WalletRequest{
+			RoundID:    newWalletReference("rnd_"),
+			OperatorID: user.OperatorID,
+			UserID:     user.ID,
+			Player:     user.Username,
+			Game:       game,
+		},
+	}
+}
+
+// newWalletReference returns a random ID for the movements of one round or
+// adjustment, which their transaction IDs are derived from
+func newWalletReference(prefix string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
+
+// debit takes the stake from a wallet that is not transactional, voiding
+// the round if that fails
+func (r *walletRound) debit(stake int) error {
+	r.stake = stake
+	if r.wallet.Transactional() {
+		return nil
+	}
+	balance, err := r.move(nil, models.WalletDebit, stake, r.wallet.Debit)
+	if err != nil {
+		r.void()
+		return err
+	}
+	r.balance = balance
+	return nil
+}
+
+// settle runs inside the round's database transaction and returns the
+// balance after the round. A wallet that is not transactional was debited
+// already and is credited by credit once the transaction has committed.
+func (r *walletRound) settle(tx *gorm.DB, win int) (int, error) {
+	if !r.wallet.Transactional() {
+		r.win = win
+		return r.balance + win, nil
+	}
+	if _, err := r.move(tx, models.WalletDebit, r.stake, r.wallet.Debit); err != nil {
+		return 0, err
+	}
+	return r.move(tx, models.WalletCredit, win, r.wallet.Credit)
+}
+
+// credit pays the win of a committed round into a wallet that is not
+// transactional. The round stands whatever happens: a credit the wallet
+// does not answer is queued and resent by the WalletReconciler.
+func (r *walletRound) credit() {
+	if r.wallet.Transactional() {
+		return
+	}
+	if _, err := r.move(nil, models.WalletCredit, r.win, r.wallet.Credit); err != nil && !errors.Is(err, ErrWalletUnavailable) {
+		println("Failed to credit wallet transaction", r.request.RoundID+"-"+models.WalletCredit, err.Error())
+	}
+}
+
+func (r *walletRound) move(tx *gorm.DB, kind string, amount int, send func(*gorm.DB, WalletRequest) (int, error)) (int, error) {
+	req := r.request
+	req.TransactionID = r.request.RoundID + "-" + kind
+	req.Amount = amount
+
+	balance, err := send(tx, req)
+	// A refused movement did not happen; anything else may have
+	if !errors.Is(err, ErrInsufficientBalance) && !errors.Is(err, ErrWalletRejected) {
+		r.moved = append(r.moved, req)
+	}
+	return balance, err
+}
+
+// void rolls back what the round moved, the newest movement first. It is
+// called once the round has failed. Rollbacks the wallet does not answer
+// are queued and resent by the WalletReconciler.
+func (r *walletRound) void() {
+	for i := len(r.moved) - 1; i >= 0; i-- {
+		if err := r.wallet.Rollback(r.moved[i]); err != nil && !errors.Is(err, ErrWalletUnavailable) {
+			println("Failed to roll back wallet transaction", r.moved[i].TransactionID, err.Error())
+		}
+	}
+	r.moved = nil
+}
@@ -11,12 +11,12 @@ echo ==========================================
 echo.
 
 echo [1/5] Registering new user: %USERNAME%
-curl -s -X POST %BASE_URL%/register -H "Content-Type: application/json" -d "{\"username\": \"%USERNAME%\", \"password\": \"%PASSWORD%\"}"
+curl -s -X POST %BASE_URL%/api/v1/auth/register -H "Content-Type: application/json" -d "{\"username\": \"%USERNAME%\", \"password\": \"%PASSWORD%\"}"
 echo.
 echo.
 
 echo [2/5] Logging in...
-for /f "tokens=*" %%i in ('curl -s -X POST %BASE_URL%/login -H "Content-Type: application/json" -d "{\"username\": \"%USERNAME%\", \"password\": \"%PASSWORD%\"}"') do set RESPONSE=%%i
+for /f "tokens=*" %%i in ('curl -s -X POST %BASE_URL%/api/v1/auth/login -H "Content-Type: application/json" -d "{\"username\": \"%USERNAME%\", \"password\": \"%PASSWORD%\"}"') do set RESPONSE=%%i
 
 echo Response: %RESPONSE%
 
@@ -36,17 +36,17 @@ if "%TOKEN%"=="" (
 )
 
 echo [3/5] Getting Profile...
-curl -s -X GET %BASE_URL%/user/me -H "Authorization: Bearer %TOKEN%"
+curl -s -X GET %BASE_URL%/api/v1/user/me -H "Authorization: Bearer %TOKEN%"
 echo.
 echo.
 
 echo [4/5] Playing Slot...
-curl -s -X POST %BASE_URL%/user/play-slot -H "Authorization: Bearer %TOKEN%"
+curl -s -X POST %BASE_URL%/api/v1/user/play-slot -H "Authorization: Bearer %TOKEN%"
 echo.
 echo.
 
 echo [5/5] Getting History...
-curl -s -X GET %BASE_URL%/user/history -H "Authorization: Bearer %TOKEN%"
+curl -s -X GET %BASE_URL%/api/v1/user/history -H "Authorization: Bearer %TOKEN%"
 echo.
 echo.
 
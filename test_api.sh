@@ -10,14 +10,14 @@ echo "=========================================="
 echo
 
 echo "[1/5] Registering new user: $USERNAME"
-curl -s -X POST "$BASE_URL/register" \
+curl -s -X POST "$BASE_URL/api/v1/auth/register" \
   -H "Content-Type: application/json" \
   -d "{\"username\": \"$USERNAME\", \"password\": \"$PASSWORD\"}"
 echo
 echo
 
 echo "[2/5] Logging in..."
-RESPONSE=$(curl -s -X POST "$BASE_URL/login" \
+RESPONSE=$(curl -s -X POST "$BASE_URL/api/v1/auth/login" \
   -H "Content-Type: application/json" \
   -d "{\"username\": \"$USERNAME\", \"password\": \"$PASSWORD\"}")
 
@@ -36,19 +36,19 @@ if [ -z "$TOKEN" ]; then
 fi
 
 echo "[3/5] Getting Profile..."
-curl -s -X GET "$BASE_URL/user/me" \
+curl -s -X GET "$BASE_URL/api/v1/user/me" \
   -H "Authorization: Bearer $TOKEN"
 echo
 echo
 
 echo "[4/5] Playing Slot..."
-curl -s -X POST "$BASE_URL/user/play-slot" \
+curl -s -X POST "$BASE_URL/api/v1/user/play-slot" \
   -H "Authorization: Bearer $TOKEN"
 echo
 echo
 
 echo "[5/5] Getting History..."
-curl -s -X GET "$BASE_URL/user/history" \
+curl -s -X GET "$BASE_URL/api/v1/user/history" \
   -H "Authorization: Bearer $TOKEN"
 echo
 echo
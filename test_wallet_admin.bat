@@ -13,8 +13,8 @@ echo [2] Creating admin user...
 curl -X POST http://localhost:8080/register -H "Content-Type: application/json" -d "{\"username\":\"admin\", \"password\":\"admin123\"}"
 echo.
 echo.
-echo NOTE: Please update admin role in database manually:
-echo UPDATE users SET role = 'admin' WHERE username = 'admin';
+echo NOTE: Promote the admin user with:
+echo go run ./cmd/slotctl promote -username admin
 echo.
 pause
 
@@ -9,36 +9,70 @@ import (
 
 var jwtKey = []byte("secret_key")
 
+// Token purposes
+const (
+	PurposeAccess    = ""              // regular session token
+	PurposeTwoFactor = "2fa_challenge" // only valid for completing a 2FA login
+)
+
+const (
+	TokenLifetime      = 24 * time.Hour
+	twoFactorTokenLife = 5 * time.Minute
+)
+
 type Claims struct {
 	UserID uint `json:"user_id"`
+	// MFA is set when the token was issued after a successful second factor
+	MFA     bool   `json:"mfa,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
 	jwt.StandardClaims
 }
 
-func GenerateToken(userID uint) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID: userID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
-	}
+// GenerateToken issues a session token bound to the stored session sessionID.
+// mfa marks tokens issued after a successful second factor.
+func GenerateToken(userID uint, sessionID string, mfa bool) (string, error) {
+	claims := &Claims{UserID: userID, MFA: mfa}
+	claims.Id = sessionID
+	return signToken(claims, TokenLifetime)
+}
+
+// GenerateTwoFactorChallenge issues a short-lived token that proves the
+// password step succeeded and can only be exchanged for a session token
+func GenerateTwoFactorChallenge(userID uint) (string, error) {
+	return signToken(&Claims{UserID: userID, Purpose: PurposeTwoFactor}, twoFactorTokenLife)
+}
+
+func signToken(claims *Claims, lifetime time.Duration) (string, error) {
+	claims.ExpiresAt = time.Now().Add(lifetime).Unix()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtKey)
 }
 
-func ValidateToken(tokenString string) (uint, error) {
+// ParseToken validates a token of the given purpose and returns its claims
+func ParseToken(tokenString, purpose string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return jwtKey, nil
 	})
 	if err != nil {
 		if err == jwt.ErrSignatureInvalid {
-			return 0, errors.New("invalid signature")
+			return nil, errors.New("invalid signature")
 		}
-		return 0, err
+		return nil, err
 	}
 	if !token.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+	return claims, nil
+}
+
+func ValidateToken(tokenString string) (uint, error) {
+	claims, err := ParseToken(tokenString, PurposeAccess)
+	if err != nil {
+		return 0, err
 	}
 	return claims.UserID, nil
 }
@@ -0,0 +1,189 @@
+// Package walletstub is a stand-in for an operator's seamless wallet, for
+// local runs and checks. It keeps balances in memory, implements the debit,
+// credit and rollback calls of services.RemoteWallet with their signatures
+// and idempotency, and can be told to fail calls.
+package walletstub
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slot-sim/services"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How far a request's timestamp may be from the stub's clock
+const maxClockSkew = 5 * time.Minute
+
+// Server is an http.Handler serving POST /debit, /credit and /rollback
+type Server struct {
+	secret         string
+	initialBalance float64
+
+	mu          sync.Mutex
+	balances    map[string]float64 // by player ID
+	answers     map[string]answer  // by transaction ID, replayed on retries
+	rolledBack  map[string]bool    // transaction IDs voided by a rollback
+	failNext    int
+	failCredits bool
+}
+
+type answer struct {
+	status  int
+	body    interface{}
+	request services.RemoteWalletRequest
+	kind    string
+}
+
+// New returns a stub that checks signatures against secret and starts
+// every player it has not seen at initialBalance
+func New(secret string, initialBalance float64) *Server {
+	return &Server{
+		secret:         secret,
+		initialBalance: initialBalance,
+		balances:       map[string]float64{},
+		answers:        map[string]answer{},
+		rolledBack:     map[string]bool{},
+	}
+}
+
+// SetBalance sets a player's balance
+func (s *Server) SetBalance(player string, balance float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[player] = balance
+}
+
+// Balance returns a player's balance
+func (s *Server) Balance(player string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance(player)
+}
+
+// FailNext answers the next n calls with 503 Service Unavailable
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// FailCredits answers every credit with 503 Service Unavailable while fail
+// is true, so credits are left for the reconciler to resend
+func (s *Server) FailCredits(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failCredits = fail
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kind := strings.TrimPrefix(r.URL.Path, "/")
+	if r.Method != http.MethodPost || (kind != "debit" && kind != "credit" && kind != "rollback") {
+		writeJSON(w, http.StatusNotFound, errorBody("NOT_FOUND"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<16))
+	if err != nil || !s.verify(r, body) {
+		writeJSON(w, http.StatusUnauthorized, errorBody("INVALID_SIGNATURE"))
+		return
+	}
+	var req services.RemoteWalletRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.TransactionID == "" || req.PlayerID == "" || req.Amount < 0 {
+		writeJSON(w, http.StatusBadRequest, errorBody("INVALID_REQUEST"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failNext > 0 || (s.failCredits && kind == "credit") {
+		if s.failNext > 0 {
+			s.failNext--
+		}
+		writeJSON(w, http.StatusServiceUnavailable, errorBody("UNAVAILABLE"))
+		return
+	}
+
+	// A retry gets the answer of the first attempt
+	if prior, ok := s.answers[req.TransactionID]; ok {
+		writeJSON(w, prior.status, prior.body)
+		return
+	}
+
+	result := s.apply(kind, req)
+	s.answers[req.TransactionID] = result
+	writeJSON(w, result.status, result.body)
+}
+
+// apply performs a call seen for the first time
+func (s *Server) apply(kind string, req services.RemoteWalletRequest) answer {
+	result := answer{status: http.StatusOK, request: req, kind: kind}
+	balance := s.balance(req.PlayerID)
+
+	switch kind {
+	case "debit", "credit":
+		if s.rolledBack[req.TransactionID] {
+			result.status, result.body = http.StatusConflict, errorBody("TRANSACTION_ROLLED_BACK")
+			return result
+		}
+		if kind == "debit" {
+			if balance < float64(req.Amount) {
+				result.status, result.body = http.StatusPaymentRequired, errorBody("INSUFFICIENT_FUNDS")
+				return result
+			}
+			balance -= float64(req.Amount)
+		} else {
+			balance += float64(req.Amount)
+		}
+	case "rollback":
+		// A rollback may arrive before, or instead of, what it voids
+		s.rolledBack[req.ReferenceTransactionID] = true
+		if original, ok := s.answers[req.ReferenceTransactionID]; ok && original.status == http.StatusOK {
+			if original.kind == "debit" {
+				balance += float64(original.request.Amount)
+			} else {
+				balance -= float64(original.request.Amount)
+			}
+		}
+	}
+
+	s.balances[req.PlayerID] = balance
+	result.body = services.RemoteWalletResponse{Balance: balance}
+	return result
+}
+
+func (s *Server) balance(player string) float64 {
+	if balance, ok := s.balances[player]; ok {
+		return balance
+	}
+	return s.initialBalance
+}
+
+// verify checks the signature and the freshness of its timestamp
+func (s *Server) verify(r *http.Request, body []byte) bool {
+	timestamp := r.Header.Get(services.WalletTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return false
+	}
+	want := services.SignWalletRequest(s.secret, timestamp, body)
+	return hmac.Equal([]byte(want), []byte(r.Header.Get(services.WalletSignatureHeader)))
+}
+
+func errorBody(code string) map[string]string {
+	return map[string]string{"error": code}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}